@@ -0,0 +1,92 @@
+// Package chopchop runs the ChopChop scan engine from another Go program.
+// It never exits the process: the errors are returned and the findings handled by the caller.
+package chopchop
+
+import (
+	"context"
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal/httpget"
+	"time"
+)
+
+// NewScanner returns a scanner configured with the HTTP options, threads, base path and limits of the configuration.
+// Its Writer, Pauser and Checkpoint can be set before scanning.
+func NewScanner(config *core.Config, signatures *core.Signatures) *core.Scanner {
+	fetcher := httpget.NewFetcher(config.HTTP)
+	noRedirectFetcher := httpget.NewNoRedirectFetcher(config.HTTP)
+
+	scanner := core.NewScanner(fetcher, noRedirectFetcher, signatures, config.Threads)
+	scanner.BasePath = config.BasePath
+	scanner.InsecureTargets = config.InsecureTargets
+	scanner.CaptureEvidence = config.CaptureEvidence
+	scanner.DetectBlocking = config.DetectBlocking
+	if len(config.RateLimits) > 0 {
+		scanner.Limiter = core.NewSeverityLimiter(config.RateLimits)
+	}
+	if config.RateLimit > 0 {
+		scanner.HostLimiter = core.NewHostLimiter(config.RateLimit)
+	}
+	if config.MaxPerHost > 0 {
+		scanner.HostSemaphore = core.NewHostSemaphore(config.MaxPerHost)
+	}
+	if config.Adaptive {
+		scanner.Concurrency = core.NewAdaptiveConcurrency(1, config.Threads)
+	}
+	return scanner
+}
+
+// Prepare keeps the checks of the signatures selected by the severity and plugin filters of the configuration,
+// then validates them and loads the files they reference (match_file, default_credentials), relative to the Dir
+// of their plugin or else to the current directory. The signatures can't be scanned before being prepared.
+func Prepare(config *core.Config, signatures *core.Signatures) error {
+	if config == nil || signatures == nil {
+		return fmt.Errorf("a configuration and signatures are needed to scan")
+	}
+	if config.Threads <= 0 {
+		return fmt.Errorf("The number of threads must be positive")
+	}
+	for _, severity := range []string{config.SeverityFilter, config.MinSeverity} {
+		if severity != "" && !core.ValidSeverity(severity) {
+			return fmt.Errorf("Invalid severity level : %s. Please use : %s", severity, core.SeveritiesAsString())
+		}
+	}
+	signatures.FilterDisabled()
+	if config.SeverityFilter != "" {
+		signatures.FilterBySeverity(config.SeverityFilter)
+	}
+	if config.MinSeverity != "" {
+		signatures.FilterByMinSeverity(config.MinSeverity)
+	}
+	if len(config.PluginFilter) > 0 {
+		signatures.FilterByNames(config.PluginFilter)
+	}
+	if len(config.Tags) > 0 {
+		signatures.FilterByTags(config.Tags)
+	}
+	if len(config.ExcludeTags) > 0 {
+		signatures.FilterByExcludedTags(config.ExcludeTags)
+	}
+	return signatures.Validate("", config.StrictCategories)
+}
+
+// Scan prepares the signatures then runs them against the urls of the configuration.
+// It returns the findings and the duration of the scan. When the context is done before the end of the scan,
+// the findings so far are returned along with the error of the context.
+func Scan(ctx context.Context, config *core.Config, signatures *core.Signatures) ([]core.Output, time.Duration, error) {
+	if err := Prepare(config, signatures); err != nil {
+		return nil, 0, err
+	}
+
+	// rejected proxy credentials fail the scan rather than every request
+	if err := httpget.VerifyProxy(ctx, config.HTTP); err != nil {
+		return nil, 0, err
+	}
+
+	begin := time.Now()
+	result, err := NewScanner(config, signatures).Scan(ctx, config.Urls)
+	if err != nil {
+		return nil, time.Since(begin), err
+	}
+	return result, time.Since(begin), ctx.Err()
+}
@@ -0,0 +1,148 @@
+package chopchop_test
+
+import (
+	"context"
+	"gochopchop/chopchop"
+	"gochopchop/core"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.git/config" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var ok int32 = 200
+	newSignatures := func() *core.Signatures {
+		return &core.Signatures{Plugins: []*core.Plugin{
+			{Endpoint: "/.git/config", Checks: []*core.Check{{Name: "Git exposed", Severity: "High", StatusCode: &ok, Description: "d", Remediation: "r"}}},
+			{Endpoint: "/server-status", Checks: []*core.Check{{Name: "Apache status", Severity: "Low", StatusCode: &ok, Description: "d", Remediation: "r"}}},
+		}}
+	}
+
+	var tests = map[string]struct {
+		config   *core.Config
+		findings int
+		nilErr   bool
+	}{
+		"findings returned":          {config: &core.Config{Urls: []string{server.URL}, Threads: 2, HTTP: core.HTTPConfig{Timeout: 5}}, findings: 1, nilErr: true},
+		"checks filtered":            {config: &core.Config{Urls: []string{server.URL}, Threads: 2, HTTP: core.HTTPConfig{Timeout: 5}, SeverityFilter: "Low"}, findings: 0, nilErr: true},
+		"no configuration":           {config: nil, nilErr: false},
+		"no thread":                  {config: &core.Config{Urls: []string{server.URL}}, nilErr: false},
+		"invalid severity of filter": {config: &core.Config{Urls: []string{server.URL}, Threads: 1, MinSeverity: "Urgent"}, nilErr: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			output, duration, err := chopchop.Scan(context.Background(), tc.config, newSignatures())
+			if tc.nilErr && err != nil {
+				t.Fatalf("expected a nil error, got : %v", err)
+			}
+			if !tc.nilErr {
+				if err == nil {
+					t.Errorf("expected a non-nil error, got : %v", err)
+				}
+				return
+			}
+			if len(output) != tc.findings {
+				t.Errorf("expected: %v, got: %v", tc.findings, output)
+			}
+			if duration <= 0 {
+				t.Errorf("expected: a positive duration, got: %v", duration)
+			}
+		})
+	}
+}
+
+func TestScanValidatesSignatures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("-- dump\nCREATE TABLE users (id int);"))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "chopchop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "users.sql"), []byte("CREATE TABLE users"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]struct {
+		matchFile string
+		findings  int
+		nilErr    bool
+	}{
+		"match file and request headers loaded": {matchFile: "users.sql", findings: 1, nilErr: true},
+		"missing match file":                    {matchFile: "missing.sql", nilErr: false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			signatures := &core.Signatures{Plugins: []*core.Plugin{{
+				Endpoint:       "/backup.sql",
+				RequestHeaders: []string{"X-Token: secret"},
+				Dir:            dir,
+				Checks:         []*core.Check{{Name: "Backup", Severity: "High", Description: "d", Remediation: "r", MatchFile: tc.matchFile}},
+			}}}
+			config := &core.Config{Urls: []string{server.URL}, Threads: 1, HTTP: core.HTTPConfig{Timeout: 5}}
+
+			output, _, err := chopchop.Scan(context.Background(), config, signatures)
+			if (err == nil) != tc.nilErr {
+				t.Fatalf("expected a nil error: %v, got: %v", tc.nilErr, err)
+			}
+			if len(output) != tc.findings {
+				t.Errorf("expected: %v, got: %v", tc.findings, output)
+			}
+		})
+	}
+}
+
+func TestScanCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	config := &core.Config{Urls: []string{"http://127.0.0.1:1"}, Threads: 1}
+	_, _, err := chopchop.Scan(ctx, config, &core.Signatures{})
+	if err != context.Canceled {
+		t.Errorf("expected: %v, got: %v", context.Canceled, err)
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	var ok int32 = 200
+	signatures := &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/admin", Checks: []*core.Check{
+		{Name: "Admin panel", StatusCode: &ok, MustMatchOne: []string{"Dashboard", "Admin"}, Headers: []string{"X-Powered-By:PHP"}},
+		{Name: "Broken", MustMatchAll: []string{"admin"}, MustNotMatch: []string{"adm"}},
+		{Name: "Version", MatchRegex: []string{"v[0-9]+"}},
+	}}}}
+
+	results, err := chopchop.SelfTest(context.Background(), signatures)
+	if err != nil {
+		t.Fatalf("expected a nil error, got : %v", err)
+	}
+	want := []chopchop.SelfTestResult{
+		{Check: "Admin panel", Fired: true},
+		{Check: "Broken", Fired: false},
+		{Check: "Version", Skipped: `no fixture for the regex "v[0-9]+"`},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("expected: %v, got: %v", want, results)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("expected: %v, got: %v", want[i], results[i])
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package chopchop
+
+import (
+	"context"
+	"gochopchop/core"
+	"gochopchop/internal/httpget"
+	"net/http/httptest"
+)
+
+// SelfTestResult is the outcome of a check against the fixture built from its own conditions
+type SelfTestResult struct {
+	Check string
+	// Fired tells whether the check reported a finding on its fixture
+	Fired bool
+	// Skipped tells why no fixture could be built for the check, empty when it was run
+	Skipped string
+}
+
+// SelfTest scans an in-process server answering each check with the response it looks for, built by core.NewFixture.
+// A check which doesn't fire on its own fixture is broken, or the matching engine is.
+func SelfTest(ctx context.Context, signatures *core.Signatures) ([]SelfTestResult, error) {
+	config := core.HTTPConfig{Timeout: 10}
+	fetcher := httpget.NewFetcher(config)
+	noRedirectFetcher := httpget.NewNoRedirectFetcher(config)
+
+	var results []SelfTestResult
+	for _, plugin := range signatures.Plugins {
+		for _, check := range plugin.Checks {
+			fixture, err := core.NewFixture(plugin, check)
+			if err != nil {
+				results = append(results, SelfTestResult{Check: check.Name, Skipped: err.Error()})
+				continue
+			}
+			fired, err := selfTestCheck(ctx, fetcher, noRedirectFetcher, plugin, check, fixture)
+			if err != nil {
+				return results, err
+			}
+			results = append(results, SelfTestResult{Check: check.Name, Fired: fired})
+		}
+	}
+	return results, ctx.Err()
+}
+
+// selfTestCheck scans a server of its own with the plugin reduced to the check, so the fixtures of the checks sharing
+// an endpoint don't get in the way of each other
+func selfTestCheck(ctx context.Context, fetcher core.IFetcher, noRedirectFetcher core.IFetcher, plugin *core.Plugin, check *core.Check, fixture *core.Fixture) (bool, error) {
+	server := httptest.NewServer(fixture)
+	defer server.Close()
+
+	reduced := *plugin
+	reduced.Checks = []*core.Check{check}
+	scanner := core.NewScanner(fetcher, noRedirectFetcher, &core.Signatures{Plugins: []*core.Plugin{&reduced}}, 1)
+	outputs, err := scanner.Scan(ctx, []string{server.URL})
+	if err != nil {
+		return false, err
+	}
+	for _, output := range outputs {
+		if output.Name == check.Name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultsFilename is the file of the team-wide flag values, searched in the working directory then in the home directory
+var defaultsFilename = ".chopchop.yaml"
+
+// trustedDefaults are the flags running commands, sending the requests or the results elsewhere, turning off the TLS
+// verification, choosing the signatures or reading and writing files. A defaults file in the working directory may come
+// with an untrusted checkout, so they are only accepted from the command line or from the defaults file of the home directory.
+var trustedDefaults = []string{
+	"on-complete", "webhook", "proxy", "proxy-user", "proxy-pass-file", "basic-auth", "bearer-token", "resolver", "insecure", "signatures",
+	"requested-urls-file", "output-dir", "export-filename", "resume-file", "baseline",
+}
+
+// findDefaultsFile returns the path of the defaults file, empty when there is none, and whether it is the one of the home directory
+func findDefaultsFile() (string, bool) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = ""
+	}
+	if info, err := os.Stat(defaultsFilename); err == nil && !info.IsDir() {
+		// the working directory may be the home directory itself
+		homeFile, err := os.Stat(filepath.Join(home, defaultsFilename))
+		return defaultsFilename, home != "" && err == nil && os.SameFile(info, homeFile)
+	}
+	if home != "" {
+		path := filepath.Join(home, defaultsFilename)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// loadDefaults sets the flags of the command from the defaults file, the flags of the command line taking precedence.
+// The file maps the long flag names to their values, eg. threads: 10 or export: [json, csv].
+// The flags of the other commands are skipped, so a single file serves them all. It returns the path of the file, if any.
+func loadDefaults(cmd *cobra.Command) (string, error) {
+	path, home := findDefaultsFile()
+	if path == "" {
+		return "", nil
+	}
+	if err := loadDefaultsFile(cmd, path, home); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// loadDefaultsFile sets the flags of the command from the file, the trusted flags being refused unless it is the one of the home directory
+func loadDefaultsFile(cmd *cobra.Command, path string, home bool) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return fmt.Errorf("invalid defaults file %s: %v", path, err)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !home && contains(trustedDefaults, name) {
+			return fmt.Errorf("%s cannot be set by %s, only on the command line or in ~/%s", name, path, defaultsFilename)
+		}
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			if !knownFlag(cmd.Root(), name) {
+				log.Warn("Unknown flag ", name, " in ", path, " - skipping it")
+			}
+			continue
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(name, defaultValue(values[name])); err != nil {
+			return fmt.Errorf("invalid value for %s in %s: %v", name, path, err)
+		}
+	}
+	return nil
+}
+
+// defaultValue formats a value of the defaults file as on the command line, the lists being comma-separated
+func defaultValue(value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// knownFlag tells whether a command of the tree has the flag
+func knownFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil {
+		return true
+	}
+	for _, sub := range cmd.Commands() {
+		if knownFlag(sub, name) {
+			return true
+		}
+	}
+	return false
+}
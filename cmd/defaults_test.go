@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func defaultsCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "scan"}
+	cmd.Flags().Int("threads", 1, "")
+	cmd.Flags().String("on-complete", "", "")
+	cmd.Flags().String("webhook", "", "")
+	cmd.Flags().StringSlice("signatures", nil, "")
+	return cmd
+}
+
+func TestLoadDefaultsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chopchop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var tests = map[string]struct {
+		content string
+		home    bool
+		wantErr bool
+		flag    string
+		want    string
+	}{
+		"plain flag from the working directory":  {content: "threads: 10\n", flag: "threads", want: "10"},
+		"on-complete from the working directory": {content: "on-complete: touch pwned\n", wantErr: true},
+		"webhook from the working directory":     {content: "threads: 10\nwebhook: https://evil.example\n", wantErr: true},
+		"signatures from the working directory":  {content: "signatures: [./evil.yml]\n", wantErr: true},
+		"proxy of another command refused":       {content: "proxy: http://evil.example\n", wantErr: true},
+		"on-complete from the home directory":    {content: "on-complete: notify\n", home: true, flag: "on-complete", want: "notify"},
+		"signatures from the home directory":     {content: "signatures: [a.yml, b.yml]\n", home: true, flag: "signatures", want: "[a.yml,b.yml]"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, defaultsFilename)
+			if err := ioutil.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cmd := defaultsCommand()
+			err := loadDefaultsFile(cmd, path, tc.home)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if tc.flag != "" {
+				if have := cmd.Flags().Lookup(tc.flag).Value.String(); have != tc.want {
+					t.Errorf("expected: %v, got: %v", tc.want, have)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadDefaultsFileTrusted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chopchop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var tests = map[string]struct {
+		value string
+	}{
+		"on-complete":         {value: "touch pwned"},
+		"webhook":             {value: "https://evil.example"},
+		"proxy":               {value: "http://evil.example"},
+		"proxy-user":          {value: "user"},
+		"proxy-pass-file":     {value: "/tmp/pass"},
+		"basic-auth":          {value: "user:pass"},
+		"bearer-token":        {value: "token"},
+		"resolver":            {value: "203.0.113.1:53"},
+		"insecure":            {value: "true"},
+		"signatures":          {value: "[./evil.yml]"},
+		"requested-urls-file": {value: "/tmp/urls"},
+		"output-dir":          {value: "/etc"},
+		"export-filename":     {value: "../../.bashrc"},
+		"resume-file":         {value: "/tmp/resume"},
+		"baseline":            {value: "/etc/passwd"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, defaultsFilename)
+			if err := ioutil.WriteFile(path, []byte(name+": "+tc.value+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := loadDefaultsFile(defaultsCommand(), path, false); err == nil {
+				t.Errorf("expected: an error for %s in the working directory file, got: nil", name)
+			}
+			if err := loadDefaultsFile(defaultsCommand(), path, true); err != nil {
+				t.Errorf("expected: no error for %s in the home directory file, got: %v", name, err)
+			}
+		})
+	}
+}
+
+func TestFindDefaultsFile(t *testing.T) {
+	work, err := ioutil.TempDir("", "chopchop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(work)
+	home, err := ioutil.TempDir("", "chopchop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(work); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	if path, _ := findDefaultsFile(); path != "" {
+		t.Errorf("expected: no file, got: %v", path)
+	}
+	if err := ioutil.WriteFile(filepath.Join(home, defaultsFilename), []byte("threads: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, fromHome := findDefaultsFile(); path != filepath.Join(home, defaultsFilename) || !fromHome {
+		t.Errorf("expected: %v from the home directory, got: %v (home: %v)", filepath.Join(home, defaultsFilename), path, fromHome)
+	}
+	if err := ioutil.WriteFile(defaultsFilename, []byte("threads: 4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, fromHome := findDefaultsFile(); path != defaultsFilename || fromHome {
+		t.Errorf("expected: %v from the working directory, got: %v (home: %v)", defaultsFilename, path, fromHome)
+	}
+	if err := os.Chdir(home); err != nil {
+		t.Fatal(err)
+	}
+	if path, fromHome := findDefaultsFile(); path != defaultsFilename || !fromHome {
+		t.Errorf("expected: %v from the home directory, got: %v (home: %v)", defaultsFilename, path, fromHome)
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"gochopchop/core"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// runOnComplete runs the user provided command once the scan is over.
+// The exported files and a summary of the findings are passed through environment variables.
+func runOnComplete(ctx context.Context, command string, exportFiles []string, summary *core.Summary) error {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		c = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	c.Env = append(os.Environ(),
+		fmt.Sprintf("CHOPCHOP_EXPORT_FILES=%s", strings.Join(exportFiles, ",")),
+		fmt.Sprintf("CHOPCHOP_FINDINGS=%d", summary.Total),
+	)
+	for _, severity := range core.Severities() {
+		c.Env = append(c.Env, fmt.Sprintf("CHOPCHOP_FINDINGS_%s=%d", strings.ToUpper(severity), summary.BySeverity[severity]))
+	}
+
+	var out io.Writer = os.Stdout
+	if quiet {
+		out = os.Stderr
+	}
+	c.Stdout = out
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("on-complete command failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"gochopchop/core"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunOnComplete(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command is run by sh")
+	}
+	dir, err := ioutil.TempDir("", "chopchop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "env")
+
+	summary := core.NewSummary()
+	summary.Write(core.Output{Severity: "High"})
+	summary.Write(core.Output{Severity: "Low"})
+	summary.Write(core.Output{Severity: "High"})
+	command := `printf '%s\n' "$CHOPCHOP_EXPORT_FILES" "$CHOPCHOP_FINDINGS" "$CHOPCHOP_FINDINGS_HIGH" "$CHOPCHOP_FINDINGS_CRITICAL" > ` + out
+	if err := runOnComplete(context.Background(), command, []string{"results.json", "results.csv"}, summary); err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"results.json,results.csv", "3", "2", "0"}
+	have := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+	if strings.Join(have, "|") != strings.Join(want, "|") {
+		t.Errorf("expected: %v, got: %v", want, have)
+	}
+
+	if err := runOnComplete(context.Background(), "exit 3", nil, summary); err == nil {
+		t.Errorf("expected the failure of the command to be returned")
+	}
+}
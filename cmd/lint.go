@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"gochopchop/core"
+)
+
+// yamlErrorRegexp splits the "line N: message" errors of yaml.v2
+var yamlErrorRegexp = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// checkNameRegexp matches a name field, without the indentation and the dash of a list item
+var checkNameRegexp = regexp.MustCompile(`^name:\s*(.+?)\s*$`)
+
+func init() {
+	lintCmd := &cobra.Command{
+		Use:   "lint [signature files]",
+		Short: "validate signature files and report all their problems at once",
+		RunE:  runLint,
+	}
+	addSignaturesFlag(lintCmd)
+
+	rootCmd.AddCommand(lintCmd)
+}
+
+// lintProblem is a problem of a signature file, at the line of the plugin or check it belongs to when known
+type lintProblem struct {
+	file    string
+	line    int
+	message string
+}
+
+func (p lintProblem) String() string {
+	if p.line == 0 {
+		return fmt.Sprintf("%s: %s", p.file, p.message)
+	}
+	return fmt.Sprintf("%s:%d: %s", p.file, p.line, p.message)
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	patterns := args
+	if len(patterns) == 0 {
+		var err error
+		if patterns, err = cmd.Flags().GetStringSlice(signatureFlagName); err != nil {
+			return fmt.Errorf("Invalid value for signatureFile: %v", err)
+		}
+	}
+	signatureFiles, err := expandSignatureFiles(patterns)
+	if err != nil {
+		return err
+	}
+	strictCategories, err := cmd.Flags().GetBool("strict-categories")
+	if err != nil {
+		return fmt.Errorf("Invalid value for strict-categories: %v", err)
+	}
+
+	var problems []lintProblem
+	checkFiles := make(map[string]string)
+	for _, signatureFile := range signatureFiles {
+		problems = append(problems, lintFile(signatureFile, strictCategories, checkFiles)...)
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stdout, problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s) found in the signatures", len(problems))
+	}
+	log.Info("No problem found in ", len(signatureFiles), " signature file(s)")
+	return nil
+}
+
+// lintFile reports every problem of the signature file, unlike the scan stopping at the first one.
+// The unknown fields, often typos, are reported too.
+func lintFile(signatureFile string, strictCategories bool, checkFiles map[string]string) []lintProblem {
+	var problems []lintProblem
+	report := func(line int, err error) {
+		message := strings.TrimSuffix(err.Error(), ". Stopping execution")
+		problems = append(problems, lintProblem{file: signatureFile, line: line, message: message})
+	}
+
+	data, err := ioutil.ReadFile(signatureFile)
+	if err != nil {
+		report(0, err)
+		return problems
+	}
+	signatures := core.NewSignatures()
+	if err := yaml.UnmarshalStrict(data, signatures); err != nil {
+		typeErr, ok := err.(*yaml.TypeError)
+		if !ok {
+			report(0, err)
+			return problems
+		}
+		for _, message := range typeErr.Errors {
+			if m := yamlErrorRegexp.FindStringSubmatch(message); m != nil {
+				line, _ := strconv.Atoi(m[1])
+				report(line, fmt.Errorf("%s", m[2]))
+			} else {
+				report(0, fmt.Errorf("%s", message))
+			}
+		}
+		// the values of the known fields are still validated
+		signatures = core.NewSignatures()
+		if err := yaml.Unmarshal(data, signatures); err != nil {
+			return problems
+		}
+	}
+
+	lines := locateSignatures(data)
+	dir := filepath.Dir(signatureFile)
+	for i, plugin := range signatures.Plugins {
+		pluginLine := lines.plugin(i)
+		if err := plugin.Interpolate(os.LookupEnv); err != nil {
+			report(pluginLine, err)
+		}
+		for _, err := range plugin.Validate(dir, strictCategories) {
+			report(pluginLine, err)
+		}
+		for _, check := range plugin.Checks {
+			checkLine := lines.check(check.Name, pluginLine)
+			if file, ok := checkFiles[check.Name]; ok && file != signatureFile {
+				report(checkLine, fmt.Errorf("Duplicate check %s, also in %s", check.Name, file))
+			} else if check.Name != "" {
+				checkFiles[check.Name] = signatureFile
+			}
+			for _, err := range check.Validate(dir) {
+				report(checkLine, err)
+			}
+		}
+	}
+	// the unknown fields are reported among the other problems, in the order of the file
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].line < problems[j].line })
+	return problems
+}
+
+// signatureLines are the lines of the plugins and checks of a signature file.
+// yaml.v2 doesn't keep the position of the decoded values, so they are looked up in the text of the file.
+type signatureLines struct {
+	plugins []int
+	checks  map[string]int
+}
+
+// locateSignatures finds the items of the plugins list, and the name field of each check.
+// Only the names of the items of a checks list are taken, not the ones of the cookies or extractors.
+func locateSignatures(data []byte) signatureLines {
+	lines := signatureLines{checks: make(map[string]int)}
+	inPlugins := false
+	pluginIndent := -1
+	// indentation of the current checks key, of its items and of the keys of its items, -1 outside of a checks list
+	checksIndent, itemIndent, keyIndent := -1, -1, -1
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		text := scanner.Text()
+		trimmed := strings.TrimLeft(text, " ")
+		indent := len(text) - len(trimmed)
+
+		// the key of the line, after the dash of a list item
+		key, keyAt := trimmed, indent
+		if strings.HasPrefix(trimmed, "- ") {
+			key = strings.TrimLeft(trimmed[1:], " ")
+			keyAt = indent + len(trimmed) - len(key)
+		}
+		if checksIndent >= 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") &&
+			(indent < checksIndent || (indent == checksIndent && key == trimmed)) {
+			checksIndent, itemIndent, keyIndent = -1, -1, -1
+		}
+		switch {
+		case strings.HasPrefix(key, "checks:"):
+			checksIndent, itemIndent, keyIndent = keyAt, -1, -1
+		case checksIndent >= 0 && key != trimmed && (itemIndent < 0 || indent == itemIndent):
+			itemIndent, keyIndent = indent, keyAt
+		}
+		if keyIndent >= 0 && keyAt == keyIndent {
+			if m := checkNameRegexp.FindStringSubmatch(key); m != nil {
+				name := strings.Trim(m[1], `"'`)
+				if _, ok := lines.checks[name]; !ok {
+					lines.checks[name] = n
+				}
+			}
+		}
+
+		switch {
+		case indent == 0 && strings.HasPrefix(trimmed, "plugins:"):
+			inPlugins = true
+		case indent == 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "- "):
+			inPlugins = false
+		case inPlugins && strings.HasPrefix(trimmed, "- "):
+			if pluginIndent < 0 {
+				pluginIndent = indent
+			}
+			if indent == pluginIndent {
+				lines.plugins = append(lines.plugins, n)
+			}
+		}
+	}
+	return lines
+}
+
+func (l signatureLines) plugin(i int) int {
+	if i < len(l.plugins) {
+		return l.plugins[i]
+	}
+	return 0
+}
+
+func (l signatureLines) check(name string, fallback int) int {
+	if line, ok := l.checks[name]; ok && name != "" {
+		return line
+	}
+	return fallback
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const lintedSignatures = `---
+insecure: false
+plugins:
+  - endpoint: "/login"
+    steps:
+      - endpoint: "/login"
+        extract:
+          - name: token
+            regex: "token=([a-z]+)"
+    checks:
+      - name: Session cookie
+        cookie:
+          name: JSESSIONID
+          secure: false
+        severity: Medium
+  - endpoint: "/admin"
+    checks:
+      -   severity: High
+          name: "Admin panel"
+      - name: token
+        severity: Low
+`
+
+func TestLocateSignatures(t *testing.T) {
+	lines := locateSignatures([]byte(lintedSignatures))
+
+	if want := []int{4, 16}; !reflect.DeepEqual(lines.plugins, want) {
+		t.Errorf("expected: %v, got: %v", want, lines.plugins)
+	}
+	var tests = map[string]struct {
+		name string
+		want int
+	}{
+		"name on the item line":        {name: "Session cookie", want: 11},
+		"name after another field":     {name: "Admin panel", want: 19},
+		"name of a cookie not a check": {name: "JSESSIONID", want: 0},
+		"name of an extractor skipped": {name: "token", want: 20},
+		"unknown check falls back":     {name: "Missing", want: 0},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := lines.check(tc.name, 0)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestLintLegacyInsecure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chopchop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "chopchop.yml")
+	signatures := "---\ninsecure: false\nplugins:\n  - endpoint: \"/\"\n    checks:\n      - name: Root\n        severity: Low\n        description: d\n        remediation: r\n"
+	if err := ioutil.WriteFile(file, []byte(signatures), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if problems := lintFile(file, false, make(map[string]string)); len(problems) != 0 {
+		t.Errorf("expected: no problem, got: %v", problems)
+	}
+}
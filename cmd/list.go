@@ -3,14 +3,21 @@ package cmd
 import (
 	"fmt"
 	"gochopchop/core"
+	"gochopchop/internal/export"
 	"os"
+	"strings"
 
 	"github.com/jedib0t/go-pretty/table"
 	"github.com/spf13/cobra"
 )
 
+var validListExportFormats = []string{"json", "csv"}
+
 type listOptions struct {
 	Severity string
+	// ExportFormats replace the table, printed on stdout unless ExportFilename is set
+	ExportFormats  []string
+	ExportFilename string
 }
 
 func init() {
@@ -20,7 +27,10 @@ func init() {
 		RunE:  runList,
 	}
 	addSignaturesFlag(pluginCmd)
-	pluginCmd.Flags().StringP("severity", "s", "", "severity option for list tag") // --severity ou -s
+	pluginCmd.Flags().StringP("severity", "s", "", "severity option for list tag")                                              // --severity ou -s
+	pluginCmd.Flags().StringSliceP("export", "e", []string{}, "export of the checks instead of the table (json and csv)")       // --export ou -e
+	pluginCmd.Flags().StringP("export-filename", "", "", "filename for export files, the export is printed on stdout if empty") // --export-filename
+	pluginCmd.Flags().BoolP("show-disabled", "", false, "also list the disabled checks, marked as disabled")                    // --show-disabled
 
 	rootCmd.AddCommand(pluginCmd)
 }
@@ -34,19 +44,39 @@ func runList(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if len(options.ExportFormats) > 0 {
+		checks := make([]export.CheckEntry, 0)
+		for _, plugin := range signatures.Plugins {
+			for _, check := range plugin.Checks {
+				if options.Severity == "" || options.Severity == check.Severity {
+					checks = append(checks, export.NewCheckEntry(plugin, check))
+				}
+			}
+		}
+		for _, format := range options.ExportFormats {
+			if err := export.ExportChecks(options.ExportFilename, format, checks); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	cpt := 0
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"URL", "Plugin Name", "Severity", "Description"})
+	t.AppendHeader(table.Row{"URL", "Category", "Plugin Name", "Severity", "Description"})
 	for _, plugin := range signatures.Plugins {
 		for _, check := range plugin.Checks {
 			if options.Severity == "" || options.Severity == string(check.Severity) {
-				t.AppendRow([]interface{}{plugin.Endpoint, check.Name, check.Severity, check.Description})
+				name := check.Name
+				if !check.IsEnabled() {
+					name += " (disabled)"
+				}
+				t.AppendRow([]interface{}{plugin.Endpoint, plugin.Category, name, check.Severity, check.Description})
 				cpt++
 			}
 		}
 	}
-	t.AppendFooter(table.Row{"", "", "Total Checks", cpt})
+	t.AppendFooter(table.Row{"", "", "", "Total Checks", cpt})
 	t.Render()
 	return nil
 }
@@ -63,5 +93,23 @@ func parseOptions(cmd *cobra.Command) (*listOptions, error) {
 		}
 		options.Severity = severity
 	}
+	exportFormats, err := cmd.Flags().GetStringSlice("export")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for export formats: %v", err)
+	}
+	for _, f := range exportFormats {
+		if !contains(validListExportFormats, f) {
+			return nil, fmt.Errorf("invalid value for export: %v , expected %s", f, strings.Join(validListExportFormats, ", "))
+		}
+	}
+	exportFilename, err := cmd.Flags().GetString("export-filename")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for export-filename: %v", err)
+	}
+	if len(exportFormats) > 1 && exportFilename == "" {
+		return nil, fmt.Errorf("Several export formats can't be printed on stdout, please set the export-filename flag")
+	}
+	options.ExportFormats = exportFormats
+	options.ExportFilename = exportFilename
 	return options, nil
 }
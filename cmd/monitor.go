@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal/formatting"
+	"gochopchop/internal/httpget"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	monitorCmd := &cobra.Command{
+		Use:   "monitor",
+		Short: "request a list of urls and report the ones not answering with their expected status code",
+		RunE:  runMonitor,
+	}
+	monitorCmd.Flags().StringP("url-file", "u", "", "path to a file of \"URL STATUS\" lines, eg. \"https://foobar.com/health 200\"")                          // --url-file ou -u
+	monitorCmd.Flags().BoolP("insecure", "k", false, "Check SSL certificate")                                                                                 // --insecure ou -k
+	monitorCmd.Flags().IntP("timeout", "t", 10, "Timeout for the HTTP requests (default: 10s)")                                                               // --timeout ou -t
+	monitorCmd.Flags().BoolP("follow-redirects", "", false, "compare the status code of the final response, after the redirects")                             // --follow-redirects
+	monitorCmd.Flags().StringP("severity", "", "High", "severity of the deviations")                                                                          // --severity
+	monitorCmd.Flags().StringSliceP("export", "e", []string{}, "export of the output (csv, json, ndjson, defectdojo, markdown, asff, sarif, html and junit)") // --export ou -e
+	monitorCmd.Flags().StringP("export-filename", "", "", "filename for export files")                                                                        // --export-filename
+	monitorCmd.Flags().StringP("output-dir", "", "", "directory of the export files, created if missing")                                                     // --output-dir
+	monitorCmd.Flags().StringP("asff-account-id", "", "", "AWS account id of the asff export")                                                                // --asff-account-id
+	monitorCmd.Flags().StringP("asff-region", "", "", "AWS region of the asff export")                                                                        // --asff-region
+	monitorCmd.Flags().StringP("asff-product-arn", "", "", "product ARN of the asff export")                                                                  // --asff-product-arn
+	// a deviation makes the monitoring fail unless --max-severity is over its --severity
+	addThresholdFlags(monitorCmd, "Informational")
+	monitorCmd.MarkFlagRequired("url-file")
+
+	rootCmd.AddCommand(monitorCmd)
+}
+
+func runMonitor(cmd *cobra.Command, args []string) error {
+	urlFile, err := cmd.Flags().GetString("url-file")
+	if err != nil {
+		return fmt.Errorf("invalid value for url-file: %v", err)
+	}
+	insecure, err := cmd.Flags().GetBool("insecure")
+	if err != nil {
+		return fmt.Errorf("invalid value for insecure: %v", err)
+	}
+	timeout, err := cmd.Flags().GetInt("timeout")
+	if err != nil {
+		return fmt.Errorf("invalid value for timeout: %v", err)
+	}
+	followRedirects, err := cmd.Flags().GetBool("follow-redirects")
+	if err != nil {
+		return fmt.Errorf("invalid value for follow-redirects: %v", err)
+	}
+	severity, err := cmd.Flags().GetString("severity")
+	if err != nil {
+		return fmt.Errorf("invalid value for severity: %v", err)
+	}
+	if !core.ValidSeverity(severity) {
+		return fmt.Errorf("Invalid severity level : %s. Please use : %s", severity, core.SeveritiesAsString())
+	}
+	thresholds, err := parseThresholds(cmd)
+	if err != nil {
+		return err
+	}
+	exportFormats, err := cmd.Flags().GetStringSlice("export")
+	if err != nil {
+		return fmt.Errorf("invalid value for export formats: %v", err)
+	}
+	for _, f := range exportFormats {
+		if !contains(validExportFormats, f) {
+			return fmt.Errorf("invalid value for export: %v , expected %s", f, strings.Join(validExportFormats, ", "))
+		}
+	}
+	asff, err := parseASFFConfig(cmd, contains(exportFormats, "asff"))
+	if err != nil {
+		return err
+	}
+	exportFilename, err := cmd.Flags().GetString("export-filename")
+	if err != nil {
+		return fmt.Errorf("invalid value for exportFilename: %v", err)
+	}
+	if exportFilename == "" {
+		exportFilename = fmt.Sprintf("gochopchop_monitor_%s", time.Now().Format("2006-01-02_15-04-05"))
+	}
+	outputDir, err := cmd.Flags().GetString("output-dir")
+	if err != nil {
+		return fmt.Errorf("invalid value for output-dir: %v", err)
+	}
+	exportFilename = exportPath(outputDir, exportFilename)
+	threads, err := rootCmd.Flags().GetInt("threads")
+	if err != nil {
+		return fmt.Errorf("invalid value for threads: %w", err)
+	}
+	if threads <= 0 {
+		return fmt.Errorf("The number of threads must be positive")
+	}
+
+	file, err := os.Open(urlFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	probes, err := core.ParseProbes(file)
+	if err != nil {
+		return err
+	}
+	if len(probes) == 0 {
+		return fmt.Errorf("No url loaded from %s", urlFile)
+	}
+
+	proxyUser, proxyPass, err := parseProxyCredentials(rootCmd.Flags())
+	if err != nil {
+		return err
+	}
+	proxy, err := parseProxy()
+	if err != nil {
+		return err
+	}
+	userAgent, err := parseUserAgent()
+	if err != nil {
+		return err
+	}
+	resolver, dnsCacheTTL, err := parseResolver()
+	if err != nil {
+		return err
+	}
+	httpConfig := core.HTTPConfig{Insecure: insecure, Timeout: timeout, Proxy: proxy, ProxyUser: proxyUser, ProxyPass: proxyPass, UserAgent: userAgent, Resolver: resolver, DNSCacheTTL: dnsCacheTTL}
+	if err := httpget.VerifyProxy(cmd.Context(), httpConfig); err != nil {
+		return err
+	}
+	fetcher := httpget.NewNoRedirectFetcher(httpConfig)
+	if followRedirects {
+		fetcher = httpget.NewFetcher(httpConfig)
+	}
+	begin := time.Now()
+	result := core.Monitor(cmd.Context(), fetcher, probes, threads, severity)
+
+	exportConfig := &core.Config{
+		ExportFormats:      exportFormats,
+		ExportFilename:     exportFilename,
+		ASFF:               asff,
+		MaxSeverity:        thresholds.maxSeverity,
+		WarnSeverity:       thresholds.warnSeverity,
+		NoFindingsExitCode: thresholds.noFindingsExitCode,
+		FindingsExitCode:   thresholds.findingsExitCode,
+		BlockExitCode:      thresholds.blockExitCode,
+	}
+	if len(result) == 0 {
+		log.Info("All the urls answered with their expected status code")
+		if exportConfig.NoFindingsExitCode != 0 {
+			cmd.SilenceErrors = true
+			return &exitError{code: exportConfig.NoFindingsExitCode, err: fmt.Errorf("No deviation found"), silent: true}
+		}
+		return nil
+	}
+	if !quiet {
+		formatting.PrintTable(result, os.Stdout, []string{"url", "severity", "details"})
+	}
+	metadata := core.Metadata{Version: core.Version, StartTime: begin.UTC(), EndTime: time.Now().UTC(), Targets: len(probes)}
+	exportResults(exportConfig, result, metadata)
+	// the probes are only reported once all done, the ndjson export is written from the results
+	fileWriters, err := openExportWriters(exportConfig, func() core.Metadata { return metadata })
+	if err != nil {
+		return err
+	}
+	for _, output := range result {
+		for _, w := range fileWriters {
+			w.Write(output)
+		}
+	}
+	closeExportWriters(fileWriters)
+	log.Warn(fmt.Sprintf("%d of %d urls did not answer with their expected status code", len(result), len(probes)))
+	return thresholdsError(cmd, exportConfig, core.Summarize(result), result)
+}
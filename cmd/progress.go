@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"gochopchop/core"
+	"math"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// reportProgress reports the progress of the scan every interval, until the returned function is called.
+// On a terminal a single line is updated in place, otherwise a structured log line is written each time.
+func reportProgress(progress *core.Progress, interval time.Duration) func() {
+	terminal := isTerminal(os.Stderr)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				printProgress(progress.State(), terminal)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		printProgress(progress.State(), terminal)
+		if terminal {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
+
+func printProgress(state core.ProgressState, terminal bool) {
+	if terminal {
+		fmt.Fprintf(os.Stderr, "\r\033[K%s", state)
+		return
+	}
+	fields := log.Fields{
+		"completed": state.Completed,
+		"total":     state.Total,
+		"percent":   math.Round(state.Percent()*10) / 10,
+	}
+	if remaining, ok := state.Remaining(); ok {
+		fields["remaining"] = remaining.String()
+	}
+	log.WithFields(fields).Warn("Scan progress")
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
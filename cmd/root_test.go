@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestParseProxyCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chopchop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	passFile := filepath.Join(dir, "proxy-pass")
+	if err := ioutil.WriteFile(passFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = map[string]struct {
+		user     string
+		passFile string
+		env      map[string]string
+		wantUser string
+		wantPass string
+		wantErr  bool
+	}{
+		"no credentials":         {},
+		"environment":            {env: map[string]string{"CHOPCHOP_PROXY_USER": "scanner", "CHOPCHOP_PROXY_PASS": "secret"}, wantUser: "scanner", wantPass: "secret"},
+		"user flag":              {user: "flag", env: map[string]string{"CHOPCHOP_PROXY_USER": "scanner", "CHOPCHOP_PROXY_PASS": "secret"}, wantUser: "flag", wantPass: "secret"},
+		"password file":          {user: "scanner", passFile: passFile, wantUser: "scanner", wantPass: "from-file"},
+		"password file over env": {user: "scanner", passFile: passFile, env: map[string]string{"CHOPCHOP_PROXY_PASS": "secret"}, wantUser: "scanner", wantPass: "from-file"},
+		"missing password file":  {user: "scanner", passFile: filepath.Join(dir, "missing"), wantErr: true},
+		"password without user":  {env: map[string]string{"CHOPCHOP_PROXY_PASS": "secret"}, wantErr: true},
+		"password file, no user": {passFile: passFile, wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"CHOPCHOP_PROXY_USER", "CHOPCHOP_PROXY_PASS"} {
+				defer os.Setenv(key, os.Getenv(key))
+				os.Setenv(key, tc.env[key])
+			}
+			flags := pflag.NewFlagSet("chopchop", pflag.ContinueOnError)
+			flags.String("proxy-user", tc.user, "")
+			flags.String("proxy-pass-file", tc.passFile, "")
+
+			user, pass, err := parseProxyCredentials(flags)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if user != tc.wantUser || pass != tc.wantPass {
+				t.Errorf("expected: %v/%v, got: %v/%v", tc.wantUser, tc.wantPass, user, pass)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal/httpget"
+	"os"
+
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	runCheckCmd := &cobra.Command{
+		Use:   "run-check",
+		Short: "run a single check against an url and detail the outcome of each of its conditions",
+		RunE:  runCheck,
+	}
+	addSignaturesFlag(runCheckCmd)
+	runCheckCmd.Flags().StringP("name", "n", "", "name of the check to run")                     // --name ou -n
+	runCheckCmd.Flags().StringP("url", "", "", "url to run the check against")                   // --url
+	runCheckCmd.Flags().BoolP("insecure", "k", false, "Check SSL certificate")                   // --insecure ou -k
+	runCheckCmd.Flags().IntP("timeout", "t", 10, "Timeout for the HTTP requests (default: 10s)") // --timeout ou -t
+	runCheckCmd.MarkFlagRequired("name")
+	runCheckCmd.MarkFlagRequired("url")
+
+	rootCmd.AddCommand(runCheckCmd)
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	signatures, err := parseSignatures(cmd)
+	if err != nil {
+		return err
+	}
+	name, err := cmd.Flags().GetString("name")
+	if err != nil {
+		return fmt.Errorf("invalid value for name: %v", err)
+	}
+	url, err := cmd.Flags().GetString("url")
+	if err != nil {
+		return fmt.Errorf("invalid value for url: %v", err)
+	}
+	if !isURL(url) {
+		return fmt.Errorf("Please provide a valid URL")
+	}
+	insecure, err := cmd.Flags().GetBool("insecure")
+	if err != nil {
+		return fmt.Errorf("invalid value for insecure: %v", err)
+	}
+	timeout, err := cmd.Flags().GetInt("timeout")
+	if err != nil {
+		return fmt.Errorf("invalid value for timeout: %v", err)
+	}
+
+	var plugin *core.Plugin
+	var check *core.Check
+	for _, p := range signatures.Plugins {
+		for _, c := range p.Checks {
+			if c.Name == name {
+				plugin, check = p, c
+				break
+			}
+		}
+	}
+	if check == nil {
+		return fmt.Errorf("No check named %s in the signatures", name)
+	}
+	if len(plugin.Steps) > 0 {
+		return fmt.Errorf("The steps of %s can't be run with run-check, please use the scan command", name)
+	}
+
+	proxyUser, proxyPass, err := parseProxyCredentials(rootCmd.Flags())
+	if err != nil {
+		return err
+	}
+	proxy, err := parseProxy()
+	if err != nil {
+		return err
+	}
+	userAgent, err := parseUserAgent()
+	if err != nil {
+		return err
+	}
+	resolver, dnsCacheTTL, err := parseResolver()
+	if err != nil {
+		return err
+	}
+	httpConfig := core.HTTPConfig{Insecure: insecure, Timeout: timeout, Proxy: proxy, ProxyUser: proxyUser, ProxyPass: proxyPass, UserAgent: userAgent, Resolver: resolver, DNSCacheTTL: dnsCacheTTL}
+	if err := httpget.VerifyProxy(cmd.Context(), httpConfig); err != nil {
+		return err
+	}
+	var fetcher core.IFetcher
+	if plugin.FollowsRedirects() {
+		fetcher = httpget.NewFetcher(httpConfig)
+	} else {
+		fetcher = httpget.NewNoRedirectFetcher(httpConfig)
+	}
+
+	matched := false
+	for _, target := range plugin.Targets(url, "") {
+		fullURL := target.URL
+		fmt.Fprintf(os.Stdout, "%s\n", fullURL)
+
+		resp, err := fetcher.Fetch(plugin.NewRequest(fullURL))
+		if err != nil {
+			fmt.Fprintf(os.Stdout, "request failed: %v\n\n", err)
+			continue
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"Condition", "Result", "Detail"})
+		for _, result := range check.Evaluate(resp) {
+			t.AppendRow(table.Row{result.Condition, passOrFail(result.Passed), result.Detail})
+		}
+		endpointMatched := check.Match(resp)
+		t.AppendFooter(table.Row{"Check", passOrFail(endpointMatched), check.Details(resp)})
+		t.Render()
+		fmt.Fprintln(os.Stdout)
+		matched = matched || endpointMatched
+	}
+
+	if !matched {
+		return fmt.Errorf("Check %s did not match", check.Name)
+	}
+	return nil
+}
+
+func passOrFail(passed bool) string {
+	if passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
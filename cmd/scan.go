@@ -2,19 +2,28 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"gochopchop/chopchop"
 	"gochopchop/core"
 	"gochopchop/internal/export"
 	"gochopchop/internal/formatting"
 	"gochopchop/internal/httpget"
+	"io"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+var validExportFormats = []string{"csv", "json", "ndjson", "defectdojo", "markdown", "asff", "sarif", "html", "junit"}
+var validNoFindings = []string{"log", "silent", "json"}
+
 func init() {
 	scanCmd := &cobra.Command{
 		Use:   "scan",
@@ -22,15 +31,56 @@ func init() {
 		RunE:  runScan,
 	}
 	addSignaturesFlag(scanCmd)
+	addThresholdFlags(scanCmd, "")
 
-	scanCmd.Flags().BoolP("insecure", "k", false, "Check SSL certificate")                                                                                    // --insecure ou -n
-	scanCmd.Flags().StringP("url-file", "u", "", "path to a specified file containing urls to test")                                                          // --uri-file ou -f
-	scanCmd.Flags().StringP("max-severity", "b", "", "block the CI pipeline if severity is over or equal specified flag")                                     // --max-severity ou -m
-	scanCmd.Flags().StringSliceP("export", "e", []string{}, "export of the output (csv and json)")                                                            //--export ou --e
-	scanCmd.Flags().StringP("export-filename", "", "", "filename for export files")                                                                           // --export-filename
-	scanCmd.Flags().IntP("timeout", "t", 10, "Timeout for the HTTP requests (default: 10s)")                                                                  // --timeout ou -ts
-	scanCmd.Flags().StringP("severity-filter", "", "", "Filter by severity (engine will check for same severity checks)")                                     // --severity-filter
-	scanCmd.Flags().StringSliceP("plugin-filters", "", []string{}, "Filter by the name of the plugin (engine will only check for plugin with the same name)") // --plugin-filter
+	scanCmd.Flags().BoolP("insecure", "k", false, "Check SSL certificate")                                                                                             // --insecure ou -n
+	scanCmd.Flags().StringP("url-file", "u", "", "path to a specified file containing urls to test (- for stdin)")                                                     // --uri-file ou -f
+	scanCmd.Flags().BoolP("stdin", "", false, "read the urls to test from stdin, same as --url-file -")                                                                // --stdin
+	scanCmd.Flags().StringP("cidr-scheme", "", "http", "scheme of the hosts of the IP addresses and CIDR ranges without one (http or https)")                          // --cidr-scheme
+	scanCmd.Flags().IntP("max-cidr-hosts", "", 1024, "maximum number of hosts a CIDR range can be expanded into")                                                      // --max-cidr-hosts
+	scanCmd.Flags().BoolP("fail-fast", "", false, "stop the scan on the first finding over --max-severity, the findings so far are still exported")                    // --fail-fast
+	scanCmd.Flags().StringSliceP("export", "e", []string{}, "export of the output (csv, json, ndjson, defectdojo, markdown, asff, sarif, html and junit)")             //--export ou --e
+	scanCmd.Flags().BoolP("stream", "", false, "stream the findings on stdout as newline-delimited JSON while scanning")                                               // --stream
+	scanCmd.Flags().StringP("asff-account-id", "", "", "AWS account id of the asff export")                                                                            // --asff-account-id
+	scanCmd.Flags().StringP("asff-region", "", "", "AWS region of the asff export")                                                                                    // --asff-region
+	scanCmd.Flags().StringP("asff-product-arn", "", "", "product ARN of the asff export (default: the default product of the account)")                                // --asff-product-arn
+	scanCmd.Flags().StringP("export-filename", "", "", "filename for export files")                                                                                    // --export-filename
+	scanCmd.Flags().StringP("output-dir", "", "", "directory of the export files, created if missing")                                                                 // --output-dir
+	scanCmd.Flags().StringP("basic-auth", "", "", "user:password sent with basic authentication on every request")                                                     // --basic-auth
+	scanCmd.Flags().StringP("bearer-token", "", "", "token sent as a bearer authorization on every request")                                                           // --bearer-token
+	scanCmd.Flags().IntP("timeout", "t", 10, "Timeout for the HTTP requests (default: 10s)")                                                                           // --timeout ou -ts
+	scanCmd.Flags().StringP("http-version", "", "auto", "HTTP version of the requests (auto, 1.1 or 2), HTTP/2 being only negotiated over TLS")                        // --http-version
+	scanCmd.Flags().IntP("max-redirects", "", 10, "maximum number of redirects followed by the plugins following the redirects, more is an error")                     // --max-redirects
+	scanCmd.Flags().StringP("severity-filter", "", "", "Filter by severity (engine will check for same severity checks)")                                              // --severity-filter
+	scanCmd.Flags().StringP("min-severity", "", "", "Filter by minimum severity (engine will check for the checks of this severity or more critical)")                 // --min-severity
+	scanCmd.Flags().StringSliceP("plugin-filters", "", []string{}, "Filter by the name of the plugin (engine will only check for plugin with the same name)")          // --plugin-filter
+	scanCmd.Flags().StringSliceP("tags", "", []string{}, "only run the plugins having at least one of these tags (eg. cms,exposure)")                                  // --tags
+	scanCmd.Flags().StringSliceP("exclude-tags", "", []string{}, "do not run the plugins having any of these tags")                                                    // --exclude-tags
+	scanCmd.Flags().StringP("base-path", "", "", "path prefix prepended to every plugin endpoint (eg. /app)")                                                          // --base-path
+	scanCmd.Flags().BoolP("risk-score", "", false, "print a risk score per host, computed from the severities of its findings")                                        // --risk-score
+	scanCmd.Flags().StringSliceP("risk-weights", "", []string{}, "weight of each severity in the risk score (eg. High=10,Medium=5)")                                   // --risk-weights
+	scanCmd.Flags().Float64P("rate-limit", "", 0, "requests per second to each host, unlimited when 0")                                                                // --rate-limit
+	scanCmd.Flags().IntP("max-per-host", "", 0, "requests in flight to each host, the threads working on the other hosts meanwhile, unlimited when 0")                 // --max-per-host
+	scanCmd.Flags().StringSliceP("rate-limits", "", []string{}, "requests per second for the checks of each severity (eg. High=1,Medium=5), unlimited by default")     // --rate-limits
+	scanCmd.Flags().StringP("on-complete", "", "", "shell command to run once the scan is over (export files and summary are passed as environment variables)")        // --on-complete
+	scanCmd.Flags().StringP("webhook", "", "", "url to POST a JSON summary of the findings to once the scan is over (eg. a Slack incoming webhook)")                   // --webhook
+	scanCmd.Flags().StringP("baseline", "", "", "json export of a previous run, the findings are marked new, existing or resolved compared to it")                     // --baseline
+	scanCmd.Flags().BoolP("fail-on-new", "", false, "with --baseline, only the new findings make the scan fail (over --max-severity if set)")                          // --fail-on-new
+	scanCmd.Flags().IntP("table-limit", "", 1000, "number of findings above which compact lines are printed instead of the table (0: always print the table)")         // --table-limit
+	scanCmd.Flags().BoolP("show-timing", "", false, "add the response time of the findings to the results table")                                                      // --show-timing
+	scanCmd.Flags().StringSliceP("columns", "", []string{}, "columns of the table and csv outputs, in order (eg. domain,plugin,severity,url)")                         // --columns
+	scanCmd.Flags().StringP("requested-urls-file", "", "", "write the sorted list of the urls requested during the scan to this file")                                 // --requested-urls-file
+	scanCmd.Flags().StringP("resume-file", "", "", "save the progress of the scan to this file, and skip the work it records when the scan is restarted")              // --resume-file
+	scanCmd.Flags().StringP("no-findings", "", "log", "what to print when nothing is found (log, silent or json)")                                                     // --no-findings
+	scanCmd.Flags().BoolP("low-memory", "", false, "stream the findings to the csv and json exports instead of keeping them in memory, only their counts are printed") // --low-memory
+	scanCmd.Flags().BoolP("adaptive", "", false, "adapt the number of requests in flight (from 1 to --threads) to the error rate of the targets")                      // --adaptive
+	scanCmd.Flags().BoolP("progress", "", false, "report the completed and total requests with the estimated time remaining during the scan")                          // --progress
+	scanCmd.Flags().IntP("progress-interval", "", 10, "seconds between two progress reports")                                                                          // --progress-interval
+	scanCmd.Flags().BoolP("capture-evidence", "", false, "record in the findings a snippet of the body their check matched, the obvious secrets being redacted")       // --capture-evidence
+	scanCmd.Flags().BoolP("detect-blocking", "", false, "request a nonexistent path of each host first, to flag the hosts refusing every request (eg. a WAF)")         // --detect-blocking
+	scanCmd.Flags().BoolP("allow-empty", "", false, "do not fail when no url or no signature is left to scan")                                                         // --allow-empty
+	scanCmd.Flags().BoolP("validate-only", "", false, "Validate the configuration, signatures and url file then exit without scanning")                                // --validate-only
+	scanCmd.Flags().BoolP("dry-run", "", false, "print the urls that would be requested then exit without sending any request")                                        // --dry-run
 	rootCmd.AddCommand(scanCmd)
 }
 
@@ -40,55 +90,391 @@ func runScan(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	signatures, err := parseSignatures(cmd)
+	// the signatures are filtered and validated as chopchop.Scan does, so the library and the CLI run the same checks
+	signatures, err := loadSignatures(cmd)
 	if err != nil {
 		return err
 	}
+	if err := chopchop.Prepare(config, signatures); err != nil {
+		return err
+	}
+	if len(signatures.Plugins) == 0 && !config.AllowEmpty {
+		return fmt.Errorf("No signature left to scan after filtering, use --allow-empty to scan anyway")
+	}
+
+	var previous []core.Output
+	if config.Baseline != "" {
+		previous, err = export.ReadJSON(config.Baseline)
+		if err != nil {
+			return fmt.Errorf("Can't read the baseline %s: %v", config.Baseline, err)
+		}
+	}
+
+	if config.ValidateOnly {
+		log.Info("Configuration and signatures are valid. Exiting...")
+		return nil
+	}
+
+	if config.DryRun {
+		printTargets(config, signatures)
+		return nil
+	}
+	if err := httpget.VerifyProxy(cmd.Context(), config.HTTP); err != nil {
+		return err
+	}
 
 	begin := time.Now()
+	// the risk scores are only known once the scan is over
+	var risks []core.HostRisk
+	metadata := func() core.Metadata {
+		m := core.NewMetadata(config, signatures, begin)
+		m.RiskScores = risks
+		return m
+	}
 
-	fetcher := httpget.NewFetcher(config.HTTP.Insecure, config.HTTP.Timeout)
-	noRedirectFetcher := httpget.NewNoRedirectFetcher(config.HTTP.Insecure, config.HTTP.Timeout)
+	scanner := chopchop.NewScanner(config, signatures)
+	scanner.Pauser = core.NewPauser()
+	stopPause := notifyPause(scanner.Pauser)
+	defer stopPause()
+
+	var writers export.MultiWriter
+	if config.Stream {
+		writers = append(writers, export.NewNDJSONWriter(os.Stdout))
+	}
+	// in low memory mode the findings are only counted and streamed to the export files
+	summary := core.NewSummary()
+	if config.LowMemory {
+		scanner.DiscardFindings = true
+		writers = append(writers, summary)
+	}
+	fileWriters, err := openExportWriters(config, metadata)
+	if err != nil {
+		return err
+	}
+	for _, w := range fileWriters {
+		writers = append(writers, w)
+	}
 
-	scanner := core.NewScanner(fetcher, noRedirectFetcher, signatures, config.Threads)
+	// the findings of an interrupted scan are restored from the resume file
+	var checkpoint *core.Checkpoint
+	var restored []core.Output
+	if config.ResumeFile != "" {
+		checkpoint, err = core.NewCheckpoint(config.ResumeFile)
+		if err != nil {
+			return err
+		}
+		defer checkpoint.Close()
+		restored = checkpoint.Findings()
+		if len(restored) > 0 {
+			log.Info("Resuming the scan with ", len(restored), " findings restored from ", config.ResumeFile)
+		}
+		for _, output := range restored {
+			if config.LowMemory {
+				writers.Write(output)
+				continue
+			}
+			for _, w := range fileWriters {
+				w.Write(output)
+			}
+		}
+		scanner.Checkpoint = checkpoint
+		writers = append(writers, checkpoint)
+	}
+	ctx := cmd.Context()
+	if config.FailFast {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		writers = append(writers, &failFastWriter{maxSeverity: config.MaxSeverity, cancel: cancel})
+	}
+	if len(writers) > 0 {
+		scanner.Writer = writers
+	}
 
-	result, err := scanner.Scan(cmd.Context(), config.Urls)
+	// on interrupt the progress is saved and the findings so far are exported, once the workers stopped
+	stopInterrupt := onInterrupt(func() {
+		if err := checkpoint.Close(); err != nil {
+			log.Error(err)
+		}
+		partial := append(append([]core.Output{}, restored...), scanner.Results()...)
+		if config.RiskScore {
+			risks = core.RiskScores(partial, config.RiskWeights)
+		}
+		closeExportWriters(fileWriters)
+		if !config.LowMemory && len(partial) > 0 {
+			exportResults(config, partial, metadata())
+		}
+	})
+	stopProgress := func() {}
+	if config.Progress > 0 {
+		scanner.Progress = core.NewProgress()
+		stopProgress = reportProgress(scanner.Progress, config.Progress)
+	}
+	result, err := scanner.Scan(ctx, config.Urls)
+	stopProgress()
+	if blocked := scanner.BlockedHosts(); len(blocked) > 0 {
+		log.Warn(fmt.Sprintf("%d host(s) possibly blocked, their findings may be missing: %s", len(blocked), strings.Join(blocked, ", ")))
+	}
+	// the interrupt hook saves the progress once the command returned
+	if cmd.Context().Err() != nil {
+		cmd.SilenceErrors = true
+		return cmd.Context().Err()
+	}
+	stopInterrupt()
 	if err != nil {
 		return err
 	}
+	if err := checkpoint.Close(); err != nil {
+		log.Error(err)
+	}
+	if !config.LowMemory {
+		result = append(append([]core.Output{}, restored...), result...)
+	}
+	if config.Baseline != "" {
+		result = core.Diff(previous, result)
+	}
+	if !config.LowMemory {
+		summary = core.Summarize(result)
+	}
+	if config.RiskScore {
+		risks = core.RiskScores(result, config.RiskWeights)
+	}
+	summary.Requests = scanner.RequestsSent()
 
 	log.Info("Scan execution time:", time.Since(begin))
 
-	if len(result) > 0 {
+	if config.RequestedURLsFile != "" {
+		if err := export.ExportRequestedURLs(config.RequestedURLsFile, scanner.RequestedURLs()); err != nil {
+			log.Error(err)
+		}
+	}
 
-		formatting.PrintTable(result, os.Stdout)
+	// the resolved findings of the baseline are reported even when nothing is found anymore
+	if summary.Total > 0 || len(result) > 0 {
 
-		if contains(config.ExportFormats, "json") {
-			export.ExportJSON(config.ExportFilename, result)
+		// the streamed findings are the only content of stdout
+		if !quiet && !config.Stream && config.LowMemory {
+			formatting.PrintSummary(summary, os.Stdout)
+		} else if !quiet && !config.Stream {
+			if config.TableLimit > 0 && len(result) > config.TableLimit {
+				log.Warn("Too many findings to render a table (", len(result), " > ", config.TableLimit, "), printing compact lines instead. Use --export for the full details")
+				formatting.PrintLines(result, os.Stdout)
+			} else {
+				formatting.PrintTable(result, os.Stdout, tableColumns(config))
+			}
+			if config.RiskScore {
+				formatting.PrintRiskTable(risks, os.Stdout)
+			}
+			formatting.PrintSummary(summary, os.Stdout)
+		} else if config.RiskScore {
+			// stdout is kept for the machine-readable content, the risk scores are printed on stderr
+			formatting.PrintRiskTable(risks, os.Stderr)
 		}
-		if contains(config.ExportFormats, "csv") {
-			export.ExportCSV(config.ExportFilename, result)
+
+		exportFiles := closeExportWriters(fileWriters)
+		if !config.LowMemory {
+			exportFiles = append(exportFiles, exportResults(config, result, metadata())...)
 		}
 
-		if config.MaxSeverity != "" {
-			for _, output := range result {
-				if core.SeverityReached(config.MaxSeverity, output.Severity) {
-					return fmt.Errorf("Max severity level reached, exiting with error code")
-				}
+		if config.OnComplete != "" {
+			if err := runOnComplete(cmd.Context(), config.OnComplete, exportFiles, summary); err != nil {
+				log.Error(err)
 			}
 		}
+		notifyWebhook(cmd, config, summary, result)
+
+		return thresholdsError(cmd, config, summary, result)
 	} else {
-		log.Info("No vulnerabilities found. Exiting...")
+		// the empty reports are written too, eg. so the json export of a clean run is the --baseline of the next one
+		exportFiles := closeExportWriters(fileWriters)
+		if !config.LowMemory {
+			exportFiles = append(exportFiles, exportResults(config, result, metadata())...)
+		}
+		switch config.NoFindings {
+		case "json":
+			fmt.Fprintln(os.Stdout, `{"findings":0,"message":"No vulnerabilities found"}`)
+		case "silent":
+		default:
+			log.Info("No vulnerabilities found. Exiting...")
+		}
+		if config.OnComplete != "" {
+			if err := runOnComplete(cmd.Context(), config.OnComplete, exportFiles, summary); err != nil {
+				log.Error(err)
+			}
+		}
+		notifyWebhook(cmd, config, summary, result)
+		if config.NoFindingsExitCode != 0 {
+			silent := config.NoFindings != "log"
+			cmd.SilenceErrors = silent
+			return &exitError{code: config.NoFindingsExitCode, err: fmt.Errorf("No vulnerabilities found"), silent: silent}
+		}
 	}
 	return nil
 }
 
+// failFastWriter cancels the scan on the first finding blocking the CI, the findings so far are then reported
+type failFastWriter struct {
+	once        sync.Once
+	maxSeverity string
+	cancel      context.CancelFunc
+}
+
+func (w *failFastWriter) Write(output core.Output) error {
+	if output.Advisory || !core.SeverityReached(w.maxSeverity, output.Severity) {
+		return nil
+	}
+	w.once.Do(func() {
+		log.Warn("Stopping the scan on a finding over max severity: ", output.Name, " - ", output.URL)
+		w.cancel()
+	})
+	return nil
+}
+
+// notifyWebhook posts the summary of the scan to the webhook of the configuration, if any.
+// A failed notification is only a warning, the result of the scan does not depend on it.
+func notifyWebhook(cmd *cobra.Command, config *core.Config, summary *core.Summary, result []core.Output) {
+	if config.Webhook == "" {
+		return
+	}
+	if err := export.PostWebhook(cmd.Context(), config.Webhook, summary, result); err != nil {
+		log.Warn(err)
+	}
+}
+
+// exportPath places the export filename, by default the timestamped name of the run, in the output directory
+func exportPath(outputDir string, filename string) string {
+	if outputDir == "" {
+		return filename
+	}
+	return filepath.Join(outputDir, filename)
+}
+
+// createExportDir creates the directory of the export files when they are exported, if missing
+func createExportDir(config *core.Config) error {
+	if len(config.ExportFormats) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(config.ExportFilename), 0755); err != nil {
+		return fmt.Errorf("could not create the directory of the export files: %v", err)
+	}
+	return nil
+}
+
+// exportResults writes the results in each export format of the configuration and returns the exported files
+func exportResults(config *core.Config, result []core.Output, metadata core.Metadata) []string {
+	var exportFiles []string
+	if err := createExportDir(config); err != nil {
+		log.Error(err)
+		return nil
+	}
+	for _, e := range []struct {
+		format string
+		file   string
+		export func() error
+	}{
+		{"json", "%s.json", func() error { return export.ExportJSON(config.ExportFilename, result, metadata) }},
+		{"csv", "%s.csv", func() error { return export.ExportCSV(config.ExportFilename, result, config.Columns) }},
+		{"defectdojo", "%s.defectdojo.json", func() error { return export.ExportDefectDojo(config.ExportFilename, result) }},
+		{"markdown", "%s.md", func() error { return export.ExportMarkdown(config.ExportFilename, result) }},
+		{"asff", "%s.asff.json", func() error { return export.ExportASFF(config.ExportFilename, result, config.ASFF) }},
+		{"sarif", "%s.sarif", func() error { return export.ExportSARIF(config.ExportFilename, result) }},
+		{"html", "%s.html", func() error { return export.ExportHTML(config.ExportFilename, result) }},
+		{"junit", "%s.junit.xml", func() error { return export.ExportJUnit(config.ExportFilename, result) }},
+	} {
+		if !contains(config.ExportFormats, e.format) {
+			continue
+		}
+		// only the files actually written are returned, so --on-complete never gets a missing one
+		if err := e.export(); err != nil {
+			log.Error(fmt.Sprintf("Could not write the %s export: %v", e.format, err))
+			continue
+		}
+		exportFiles = append(exportFiles, fmt.Sprintf(e.file, config.ExportFilename))
+	}
+	return exportFiles
+}
+
+// defaultTimingColumns are the columns of the table with --show-timing when none is selected
+var defaultTimingColumns = []string{"url", "endpoint", "severity", "plugin", "remediation", "duration"}
+
+// tableColumns returns the columns of the results table, with the response time when --show-timing is set
+func tableColumns(config *core.Config) []string {
+	if !config.ShowTiming || contains(config.Columns, "duration") {
+		return config.Columns
+	}
+	if len(config.Columns) == 0 {
+		return defaultTimingColumns
+	}
+	return append(append([]string(nil), config.Columns...), "duration")
+}
+
+// openExportWriters creates the export files the findings are streamed to: ndjson always, csv and json in low memory mode
+func openExportWriters(config *core.Config, metadata func() core.Metadata) ([]export.FileWriter, error) {
+	var fileWriters []export.FileWriter
+	if err := createExportDir(config); err != nil {
+		return nil, err
+	}
+	if contains(config.ExportFormats, "ndjson") {
+		w, err := export.NewNDJSONFileWriter(config.ExportFilename)
+		if err != nil {
+			return nil, err
+		}
+		fileWriters = append(fileWriters, w)
+	}
+	if !config.LowMemory {
+		return fileWriters, nil
+	}
+	if contains(config.ExportFormats, "json") {
+		w, err := export.NewJSONFileWriter(config.ExportFilename, metadata)
+		if err != nil {
+			return nil, err
+		}
+		fileWriters = append(fileWriters, w)
+	}
+	if contains(config.ExportFormats, "csv") {
+		w, err := export.NewCSVFileWriter(config.ExportFilename, config.Columns)
+		if err != nil {
+			return nil, err
+		}
+		fileWriters = append(fileWriters, w)
+	}
+	return fileWriters, nil
+}
+
+// closeExportWriters completes the streamed export files and returns their names
+func closeExportWriters(fileWriters []export.FileWriter) []string {
+	var exportFiles []string
+	for _, w := range fileWriters {
+		if err := w.Close(); err != nil {
+			log.Error(err)
+			continue
+		}
+		log.Info("Results were exported in: ", w.Filename())
+		exportFiles = append(exportFiles, w.Filename())
+	}
+	return exportFiles
+}
+
 func parseConfig(cmd *cobra.Command, args []string) (*core.Config, error) {
 
 	urlFile, err := cmd.Flags().GetString("url-file")
 	if err != nil {
 		return nil, fmt.Errorf("invalid value for url-file: %v", err)
 	}
+	stdin, err := cmd.Flags().GetBool("stdin")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for stdin: %v", err)
+	}
+	if stdin {
+		if urlFile != "" && urlFile != "-" {
+			return nil, fmt.Errorf("Can't read the urls from both stdin and %s", urlFile)
+		}
+		urlFile = "-"
+	}
+
+	if proxyPassFile, _ := rootCmd.Flags().GetString("proxy-pass-file"); urlFile == "-" && proxyPassFile == "-" {
+		return nil, fmt.Errorf("Can't read both the urls and the proxy password from stdin")
+	}
 
 	if urlFile != "" && len(args) >= 1 {
 		// both urlFile and url are set, abort
@@ -99,23 +485,117 @@ func parseConfig(cmd *cobra.Command, args []string) (*core.Config, error) {
 		return nil, fmt.Errorf("No url provided, please set the input-file flag or provide an url as an argument")
 	}
 
+	basePath, err := cmd.Flags().GetString("base-path")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for base-path: %v", err)
+	}
+
+	riskScore, err := cmd.Flags().GetBool("risk-score")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for risk-score: %v", err)
+	}
+	riskWeightPairs, err := cmd.Flags().GetStringSlice("risk-weights")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for risk-weights: %v", err)
+	}
+	riskWeights, err := core.ParseRiskWeights(riskWeightPairs)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit, err := cmd.Flags().GetFloat64("rate-limit")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for rate-limit: %v", err)
+	}
+	if rateLimit < 0 {
+		return nil, fmt.Errorf("The rate limit must be a positive number of requests per second")
+	}
+	maxPerHost, err := cmd.Flags().GetInt("max-per-host")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for max-per-host: %v", err)
+	}
+	if maxPerHost < 0 {
+		return nil, fmt.Errorf("The maximum of requests per host must be positive")
+	}
+
+	rateLimitPairs, err := cmd.Flags().GetStringSlice("rate-limits")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for rate-limits: %v", err)
+	}
+	rateLimits, err := core.ParseRateLimits(rateLimitPairs)
+	if err != nil {
+		return nil, err
+	}
+
+	onComplete, err := cmd.Flags().GetString("on-complete")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for on-complete: %v", err)
+	}
+
+	webhook, err := cmd.Flags().GetString("webhook")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for webhook: %v", err)
+	}
+	if webhook != "" {
+		// the url is not part of the error, it usually embeds the secret of the webhook
+		u, err := url.Parse(webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("Invalid webhook url. Please use an http or https url")
+		}
+	}
+
+	baseline, err := cmd.Flags().GetString("baseline")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for baseline: %v", err)
+	}
+	failOnNew, err := cmd.Flags().GetBool("fail-on-new")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for fail-on-new: %v", err)
+	}
+	if failOnNew && baseline == "" {
+		return nil, fmt.Errorf("fail-on-new needs a baseline to compare the findings to")
+	}
+
+	validateOnly, err := cmd.Flags().GetBool("validate-only")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for validate-only: %v", err)
+	}
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for dry-run: %v", err)
+	}
+
+	cidrScheme, err := cmd.Flags().GetString("cidr-scheme")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for cidr-scheme: %v", err)
+	}
+	if cidrScheme != "http" && cidrScheme != "https" {
+		return nil, fmt.Errorf("Invalid cidr scheme : %s. Please use : http, https", cidrScheme)
+	}
+	maxCIDRHosts, err := cmd.Flags().GetInt("max-cidr-hosts")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for max-cidr-hosts: %v", err)
+	}
+	if maxCIDRHosts <= 0 {
+		return nil, fmt.Errorf("The maximum of hosts of a CIDR range must be positive")
+	}
+	targets := targetOptions{scheme: cidrScheme, maxHosts: maxCIDRHosts, validateOnly: validateOnly, insecure: make(map[string]bool)}
+
 	var urls []string
-	if urlFile != "" {
+	urlSource := urlFile
+	if urlFile == "-" {
+		urlSource = "stdin"
+		if urls, err = readURLs(os.Stdin, targets); err != nil {
+			return nil, err
+		}
+	} else if urlFile != "" {
 		content, err := os.Open(urlFile)
 		if err != nil {
 			return nil, err
 		}
 		defer content.Close()
-		scanner := bufio.NewScanner(content)
-		for scanner.Scan() {
-			url := scanner.Text()
-			if !isURL(url) {
-				log.Warn("url: ", url, " - is not valid - skipping scan")
-				continue
-			}
-			urls = append(urls, url)
-		}
-		if err := scanner.Err(); err != nil {
+		if urls, err = readURLs(content, targets); err != nil {
 			return nil, err
 		}
 	}
@@ -125,12 +605,22 @@ func parseConfig(cmd *cobra.Command, args []string) (*core.Config, error) {
 	}
 
 	if len(args) == 1 {
-		url := args[0]
-		if isURL(url) {
-			urls = append(urls, url)
-		} else {
+		hosts, ok, err := expandTarget(args[0], targets)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
 			return nil, fmt.Errorf("Please provide a valid URL")
 		}
+		urls = append(urls, hosts...)
+	}
+
+	allowEmpty, err := cmd.Flags().GetBool("allow-empty")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for allow-empty: %v", err)
+	}
+	if len(urls) == 0 && !allowEmpty {
+		return nil, fmt.Errorf("No valid url loaded from %s, use --allow-empty to scan anyway", urlSource)
 	}
 
 	insecure, err := cmd.Flags().GetBool("insecure")
@@ -147,11 +637,35 @@ func parseConfig(cmd *cobra.Command, args []string) (*core.Config, error) {
 			return nil, fmt.Errorf("Invalid severity level : %s. Please use : %s", severityFilter, core.SeveritiesAsString())
 		}
 	}
+	minSeverity, err := cmd.Flags().GetString("min-severity")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for min-severity: %v", err)
+	}
+	if minSeverity != "" {
+		if !core.ValidSeverity(minSeverity) {
+			return nil, fmt.Errorf("Invalid severity level : %s. Please use : %s", minSeverity, core.SeveritiesAsString())
+		}
+		if severityFilter != "" {
+			return nil, fmt.Errorf("Can't specify both severity-filter and min-severity")
+		}
+	}
 
 	pluginFilters, err := cmd.Flags().GetStringSlice("plugin-filters")
 	if err != nil {
 		return nil, fmt.Errorf("invalid value for plugin-filters: %v", err)
 	}
+	tags, err := cmd.Flags().GetStringSlice("tags")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for tags: %v", err)
+	}
+	excludeTags, err := cmd.Flags().GetStringSlice("exclude-tags")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for exclude-tags: %v", err)
+	}
+	strictCategories, err := cmd.Flags().GetBool("strict-categories")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for strict-categories: %v", err)
+	}
 
 	exportFormats, err := cmd.Flags().GetStringSlice("export")
 	if err != nil {
@@ -159,18 +673,47 @@ func parseConfig(cmd *cobra.Command, args []string) (*core.Config, error) {
 	}
 	if len(exportFormats) > 0 {
 		for _, f := range exportFormats {
-			if f != "csv" && f != "json" {
-				return nil, fmt.Errorf("invalid value for export: %v , expected csv or json", f)
+			if !contains(validExportFormats, f) {
+				return nil, fmt.Errorf("invalid value for export: %v , expected %s", f, strings.Join(validExportFormats, ", "))
 			}
 		}
 	}
 
-	maxSeverity, err := cmd.Flags().GetString("max-severity")
+	columns, err := cmd.Flags().GetStringSlice("columns")
 	if err != nil {
-		return nil, fmt.Errorf("invalid value for max sevirity : %v", err)
+		return nil, fmt.Errorf("invalid value for columns: %v", err)
 	}
-	if maxSeverity != "" && !core.ValidSeverity(maxSeverity) {
-		return nil, fmt.Errorf("Invalid max severity level : %s. Please use : %s", maxSeverity, core.SeveritiesAsString())
+	for _, column := range columns {
+		if !core.ValidColumn(column) {
+			return nil, fmt.Errorf("invalid value for columns: %v , expected %s", column, core.ColumnsAsString())
+		}
+	}
+
+	showTiming, err := cmd.Flags().GetBool("show-timing")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for show-timing: %v", err)
+	}
+
+	thresholds, err := parseThresholds(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	failFast, err := cmd.Flags().GetBool("fail-fast")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for fail-fast: %v", err)
+	}
+	if failFast && thresholds.maxSeverity == "" {
+		return nil, fmt.Errorf("fail-fast needs a max-severity to stop at")
+	}
+	if failFast && failOnNew {
+		// whether a finding is new is only known once the scan is over
+		return nil, fmt.Errorf("Can't specify fail-fast and fail-on-new")
+	}
+
+	stream, err := cmd.Flags().GetBool("stream")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for stream: %v", err)
 	}
 
 	exportFilename, err := cmd.Flags().GetString("export-filename")
@@ -181,12 +724,111 @@ func parseConfig(cmd *cobra.Command, args []string) (*core.Config, error) {
 		now := time.Now().Format("2006-01-02_15-04-05")
 		exportFilename = fmt.Sprintf("gochopchop_%s", now)
 	}
+	outputDir, err := cmd.Flags().GetString("output-dir")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for output-dir: %v", err)
+	}
+	exportFilename = exportPath(outputDir, exportFilename)
+
+	asff, err := parseASFFConfig(cmd, contains(exportFormats, "asff"))
+	if err != nil {
+		return nil, err
+	}
+
+	adaptive, err := cmd.Flags().GetBool("adaptive")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for adaptive: %v", err)
+	}
+
+	var progress time.Duration
+	progressEnabled, err := cmd.Flags().GetBool("progress")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for progress: %v", err)
+	}
+	progressInterval, err := cmd.Flags().GetInt("progress-interval")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for progress-interval: %v", err)
+	}
+	if progressEnabled {
+		if progressInterval <= 0 {
+			return nil, fmt.Errorf("The progress interval must be positive")
+		}
+		progress = time.Duration(progressInterval) * time.Second
+	}
+
+	captureEvidence, err := cmd.Flags().GetBool("capture-evidence")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for capture-evidence: %v", err)
+	}
+
+	detectBlocking, err := cmd.Flags().GetBool("detect-blocking")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for detect-blocking: %v", err)
+	}
+
+	lowMemory, err := cmd.Flags().GetBool("low-memory")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for low-memory: %v", err)
+	}
+	if lowMemory {
+		for _, f := range exportFormats {
+			if f != "csv" && f != "json" && f != "ndjson" {
+				return nil, fmt.Errorf("The %s export can't be streamed, only csv, json and ndjson are available with low-memory", f)
+			}
+		}
+		if riskScore {
+			return nil, fmt.Errorf("risk-score can't be computed with low-memory")
+		}
+		if baseline != "" {
+			return nil, fmt.Errorf("the findings can't be compared to a baseline with low-memory")
+		}
+	}
+
+	noFindings, err := cmd.Flags().GetString("no-findings")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for no-findings: %v", err)
+	}
+	if !contains(validNoFindings, noFindings) {
+		return nil, fmt.Errorf("invalid value for no-findings: %v , expected %s", noFindings, strings.Join(validNoFindings, ", "))
+	}
+	requestedURLsFile, err := cmd.Flags().GetString("requested-urls-file")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for requested-urls-file: %v", err)
+	}
+	resumeFile, err := cmd.Flags().GetString("resume-file")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for resume-file: %v", err)
+	}
+
+	tableLimit, err := cmd.Flags().GetInt("table-limit")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for table-limit: %v", err)
+	}
+	if tableLimit < 0 {
+		return nil, fmt.Errorf("The table limit must be positive or 0")
+	}
 
 	timeout, err := cmd.Flags().GetInt("timeout")
 	if err != nil {
 		return nil, fmt.Errorf("Invalid value for timeout: %v", err)
 	}
 
+	maxRedirects, err := cmd.Flags().GetInt("max-redirects")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for max-redirects: %v", err)
+	}
+	if maxRedirects <= 0 {
+		return nil, fmt.Errorf("The maximum of redirects must be positive")
+	}
+
+	httpVersion, err := cmd.Flags().GetString("http-version")
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for http-version: %v", err)
+	}
+	if httpVersion != "auto" && httpVersion != "1.1" && httpVersion != "2" {
+		return nil, fmt.Errorf("Invalid http version : %s. Please use : auto, 1.1, 2", httpVersion)
+	}
+
 	threads, err := rootCmd.Flags().GetInt("threads")
 	if err != nil {
 		return nil, fmt.Errorf("invalid value for threads: %w", err)
@@ -196,23 +838,216 @@ func parseConfig(cmd *cobra.Command, args []string) (*core.Config, error) {
 		return nil, fmt.Errorf("The number of threads must be positive")
 	}
 
+	proxyUser, proxyPass, err := parseProxyCredentials(rootCmd.Flags())
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := parseProxy()
+	if err != nil {
+		return nil, err
+	}
+	authorization, err := parseAuthorization(cmd)
+	if err != nil {
+		return nil, err
+	}
+	userAgent, err := parseUserAgent()
+	if err != nil {
+		return nil, err
+	}
+	resolver, dnsCacheTTL, err := parseResolver()
+	if err != nil {
+		return nil, err
+	}
+
 	config := &core.Config{
 		HTTP: core.HTTPConfig{
-			Insecure: insecure,
-			Timeout:  timeout,
+			Insecure:      insecure,
+			Timeout:       timeout,
+			ProxyUser:     proxyUser,
+			ProxyPass:     proxyPass,
+			Proxy:         proxy,
+			Authorization: authorization,
+			UserAgent:     userAgent,
+			MaxRedirects:  maxRedirects,
+			HTTPVersion:   httpVersion,
+			Resolver:      resolver,
+			DNSCacheTTL:   dnsCacheTTL,
 		},
-		MaxSeverity:    maxSeverity,
-		ExportFormats:  exportFormats,
-		Urls:           urls,
-		ExportFilename: exportFilename,
-		SeverityFilter: severityFilter,
-		PluginFilter:   pluginFilters,
-		Threads:        threads,
+		MaxSeverity:        thresholds.maxSeverity,
+		WarnSeverity:       thresholds.warnSeverity,
+		ExportFormats:      exportFormats,
+		Stream:             stream,
+		Urls:               urls,
+		ExportFilename:     exportFilename,
+		SeverityFilter:     severityFilter,
+		MinSeverity:        minSeverity,
+		PluginFilter:       pluginFilters,
+		Tags:               tags,
+		ExcludeTags:        excludeTags,
+		StrictCategories:   strictCategories,
+		Threads:            threads,
+		ValidateOnly:       validateOnly,
+		DryRun:             dryRun,
+		OnComplete:         onComplete,
+		Webhook:            webhook,
+		Baseline:           baseline,
+		FailOnNew:          failOnNew,
+		FailFast:           failFast,
+		BasePath:           basePath,
+		RiskScore:          riskScore,
+		RiskWeights:        riskWeights,
+		TableLimit:         tableLimit,
+		AllowEmpty:         allowEmpty,
+		Columns:            columns,
+		ShowTiming:         showTiming,
+		RequestedURLsFile:  requestedURLsFile,
+		ResumeFile:         resumeFile,
+		RateLimits:         rateLimits,
+		RateLimit:          rateLimit,
+		MaxPerHost:         maxPerHost,
+		NoFindings:         noFindings,
+		NoFindingsExitCode: thresholds.noFindingsExitCode,
+		FindingsExitCode:   thresholds.findingsExitCode,
+		BlockExitCode:      thresholds.blockExitCode,
+		LowMemory:          lowMemory,
+		ASFF:               asff,
+		Adaptive:           adaptive,
+		Progress:           progress,
+		InsecureTargets:    targets.insecure,
+		CaptureEvidence:    captureEvidence,
+		DetectBlocking:     detectBlocking,
 	}
 
 	return config, nil
 }
 
+// parseASFFConfig reads the AWS account the findings of the asff export are imported in
+func parseASFFConfig(cmd *cobra.Command, required bool) (core.ASFFConfig, error) {
+	var config core.ASFFConfig
+	var err error
+	if config.AccountID, err = cmd.Flags().GetString("asff-account-id"); err != nil {
+		return config, fmt.Errorf("invalid value for asff-account-id: %v", err)
+	}
+	if config.Region, err = cmd.Flags().GetString("asff-region"); err != nil {
+		return config, fmt.Errorf("invalid value for asff-region: %v", err)
+	}
+	if config.ProductArn, err = cmd.Flags().GetString("asff-product-arn"); err != nil {
+		return config, fmt.Errorf("invalid value for asff-product-arn: %v", err)
+	}
+	if !required {
+		return config, nil
+	}
+	if len(config.AccountID) != 12 || strings.Trim(config.AccountID, "0123456789") != "" {
+		return config, fmt.Errorf("Invalid asff account id : %q. The asff export needs the 12 digits --asff-account-id", config.AccountID)
+	}
+	if config.Region == "" && config.ProductArn == "" {
+		return config, fmt.Errorf("The asff export needs --asff-region or --asff-product-arn")
+	}
+	return config, nil
+}
+
+// parseAuthorization returns the Authorization header of the --basic-auth or --bearer-token flags.
+// The credentials are not part of the errors so they never end up in the logs.
+func parseAuthorization(cmd *cobra.Command) (string, error) {
+	basicAuth, err := cmd.Flags().GetString("basic-auth")
+	if err != nil {
+		return "", fmt.Errorf("invalid value for basic-auth")
+	}
+	bearerToken, err := cmd.Flags().GetString("bearer-token")
+	if err != nil {
+		return "", fmt.Errorf("invalid value for bearer-token")
+	}
+	switch {
+	case basicAuth != "" && bearerToken != "":
+		return "", fmt.Errorf("Can't specify both basic-auth and bearer-token")
+	case basicAuth != "":
+		parts := strings.SplitN(basicAuth, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return "", fmt.Errorf("Invalid basic-auth format. Format should be user:password")
+		}
+		credential := core.Credential{User: parts[0], Password: parts[1]}
+		return credential.BasicAuthorization(), nil
+	case bearerToken != "":
+		return "Bearer " + bearerToken, nil
+	}
+	return "", nil
+}
+
+// printThresholdSummary distinguishes the findings that are only reported from the ones failing the scan
+// printTargets prints the urls each plugin would request, one per line
+func printTargets(config *core.Config, signatures *core.Signatures) {
+	for _, url := range config.Urls {
+		for _, plugin := range signatures.Plugins {
+			for _, target := range plugin.Targets(url, config.BasePath) {
+				fmt.Fprintln(os.Stdout, target.URL)
+			}
+		}
+	}
+}
+
+// targetOptions tell how the lines of an url file are read
+type targetOptions struct {
+	// scheme of the hosts of the IP addresses and CIDR ranges without one
+	scheme string
+	// maxHosts a CIDR range can be expanded into
+	maxHosts     int
+	validateOnly bool
+	// insecure receives the urls of the insecure:// targets
+	insecure map[string]bool
+}
+
+// expandTarget expands an entry of the url file or the command line into the urls to scan, and records the insecure ones.
+// ok is false when the entry is neither an url, an IP address nor a CIDR range.
+func expandTarget(entry string, targets targetOptions) ([]string, bool, error) {
+	entry, insecure := core.ParseInsecureTarget(entry)
+	urls, ok, err := core.ExpandHosts(entry, targets.scheme, targets.maxHosts)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		if !isURL(entry) {
+			return nil, false, nil
+		}
+		urls = []string{entry}
+	}
+	if insecure {
+		for _, u := range urls {
+			targets.insecure[u] = true
+		}
+	}
+	return urls, true, nil
+}
+
+// readURLs reads one url per line, skipping the blank lines and the # comments.
+// The IP addresses and CIDR ranges are expanded into the urls of their hosts.
+// The invalid urls are skipped, or rejected when only validating the configuration.
+func readURLs(r io.Reader, targets targetOptions) ([]string, error) {
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		url := strings.TrimSpace(scanner.Text())
+		if url == "" || strings.HasPrefix(url, "#") {
+			continue
+		}
+		hosts, ok, err := expandTarget(url, targets)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if targets.validateOnly {
+				return nil, fmt.Errorf("url: %s - is not valid", url)
+			}
+			log.Warn("url: ", url, " - is not valid - skipping scan")
+			continue
+		}
+		urls = append(urls, hosts...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
 func isURL(str string) bool {
 	u, err := url.Parse(str)
 	return err == nil && u.Scheme != "" && u.Host != ""
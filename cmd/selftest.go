@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"gochopchop/chopchop"
+	"os"
+
+	"github.com/jedib0t/go-pretty/table"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	selftestCmd := &cobra.Command{
+		Use:   "selftest",
+		Short: "check that every check fires against a local server answering with the response it looks for",
+		RunE:  runSelfTest,
+	}
+	addSignaturesFlag(selftestCmd)
+	selftestCmd.Flags().BoolP("failed-only", "", false, "only print the checks which did not fire") // --failed-only
+
+	rootCmd.AddCommand(selftestCmd)
+}
+
+func runSelfTest(cmd *cobra.Command, args []string) error {
+	signatures, err := parseSignatures(cmd)
+	if err != nil {
+		return err
+	}
+	failedOnly, err := cmd.Flags().GetBool("failed-only")
+	if err != nil {
+		return fmt.Errorf("invalid value for failed-only: %v", err)
+	}
+
+	results, err := chopchop.SelfTest(cmd.Context(), signatures)
+	if err != nil {
+		return err
+	}
+
+	failed, skipped := 0, 0
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Check", "Result"})
+	for _, result := range results {
+		status := "OK"
+		switch {
+		case result.Skipped != "":
+			skipped++
+			status = "SKIPPED (" + result.Skipped + ")"
+		case !result.Fired:
+			failed++
+			status = "FAILED"
+		}
+		if failedOnly && status != "FAILED" {
+			continue
+		}
+		t.AppendRow(table.Row{result.Check, status})
+	}
+	t.AppendFooter(table.Row{"Total", fmt.Sprintf("%d ok, %d failed, %d skipped", len(results)-failed-skipped, failed, skipped)})
+	t.Render()
+
+	if skipped > 0 {
+		log.Info(skipped, " check(s) skipped, no response could be built from their conditions")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) did not fire against their own fixture", failed)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"gochopchop/core"
+	"os"
+
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/spf13/cobra"
+)
+
+type severityLevel struct {
+	Rank     int    `json:"rank"`
+	Severity string `json:"severity"`
+}
+
+func init() {
+	severitiesCmd := &cobra.Command{
+		Use:   "severities",
+		Short: "list the valid severities, from the most to the least critical",
+		RunE:  runSeverities,
+	}
+	severitiesCmd.Flags().BoolP("json", "", false, "print the severities as JSON") // --json
+
+	rootCmd.AddCommand(severitiesCmd)
+}
+
+func runSeverities(cmd *cobra.Command, args []string) error {
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("invalid value for json: %v", err)
+	}
+
+	levels := make([]severityLevel, 0)
+	for i, severity := range core.Severities() {
+		levels = append(levels, severityLevel{Rank: i + 1, Severity: severity})
+	}
+
+	if asJSON {
+		jsonbytes, err := json.Marshal(levels)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, string(jsonbytes))
+		return nil
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Rank", "Severity"})
+	for _, level := range levels {
+		t.AppendRow(table.Row{level.Rank, level.Severity})
+	}
+	t.Render()
+	return nil
+}
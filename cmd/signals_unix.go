@@ -0,0 +1,44 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"gochopchop/core"
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// notifyPause pauses the scan on SIGUSR1 and resumes it on SIGUSR2.
+// The returned function stops listening to the signals.
+func notifyPause(pauser *core.Pauser) func() {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for {
+			select {
+			case sig := <-sigs:
+				switch sig {
+				case syscall.SIGUSR1:
+					if pauser.Pause() {
+						log.Warn("Scan paused, the requests in flight are finishing. Send SIGUSR2 to resume")
+					}
+				case syscall.SIGUSR2:
+					if pauser.Resume() {
+						log.Warn("Scan resumed")
+					}
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build windows
+// +build windows
+
+package cmd
+
+import "gochopchop/core"
+
+// notifyPause is a no-op, SIGUSR1 and SIGUSR2 don't exist on Windows
+func notifyPause(pauser *core.Pauser) func() {
+	return func() {}
+}
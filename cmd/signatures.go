@@ -5,6 +5,7 @@ import (
 	"gochopchop/core"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -16,74 +17,128 @@ var signatureFlagShorthand = "c"
 var signatureDefaultFilename = "chopchop.yml"
 
 func addSignaturesFlag(cmd *cobra.Command) error {
-	cmd.Flags().StringP(signatureFlagName, signatureFlagShorthand, signatureDefaultFilename, "path to signature file") // --signature ou -c
+	cmd.Flags().StringSliceP(signatureFlagName, signatureFlagShorthand, []string{signatureDefaultFilename}, "paths or glob patterns (eg. ./signatures/*.yml) of the signature files, repeatable") // --signature ou -c
+	cmd.Flags().BoolP("strict-categories", "", false, "only accept the known plugin categories")                                                                                                  // --strict-categories
 	return nil
 }
 
+// parseSignatures loads the signatures, keeps the checks selected by the filter flags and validates them
 func parseSignatures(cmd *cobra.Command) (*core.Signatures, error) {
-
-	signatureFile, err := cmd.Flags().GetString(signatureFlagName)
-	if err != nil {
-		return nil, fmt.Errorf("Invalid value for signatureFile: %v", err)
-	}
-	if _, err := os.Stat(signatureFile); os.IsNotExist(err) {
-		return nil, fmt.Errorf("Path of signatures file is not valid")
-	}
-
-	file, err := os.Open(signatureFile)
+	signatures, err := loadSignatures(cmd)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	signatureData, err := ioutil.ReadAll(file)
-	if err != nil {
-		return nil, err
-	}
-
-	signatures := core.NewSignatures()
-
-	err = yaml.Unmarshal([]byte(signatureData), signatures)
-	if err != nil {
-		return nil, err
+	// the disabled checks are only listed on demand, they are never run
+	showDisabled, _ := cmd.Flags().GetBool("show-disabled")
+	if !showDisabled {
+		signatures.FilterDisabled()
 	}
-
 	severityFilter, _ := cmd.Flags().GetString("severity-filter")
 	if severityFilter != "" {
 		signatures.FilterBySeverity(severityFilter)
 	}
+	minSeverity, _ := cmd.Flags().GetString("min-severity")
+	if minSeverity != "" {
+		signatures.FilterByMinSeverity(minSeverity)
+	}
 
 	pluginFilters, _ := cmd.Flags().GetStringSlice("plugin-filters")
 	if len(pluginFilters) > 0 {
 		signatures.FilterByNames(pluginFilters)
 	}
+	tags, _ := cmd.Flags().GetStringSlice("tags")
+	if len(tags) > 0 {
+		signatures.FilterByTags(tags)
+	}
+	excludeTags, _ := cmd.Flags().GetStringSlice("exclude-tags")
+	if len(excludeTags) > 0 {
+		signatures.FilterByExcludedTags(excludeTags)
+	}
 
-	for _, plugin := range signatures.Plugins {
-		if plugin.Endpoint == "" {
-			if len(plugin.Endpoints) > 0 {
-				return nil, fmt.Errorf("URI and URIs can't be set at the same time in plugin checks. Stopping execution")
-			}
+	strictCategories, err := cmd.Flags().GetBool("strict-categories")
+	if err != nil {
+		return nil, fmt.Errorf("Invalid value for strict-categories: %v", err)
+	}
+	if err := signatures.Validate("", strictCategories); err != nil {
+		return nil, err
+	}
+	return signatures, nil
+}
+
+// loadSignatures reads and merges the signature files, without filtering nor validating them
+func loadSignatures(cmd *cobra.Command) (*core.Signatures, error) {
+	patterns, err := cmd.Flags().GetStringSlice(signatureFlagName)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid value for signatureFile: %v", err)
+	}
+	signatureFiles, err := expandSignatureFiles(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	// the plugins of every file are merged, each one remembering the directory of its file
+	// for the files it references (match_file, default_credentials)
+	signatures := core.NewSignatures()
+	checkFiles := make(map[string]string)
+	for _, signatureFile := range signatureFiles {
+		fileSignatures, err := readSignatureFile(signatureFile)
+		if err != nil {
+			return nil, err
 		}
-		for _, check := range plugin.Checks {
-			if check.Description == "" {
-				return nil, fmt.Errorf("Missing or empty description field in %s plugin checks. Stopping execution", check.Name)
-			}
-			if check.Remediation == "" {
-				return nil, fmt.Errorf("Missing or empty remediation field in %s plugin checks. Stopping execution", check.Name)
+		for _, plugin := range fileSignatures.Plugins {
+			for _, check := range plugin.Checks {
+				if file, ok := checkFiles[check.Name]; ok && file != signatureFile {
+					return nil, fmt.Errorf("Duplicate check %s in %s and %s. Stopping execution", check.Name, file, signatureFile)
+				}
+				checkFiles[check.Name] = signatureFile
 			}
-			if check.Severity == "" {
-				return nil, fmt.Errorf("Missing severity field in %s plugin checks. Stopping execution", check.Name)
+			plugin.Dir = filepath.Dir(signatureFile)
+		}
+		signatures.Plugins = append(signatures.Plugins, fileSignatures.Plugins...)
+	}
+	signatures.Files = signatureFiles
+	return signatures, nil
+}
+
+// expandSignatureFiles resolves the glob patterns of the signature files, each file being kept once
+func expandSignatureFiles(patterns []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, fmt.Errorf("Invalid signatures pattern : %s. %v", pattern, err)
 			}
-			if !core.ValidSeverity(check.Severity) {
-				return nil, fmt.Errorf("Invalid severity : %s. Please use : %s", check.Severity, core.SeveritiesAsString())
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("No signatures file matches %s", pattern)
 			}
-			for _, header := range check.Headers {
-				if len(strings.Split(header, ":")) < 2 {
-					return nil, fmt.Errorf("Invalid header format : %s. Format should be KEY:VALUE", header)
-				}
+		} else if _, err := os.Stat(pattern); os.IsNotExist(err) {
+			return nil, fmt.Errorf("Path of signatures file is not valid : %s", pattern)
+		}
+		for _, match := range matches {
+			if !seen[filepath.Clean(match)] {
+				seen[filepath.Clean(match)] = true
+				files = append(files, match)
 			}
 		}
 	}
+	return files, nil
+}
 
+func readSignatureFile(signatureFile string) (*core.Signatures, error) {
+	signatureData, err := ioutil.ReadFile(signatureFile)
+	if err != nil {
+		return nil, err
+	}
+	signatures := core.NewSignatures()
+	if err := yaml.Unmarshal(signatureData, signatures); err != nil {
+		return nil, fmt.Errorf("Invalid signatures file %s: %v", signatureFile, err)
+	}
+	if err := signatures.Interpolate(os.LookupEnv); err != nil {
+		return nil, err
+	}
 	return signatures, nil
 }
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"gochopchop/core"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// thresholds are the severities and the exit codes deciding how the scan and monitor commands end
+type thresholds struct {
+	maxSeverity        string
+	warnSeverity       string
+	noFindingsExitCode int
+	findingsExitCode   int
+	blockExitCode      int
+}
+
+// addThresholdFlags registers the flags of the thresholds, maxSeverity being the default of --max-severity
+func addThresholdFlags(cmd *cobra.Command, maxSeverity string) {
+	cmd.Flags().StringP("max-severity", "b", maxSeverity, "block the CI pipeline if severity is over or equal specified flag")     // --max-severity ou -m
+	cmd.Flags().StringP("fail-severity", "", "", "alias of --max-severity")                                                        // --fail-severity
+	cmd.Flags().StringP("warn-severity", "", "", "print a warning for each finding over or equal this severity, without failing")  // --warn-severity
+	cmd.Flags().IntP("no-findings-exit-code", "", 0, "exit code when nothing is found")                                            // --no-findings-exit-code
+	cmd.Flags().IntP("exit-code-on-findings", "", 0, "exit code when something is found without reaching --max-severity")          // --exit-code-on-findings
+	cmd.Flags().IntP("exit-code-on-block", "", 1, "exit code when --max-severity is reached, or a new finding with --fail-on-new") // --exit-code-on-block
+}
+
+func parseThresholds(cmd *cobra.Command) (thresholds, error) {
+	var t thresholds
+	maxSeverity, err := cmd.Flags().GetString("max-severity")
+	if err != nil {
+		return t, fmt.Errorf("invalid value for max sevirity : %v", err)
+	}
+	failSeverity, err := cmd.Flags().GetString("fail-severity")
+	if err != nil {
+		return t, fmt.Errorf("invalid value for fail severity : %v", err)
+	}
+	if failSeverity != "" {
+		if cmd.Flags().Changed("max-severity") && maxSeverity != failSeverity {
+			return t, fmt.Errorf("Can't specify different max-severity and fail-severity")
+		}
+		maxSeverity = failSeverity
+	}
+	if maxSeverity != "" && !core.ValidSeverity(maxSeverity) {
+		return t, fmt.Errorf("Invalid max severity level : %s. Please use : %s", maxSeverity, core.SeveritiesAsString())
+	}
+	t.maxSeverity = maxSeverity
+
+	warnSeverity, err := cmd.Flags().GetString("warn-severity")
+	if err != nil {
+		return t, fmt.Errorf("invalid value for warn severity : %v", err)
+	}
+	if warnSeverity != "" && !core.ValidSeverity(warnSeverity) {
+		return t, fmt.Errorf("Invalid warn severity level : %s. Please use : %s", warnSeverity, core.SeveritiesAsString())
+	}
+	t.warnSeverity = warnSeverity
+
+	noFindingsExitCode, err := cmd.Flags().GetInt("no-findings-exit-code")
+	if err != nil {
+		return t, fmt.Errorf("invalid value for no-findings-exit-code: %v", err)
+	}
+	if noFindingsExitCode < 0 || noFindingsExitCode > 125 {
+		return t, fmt.Errorf("The exit code must be between 0 and 125")
+	}
+	t.noFindingsExitCode = noFindingsExitCode
+	findingsExitCode, err := cmd.Flags().GetInt("exit-code-on-findings")
+	if err != nil {
+		return t, fmt.Errorf("invalid value for exit-code-on-findings: %v", err)
+	}
+	if findingsExitCode < 0 || findingsExitCode > 125 {
+		return t, fmt.Errorf("The exit code must be between 0 and 125")
+	}
+	t.findingsExitCode = findingsExitCode
+	// a blocking scan must fail
+	blockExitCode, err := cmd.Flags().GetInt("exit-code-on-block")
+	if err != nil {
+		return t, fmt.Errorf("invalid value for exit-code-on-block: %v", err)
+	}
+	if blockExitCode < 1 || blockExitCode > 125 {
+		return t, fmt.Errorf("The exit code on block must be between 1 and 125")
+	}
+	t.blockExitCode = blockExitCode
+	return t, nil
+}
+
+// thresholdsError warns about the findings over --warn-severity and returns the exitError of the findings, if any:
+// the block exit code when --max-severity is reached or when there is a new finding with --fail-on-new,
+// the exit code on findings otherwise
+func thresholdsError(cmd *cobra.Command, config *core.Config, summary *core.Summary, result []core.Output) error {
+	warnings, failures := 0, 0
+	if config.WarnSeverity != "" {
+		for _, output := range result {
+			if output.Status != core.StatusResolved && core.SeverityReached(config.WarnSeverity, output.Severity) {
+				log.Warn("Finding over warn severity: ", output.Name, " - ", output.URL)
+			}
+		}
+		warnings = summary.Reached(config.WarnSeverity)
+	}
+	if config.FailOnNew {
+		threshold := config.MaxSeverity
+		if threshold == "" {
+			threshold = "Informational"
+		}
+		failures = core.Summarize(core.NewFindings(result)).BlockingReached(threshold)
+	} else if config.MaxSeverity != "" {
+		failures = summary.BlockingReached(config.MaxSeverity)
+	}
+	if !quiet && (config.WarnSeverity != "" || config.MaxSeverity != "" || config.FailOnNew) {
+		printThresholdSummary(config, warnings, failures)
+	}
+	if failures > 0 && config.FailOnNew {
+		return &exitError{code: config.BlockExitCode, err: fmt.Errorf("New findings compared to the baseline, exiting with error code")}
+	}
+	if failures > 0 {
+		return &exitError{code: config.BlockExitCode, err: fmt.Errorf("Max severity level reached, exiting with error code")}
+	}
+	// the scan ran and found something, without blocking the CI
+	if config.FindingsExitCode != 0 && summary.Total > 0 {
+		cmd.SilenceErrors = true
+		return &exitError{code: config.FindingsExitCode, err: fmt.Errorf("Vulnerabilities found"), silent: true}
+	}
+	return nil
+}
+
+func printThresholdSummary(config *core.Config, warnings int, failures int) {
+	if config.WarnSeverity != "" {
+		fmt.Fprintf(os.Stdout, "WARN: %d finding(s) with a severity equal or over %s\n", warnings, config.WarnSeverity)
+	}
+	if config.FailOnNew && config.MaxSeverity != "" {
+		fmt.Fprintf(os.Stdout, "FAIL: %d new finding(s) with a severity equal or over %s\n", failures, config.MaxSeverity)
+	} else if config.FailOnNew {
+		fmt.Fprintf(os.Stdout, "FAIL: %d new finding(s)\n", failures)
+	} else if config.MaxSeverity != "" {
+		fmt.Fprintf(os.Stdout, "FAIL: %d finding(s) with a severity equal or over %s\n", failures, config.MaxSeverity)
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+	"gochopchop/core"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestThresholdsError(t *testing.T) {
+	deviations := []core.Output{
+		{URL: "http://foobar/admin", Name: "Unexpected status code", Severity: "Medium"},
+		{URL: "http://foobar/health", Name: "Unexpected status code", Severity: "Medium"},
+	}
+
+	var tests = map[string]struct {
+		config   core.Config
+		wantCode int
+	}{
+		"max severity reached":     {config: core.Config{MaxSeverity: "Informational", BlockExitCode: 1}, wantCode: 1},
+		"custom block exit code":   {config: core.Config{MaxSeverity: "Medium", BlockExitCode: 3}, wantCode: 3},
+		"max severity not reached": {config: core.Config{MaxSeverity: "High", BlockExitCode: 1}, wantCode: 0},
+		"exit code on findings":    {config: core.Config{MaxSeverity: "High", BlockExitCode: 1, FindingsExitCode: 2}, wantCode: 2},
+		"no threshold":             {config: core.Config{BlockExitCode: 1}, wantCode: 0},
+		"warn severity only":       {config: core.Config{WarnSeverity: "Low", BlockExitCode: 1}, wantCode: 0},
+	}
+	quiet = true
+	defer func() { quiet = false }()
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := thresholdsError(&cobra.Command{}, &tc.config, core.Summarize(deviations), deviations)
+			code := 0
+			var exitErr *exitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.code
+			} else if err != nil {
+				t.Fatalf("expected an exitError, got: %v", err)
+			}
+			if code != tc.wantCode {
+				t.Errorf("expected: %v, got: %v", tc.wantCode, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// AdaptiveWindow is the number of responses the error rate is computed on
+	AdaptiveWindow = 20
+	// AdaptiveErrorThreshold is the error rate above which the concurrency is halved
+	AdaptiveErrorThreshold = 0.2
+)
+
+// AdaptiveConcurrency caps the requests in flight, the cap being adjusted to the health of the targets:
+// it is halved when the error rate of the last responses rises above the threshold,
+// and increased by one when they are healthy (additive increase, multiplicative decrease).
+type AdaptiveConcurrency struct {
+	mux    sync.Mutex
+	min    int
+	max    int
+	limit  int
+	active int
+	total  int
+	errors int
+	// changed is closed when a request slot may have been freed
+	changed chan struct{}
+}
+
+// NewAdaptiveConcurrency returns a controller starting at the max concurrency
+func NewAdaptiveConcurrency(min int, max int) *AdaptiveConcurrency {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	return &AdaptiveConcurrency{min: min, max: max, limit: max, changed: make(chan struct{})}
+}
+
+// Limit returns the current number of requests allowed in flight
+func (a *AdaptiveConcurrency) Limit() int {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	return a.limit
+}
+
+// Acquire blocks until a request can be sent, or until the context is done.
+// A nil AdaptiveConcurrency never blocks.
+func (a *AdaptiveConcurrency) Acquire(ctx context.Context) error {
+	if a == nil {
+		return nil
+	}
+	for {
+		a.mux.Lock()
+		if a.active < a.limit {
+			a.active++
+			a.mux.Unlock()
+			return nil
+		}
+		changed := a.changed
+		a.mux.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release frees the slot of a request and records whether it failed,
+// adjusting the concurrency once a window of responses is complete
+func (a *AdaptiveConcurrency) Release(failed bool) {
+	if a == nil {
+		return
+	}
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	a.active--
+	a.total++
+	if failed {
+		a.errors++
+	}
+	if a.total >= AdaptiveWindow {
+		rate := float64(a.errors) / float64(a.total)
+		previous := a.limit
+		if rate > AdaptiveErrorThreshold {
+			a.limit = a.limit / 2
+			if a.limit < a.min {
+				a.limit = a.min
+			}
+		} else if a.limit < a.max {
+			a.limit++
+		}
+		if a.limit != previous {
+			log.WithFields(log.Fields{"from": previous, "to": a.limit, "error_rate": rate}).Info("Concurrency adjusted")
+		}
+		a.total, a.errors = 0, 0
+	}
+	close(a.changed)
+	a.changed = make(chan struct{})
+}
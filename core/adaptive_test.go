@@ -0,0 +1,63 @@
+package core_test
+
+import (
+	"context"
+	"gochopchop/core"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	var tests = map[string]struct {
+		start   int
+		windows []float64
+		want    int
+	}{
+		"errors halve the concurrency":    {windows: []float64{0.5}, want: 4},
+		"concurrency stays above the min": {windows: []float64{1, 1, 1, 1, 1}, want: 1},
+		"healthy targets increase it":     {windows: []float64{0.5, 0.5, 0, 0}, want: 4},
+		"concurrency stays below the max": {windows: []float64{0, 0}, want: 8},
+		"errors under the threshold":      {windows: []float64{0.1}, want: 8},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := core.NewAdaptiveConcurrency(1, 8)
+			for _, rate := range tc.windows {
+				failures := int(rate * core.AdaptiveWindow)
+				for i := 0; i < core.AdaptiveWindow; i++ {
+					_ = a.Acquire(context.Background())
+					a.Release(i < failures)
+				}
+			}
+			if got := a.Limit(); got != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAdaptiveConcurrencyAcquire(t *testing.T) {
+	a := core.NewAdaptiveConcurrency(1, 1)
+	if err := a.Acquire(context.Background()); err != nil {
+		t.Fatalf("expected: no error, got: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := a.Acquire(ctx); err == nil {
+		t.Fatalf("expected: Acquire to block while the slot is taken")
+	}
+
+	acquired := make(chan error)
+	go func() { acquired <- a.Acquire(context.Background()) }()
+	a.Release(false)
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Errorf("expected: no error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("expected: Acquire to return once the slot is released")
+	}
+}
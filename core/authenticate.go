@@ -0,0 +1,67 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// realmRegexp matches the realm parameter, not the parameters ending with realm such as subrealm
+var realmRegexp = regexp.MustCompile(`(?i)(?:^|[\s,])realm\s*=\s*(?:"([^"]*)"|([^\s,]*))`)
+
+// WWWAuthenticateCheck asserts that the response asks for a specific authentication scheme.
+// An empty field is not checked.
+type WWWAuthenticateCheck struct {
+	Scheme string `yaml:"scheme"`
+	Realm  string `yaml:"realm"`
+}
+
+// Challenge is an authentication challenge parsed from a WWW-Authenticate header
+type Challenge struct {
+	Scheme string
+	Realm  string
+}
+
+// ParseWWWAuthenticate returns the challenges found in the WWW-Authenticate headers
+func ParseWWWAuthenticate(header http.Header) []Challenge {
+	var challenges []Challenge
+	for _, value := range header.Values("WWW-Authenticate") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		challenge := Challenge{Scheme: strings.Fields(value)[0]}
+		if m := realmRegexp.FindStringSubmatch(value); m != nil {
+			challenge.Realm = m[1] + m[2]
+		}
+		challenges = append(challenges, challenge)
+	}
+	return challenges
+}
+
+// Match reports whether one of the challenges has the expected scheme and realm.
+// The scheme is compared case-insensitively, the realm must contain the expected one.
+// When it matches, the returned string describes the challenge.
+func (w *WWWAuthenticateCheck) Match(header http.Header) (bool, string) {
+	for _, challenge := range ParseWWWAuthenticate(header) {
+		if w.Scheme != "" && !strings.EqualFold(challenge.Scheme, w.Scheme) {
+			continue
+		}
+		if w.Realm != "" && !strings.Contains(challenge.Realm, w.Realm) {
+			continue
+		}
+		if challenge.Realm != "" {
+			return true, fmt.Sprintf("protected by %s authentication (realm %q)", challenge.Scheme, challenge.Realm)
+		}
+		return true, fmt.Sprintf("protected by %s authentication", challenge.Scheme)
+	}
+	return false, ""
+}
+
+func (w *WWWAuthenticateCheck) Equals(auth *WWWAuthenticateCheck) bool {
+	if w == nil || auth == nil {
+		return w == auth
+	}
+	return w.Scheme == auth.Scheme && w.Realm == auth.Realm
+}
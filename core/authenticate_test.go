@@ -0,0 +1,40 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"net/http"
+	"testing"
+)
+
+func TestWWWAuthenticateCheckMatch(t *testing.T) {
+	header := http.Header{
+		"Www-Authenticate": []string{
+			`Basic realm="Tomcat Manager Application"`,
+			`Bearer realm=api, error="invalid_token"`,
+			`Digest subrealm="internal", realm="portal",xrealm=other`,
+			`Negotiate xrealm="corp"`,
+		},
+	}
+	var tests = map[string]struct {
+		check *core.WWWAuthenticateCheck
+		want  bool
+	}{
+		"Scheme only":             {check: &core.WWWAuthenticateCheck{Scheme: "basic"}, want: true},
+		"Scheme and realm":        {check: &core.WWWAuthenticateCheck{Scheme: "Basic", Realm: "Tomcat"}, want: true},
+		"Unquoted realm":          {check: &core.WWWAuthenticateCheck{Scheme: "Bearer", Realm: "api"}, want: true},
+		"Realm of another scheme": {check: &core.WWWAuthenticateCheck{Scheme: "Basic", Realm: "api"}, want: false},
+		"Unknown scheme":          {check: &core.WWWAuthenticateCheck{Scheme: "NTLM"}, want: false},
+		"Realm after subrealm":    {check: &core.WWWAuthenticateCheck{Scheme: "Digest", Realm: "portal"}, want: true},
+		"Subrealm not the realm":  {check: &core.WWWAuthenticateCheck{Scheme: "Digest", Realm: "internal"}, want: false},
+		"Parameter ending realm":  {check: &core.WWWAuthenticateCheck{Scheme: "Negotiate", Realm: "corp"}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have, _ := tc.check.Match(header)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
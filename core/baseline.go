@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"gochopchop/internal"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// baselines caches the baseline body size of each scanned host, which is the size of the body
+// returned by the root of the host (the url followed by the base path and a /).
+// It is only requested when a check compares the body size to the baseline.
+type baselines struct {
+	mux   sync.Mutex
+	hosts map[string]*baseline
+}
+
+type baseline struct {
+	once sync.Once
+	size *int
+}
+
+// baselineSize returns the baseline body size of the host, nil when the root of the host could not be fetched
+func (s Scanner) baselineSize(ctx context.Context, host string) *int {
+	s.baselines.mux.Lock()
+	b, ok := s.baselines.hosts[host]
+	if !ok {
+		b = &baseline{}
+		s.baselines.hosts[host] = b
+	}
+	s.baselines.mux.Unlock()
+
+	b.once.Do(func() {
+		resp, err := s.fetchHostPath(ctx, host, "/")
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error("Could not compute the baseline of ", host, ": ", err)
+			}
+			return
+		}
+		size := len(resp.Body)
+		b.size = &size
+		log.Debug("Baseline of ", host, " is ", size, " bytes")
+	})
+	return b.size
+}
+
+// withBaseline attaches the baseline of the host to the response when a check of the plugin needs it
+func (s Scanner) withBaseline(ctx context.Context, job workerJob, resp *internal.HTTPResponse) {
+	for _, check := range job.plugin.Checks {
+		if check.SizeRatio != 0 {
+			resp.BaselineSize = s.baselineSize(ctx, job.host)
+			return
+		}
+	}
+}
+
+// sizeDeviation tells whether the body size deviates from the baseline of the host by at least the ratio,
+// whether it is larger or smaller. Sizes below 1 byte count as 1 byte so an empty baseline can be compared.
+func sizeDeviation(resp *internal.HTTPResponse, ratio float64) (bool, string) {
+	if resp.BaselineSize == nil {
+		return false, "no baseline for the host"
+	}
+	size := float64(len(resp.Body))
+	base := float64(*resp.BaselineSize)
+	deviation := maxFloat(size, 1) / maxFloat(base, 1)
+	detail := fmt.Sprintf("body is %d bytes, %.1fx the baseline of %d bytes", len(resp.Body), deviation, *resp.BaselineSize)
+	return deviation >= ratio || deviation <= 1/ratio, detail
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
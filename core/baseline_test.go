@@ -0,0 +1,66 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal"
+	"strings"
+	"testing"
+)
+
+type bodyFetcher map[string]string
+
+func (f bodyFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	body, ok := f[req.URL]
+	if !ok {
+		return nil, fmt.Errorf("connection refused")
+	}
+	return &internal.HTTPResponse{StatusCode: 200, Body: body}, nil
+}
+
+func TestCheckMatchSizeRatio(t *testing.T) {
+	size := func(n int) *int { return &n }
+	var tests = map[string]struct {
+		body     string
+		baseline *int
+		want     bool
+	}{
+		"larger than the ratio":  {body: strings.Repeat("a", 400), baseline: size(100), want: true},
+		"within the ratio":       {body: strings.Repeat("a", 200), baseline: size(100), want: false},
+		"smaller than the ratio": {body: strings.Repeat("a", 20), baseline: size(100), want: true},
+		"empty baseline":         {body: "error", baseline: size(0), want: true},
+		"no baseline":            {body: strings.Repeat("a", 400), baseline: nil, want: false},
+	}
+	check := &core.Check{SizeRatio: 3}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := check.Match(&internal.HTTPResponse{Body: tc.body, BaselineSize: tc.baseline})
+			if got != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestScanSizeRatio(t *testing.T) {
+	fetcher := bodyFetcher{
+		"http://foobar/":       "<html>home</html>",
+		"http://foobar/debug":  strings.Repeat("stack trace line\n", 50),
+		"http://foobar/status": "<html>ok</html>",
+	}
+	signatures := &core.Signatures{Plugins: []*core.Plugin{{
+		Endpoints: []string{"/debug", "/status"},
+		Checks:    []*core.Check{{Name: "Verbose error", Severity: "Low", SizeRatio: 5}},
+	}}}
+	scanner := core.NewScanner(fetcher, fetcher, signatures, 2)
+
+	output, _ := scanner.Scan(context.Background(), []string{"http://foobar"})
+	if len(output) != 1 || output[0].Endpoint != "/debug" {
+		t.Fatalf("expected: a finding on /debug, got: %v", output)
+	}
+	if !strings.Contains(output[0].Details, "baseline of 17 bytes") {
+		t.Errorf("expected: the baseline in the details, got: %v", output[0].Details)
+	}
+}
@@ -0,0 +1,26 @@
+package core
+
+import "strings"
+
+// categories are the vulnerability classes accepted when categories are validated strictly
+var categories = []string{
+	"Access Control",
+	"Exposed Service",
+	"Information Disclosure",
+	"Misconfiguration",
+	"Outdated Software",
+	"Sensitive Data Exposure",
+}
+
+func ValidCategory(category string) bool {
+	for _, c := range categories {
+		if category == c {
+			return true
+		}
+	}
+	return false
+}
+
+func CategoriesAsString() string {
+	return strings.Join(categories, ", ")
+}
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CheckpointFlushInterval is how often the progress of the scan is written to the resume file
+const CheckpointFlushInterval = 5 * time.Second
+
+// Checkpoint records the completed (url, plugin) pairs and the findings of a scan in a resume file,
+// so an interrupted scan can be restarted without sending the completed requests again.
+// A nil Checkpoint records nothing.
+type Checkpoint struct {
+	mux       sync.Mutex
+	file      *os.File
+	w         *bufio.Writer
+	completed map[string]bool
+	findings  []Output
+	// restored are the (url, check) pairs of the restored findings
+	restored map[string]bool
+	done     chan struct{}
+	closed   bool
+	// cut is set when the last line of the resume file misses its line break
+	cut bool
+}
+
+// checkpointEntry is a line of the resume file, either a completed pair or a finding
+type checkpointEntry struct {
+	URL     string  `json:"url,omitempty"`
+	Plugin  string  `json:"plugin,omitempty"`
+	Finding *Output `json:"finding,omitempty"`
+}
+
+// NewCheckpoint loads the progress saved in the resume file, if any, and appends the new progress to it
+func NewCheckpoint(path string) (*Checkpoint, error) {
+	c := &Checkpoint{completed: make(map[string]bool), restored: make(map[string]bool), done: make(chan struct{})}
+	if err := c.load(path); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	c.file = file
+	c.w = bufio.NewWriter(file)
+	if c.cut {
+		c.w.WriteString("\n")
+	}
+	go c.flushPeriodically()
+	return c, nil
+}
+
+func (c *Checkpoint) load(path string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil {
+			c.cut = last[0] != '\n'
+		}
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry checkpointEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// the last line may have been cut by a crash, its work is done again
+			log.Warn(fmt.Sprintf("Skipping invalid line %d of resume file %s: %v", line, path, err))
+			continue
+		}
+		if entry.Finding != nil {
+			// a job interrupted after its findings were recorded finds them again, the first ones are kept
+			key := checkpointKey(entry.Finding.URL, entry.Finding.Name)
+			if !c.restored[key] {
+				c.restored[key] = true
+				c.findings = append(c.findings, *entry.Finding)
+			}
+		} else {
+			c.completed[checkpointKey(entry.URL, entry.Plugin)] = true
+		}
+	}
+	return scanner.Err()
+}
+
+// Completed tells whether the plugin was already run against the url
+func (c *Checkpoint) Completed(url string, plugin *Plugin) bool {
+	if c == nil {
+		return false
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.completed[checkpointKey(url, plugin.ID())]
+}
+
+// Done records that the plugin was run against the url
+func (c *Checkpoint) Done(url string, plugin *Plugin) {
+	if c == nil {
+		return
+	}
+	c.record(checkpointEntry{URL: url, Plugin: plugin.ID()})
+}
+
+// Write records a finding, so it is reported again when the scan is resumed
+func (c *Checkpoint) Write(output Output) error {
+	if c == nil {
+		return nil
+	}
+	c.record(checkpointEntry{Finding: &output})
+	return nil
+}
+
+func (c *Checkpoint) record(entry checkpointEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.closed {
+		return
+	}
+	if entry.Finding == nil {
+		c.completed[checkpointKey(entry.URL, entry.Plugin)] = true
+	}
+	c.w.Write(append(line, '\n'))
+}
+
+// Restored tells whether the finding was restored from the resume file, so it is not reported twice when the interrupted
+// job finding it is run again
+func (c *Checkpoint) Restored(output Output) bool {
+	if c == nil {
+		return false
+	}
+	return c.restored[checkpointKey(output.URL, output.Name)]
+}
+
+// Findings returns the findings restored from the resume file
+func (c *Checkpoint) Findings() []Output {
+	if c == nil {
+		return nil
+	}
+	return c.findings
+}
+
+// Flush writes the recorded progress to the resume file
+func (c *Checkpoint) Flush() error {
+	if c == nil {
+		return nil
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.closed {
+		return nil
+	}
+	return c.w.Flush()
+}
+
+// Close flushes the progress and closes the resume file, it can be called several times
+func (c *Checkpoint) Close() error {
+	if c == nil {
+		return nil
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	err := c.w.Flush()
+	if cerr := c.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (c *Checkpoint) flushPeriodically() {
+	ticker := time.NewTicker(CheckpointFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Flush(); err != nil {
+				log.Error(err)
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func checkpointKey(url string, plugin string) string {
+	return url + "\n" + plugin
+}
+
+// ID identifies the plugin by its checks, whose names are unique across the signatures
+func (p *Plugin) ID() string {
+	names := make([]string, len(p.Checks))
+	for i, check := range p.Checks {
+		names[i] = check.Name
+	}
+	return strings.Join(names, ",")
+}
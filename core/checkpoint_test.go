@@ -0,0 +1,115 @@
+package core_test
+
+import (
+	"context"
+	"gochopchop/core"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resume.ndjson")
+	plugins := []*core.Plugin{
+		{Endpoint: "/a", Checks: []*core.Check{{Name: "A", Severity: "High"}}},
+		{Endpoint: "/b", Checks: []*core.Check{{Name: "B", Severity: "Low"}}},
+	}
+	scan := func(urls []string) (int, []core.Output) {
+		checkpoint, err := core.NewCheckpoint(path)
+		if err != nil {
+			t.Fatalf("expected a nil error, got : %v", err)
+		}
+		defer checkpoint.Close()
+		fetcher := &countingFetcher{calls: make(map[string]int)}
+		scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: plugins}, 2)
+		scanner.Checkpoint = checkpoint
+		scanner.Writer = checkpoint
+		output, _ := scanner.Scan(context.Background(), urls)
+		calls := 0
+		for _, n := range fetcher.calls {
+			calls += n
+		}
+		return calls, append(checkpoint.Findings(), output...)
+	}
+
+	if calls, output := scan([]string{"http://first"}); calls != 2 || len(output) != 2 {
+		t.Fatalf("expected: 2 requests and 2 findings, got: %v requests and %v", calls, output)
+	}
+	calls, output := scan([]string{"http://first", "http://second"})
+	if calls != 2 {
+		t.Errorf("expected: 2 requests, got: %v", calls)
+	}
+	if len(output) != 4 {
+		t.Errorf("expected: 4 findings, got: %v", output)
+	}
+}
+
+func TestCheckpointInvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resume.ndjson")
+	content := `{"url":"http://first/a","plugin":"A"}` + "\n" + `{"finding":{"url":"http://first/a","checkName":"A"}}` + "\n" + `{"url":"http://fir`
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint, err := core.NewCheckpoint(path)
+	if err != nil {
+		t.Fatalf("expected a nil error, got : %v", err)
+	}
+	defer checkpoint.Close()
+	plugin := &core.Plugin{Checks: []*core.Check{{Name: "A"}}}
+	if !checkpoint.Completed("http://first/a", plugin) {
+		t.Errorf("expected: completed, got: not completed")
+	}
+	if len(checkpoint.Findings()) != 1 {
+		t.Errorf("expected: 1 finding, got: %v", checkpoint.Findings())
+	}
+
+	// the progress is appended after the cut line
+	checkpoint.Done("http://second/a", plugin)
+	checkpoint.Close()
+	resumed, err := core.NewCheckpoint(path)
+	if err != nil {
+		t.Fatalf("expected a nil error, got : %v", err)
+	}
+	defer resumed.Close()
+	if !resumed.Completed("http://second/a", plugin) {
+		t.Errorf("expected: completed, got: not completed")
+	}
+}
+
+func TestCheckpointInterruptedJob(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resume.ndjson")
+	// the finding of the job was recorded, but the scan was interrupted before the job was done
+	finding := `{"finding":{"url":"http://first/a","checkName":"A","severity":"High"}}` + "\n"
+	if err := ioutil.WriteFile(path, []byte(finding+finding), 0644); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint, err := core.NewCheckpoint(path)
+	if err != nil {
+		t.Fatalf("expected a nil error, got : %v", err)
+	}
+	if len(checkpoint.Findings()) != 1 {
+		t.Errorf("expected: 1 restored finding, got: %v", checkpoint.Findings())
+	}
+	plugins := []*core.Plugin{{Endpoint: "/a", Checks: []*core.Check{{Name: "A", Severity: "High"}}}}
+	fetcher := &countingFetcher{calls: make(map[string]int)}
+	scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: plugins}, 1)
+	scanner.Checkpoint = checkpoint
+	scanner.Writer = checkpoint
+	output, _ := scanner.Scan(context.Background(), []string{"http://first"})
+	checkpoint.Close()
+
+	if fetcher.calls["http://first/a"] != 1 {
+		t.Errorf("expected: the interrupted job to run again, got: %v", fetcher.calls)
+	}
+	if len(output) != 0 {
+		t.Errorf("expected: the restored finding not to be reported again, got: %v", output)
+	}
+	resumed, err := core.NewCheckpoint(path)
+	if err != nil {
+		t.Fatalf("expected a nil error, got : %v", err)
+	}
+	defer resumed.Close()
+	if len(resumed.Findings()) != 1 {
+		t.Errorf("expected: 1 restored finding, got: %v", resumed.Findings())
+	}
+}
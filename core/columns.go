@@ -0,0 +1,65 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// columns that can be selected for the table and CSV outputs
+var columns = []string{"url", "domain", "endpoint", "severity", "plugin", "remediation", "description", "category", "details", "duration", "status", "final_url", "confidence", "evidence"}
+
+func ValidColumn(column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+func ColumnsAsString() string {
+	return strings.Join(columns, ", ")
+}
+
+// Column returns the value of the output for a selectable column
+func (o Output) Column(column string) string {
+	switch column {
+	case "url":
+		return o.URL
+	case "domain":
+		u, err := url.Parse(o.URL)
+		if err != nil || u.Host == "" {
+			return o.URL
+		}
+		return u.Hostname()
+	case "endpoint":
+		return o.Endpoint
+	case "severity":
+		return o.Severity
+	case "plugin":
+		return o.Name
+	case "remediation":
+		return o.Remediation
+	case "description":
+		return o.Description
+	case "category":
+		return o.Category
+	case "details":
+		return o.Details
+	case "duration":
+		return fmt.Sprintf("%dms", o.DurationMs)
+	case "status":
+		return o.Status
+	case "final_url":
+		return o.FinalURL
+	case "confidence":
+		return o.Confidence
+	case "evidence":
+		if o.Evidence == nil {
+			return ""
+		}
+		return o.Evidence.Snippet
+	}
+	return ""
+}
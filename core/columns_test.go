@@ -0,0 +1,42 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"testing"
+)
+
+func TestOutputColumn(t *testing.T) {
+	output := core.Output{
+		URL:        "https://foobar.com:8443/.git/config",
+		Endpoint:   "/.git/config",
+		Name:       "Git exposed",
+		Severity:   "High",
+		Category:   "Information Disclosure",
+		DurationMs: 1250,
+		Confidence: "Medium",
+		Evidence:   &core.Evidence{Match: "[core]", Offset: 0, Snippet: "[core]\n\trepositoryformatversion = 0"},
+	}
+	var tests = map[string]struct {
+		column string
+		want   string
+	}{
+		"url":        {column: "url", want: "https://foobar.com:8443/.git/config"},
+		"domain":     {column: "domain", want: "foobar.com"},
+		"plugin":     {column: "plugin", want: "Git exposed"},
+		"severity":   {column: "severity", want: "High"},
+		"category":   {column: "category", want: "Information Disclosure"},
+		"duration":   {column: "duration", want: "1250ms"},
+		"confidence": {column: "confidence", want: "Medium"},
+		"evidence":   {column: "evidence", want: "[core]\n\trepositoryformatversion = 0"},
+		"unknown":    {column: "unknown", want: ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := output.Column(tc.column)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
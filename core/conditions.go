@@ -0,0 +1,221 @@
+package core
+
+import (
+	"fmt"
+	"gochopchop/internal"
+	"regexp"
+	"strings"
+)
+
+// Condition is a node of the conditions tree of a check: a group of conditions (and, or, not)
+// or a predicate on the body, a header or the status code of the response. A node sets exactly one of them.
+type Condition struct {
+	// And matches when all its conditions match, Or when at least one does, Not when its condition doesn't
+	And []*Condition `yaml:"and"`
+	Or  []*Condition `yaml:"or"`
+	Not *Condition   `yaml:"not"`
+	// Body is a string the body contains, BodyRegex a regex matching the body
+	Body      string `yaml:"body"`
+	BodyRegex string `yaml:"body_regex"`
+	// Header is a KEY, KEY:* or KEY:VALUE condition as in headers, HeaderRegex a KEY: REGEX condition as in headers_regex
+	Header      string `yaml:"header"`
+	HeaderRegex string `yaml:"header_regex"`
+	// Status is a status code or a class of status codes, eg. 200 or 2xx
+	Status string `yaml:"status"`
+	regex  *regexp.Regexp
+}
+
+// kinds returns the names of the fields set on the node
+func (c *Condition) kinds() []string {
+	var kinds []string
+	for _, field := range []struct {
+		name string
+		set  bool
+	}{
+		{"and", len(c.And) > 0},
+		{"or", len(c.Or) > 0},
+		{"not", c.Not != nil},
+		{"body", c.Body != ""},
+		{"body_regex", c.BodyRegex != ""},
+		{"header", c.Header != ""},
+		{"header_regex", c.HeaderRegex != ""},
+		{"status", c.Status != ""},
+	} {
+		if field.set {
+			kinds = append(kinds, field.name)
+		}
+	}
+	return kinds
+}
+
+// Validate checks that every node of the tree sets exactly one field, and compiles its regexes
+func (c *Condition) Validate() error {
+	kinds := c.kinds()
+	if len(kinds) != 1 {
+		return fmt.Errorf("Invalid condition : %s. A condition must set exactly one of and, or, not, body, body_regex, header, header_regex, status", strings.Join(kinds, ", "))
+	}
+	for _, child := range c.children() {
+		if child == nil {
+			return fmt.Errorf("Invalid condition : empty condition in %s", kinds[0])
+		}
+		if err := child.Validate(); err != nil {
+			return err
+		}
+	}
+	var err error
+	switch {
+	case c.BodyRegex != "":
+		if c.regex, err = regexp.Compile(c.BodyRegex); err != nil {
+			return fmt.Errorf("Invalid regex %q in conditions : %v", c.BodyRegex, err)
+		}
+	case c.HeaderRegex != "":
+		key, pattern := splitHeaderRegex(c.HeaderRegex)
+		if key == "" || !strings.Contains(c.HeaderRegex, ":") {
+			return fmt.Errorf("Invalid header regex format : %s in conditions. Format should be KEY: REGEX", c.HeaderRegex)
+		}
+		if c.regex, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("Invalid regex %q in conditions : %v", pattern, err)
+		}
+	case c.Header != "":
+		if key, _, _ := ParseHeaderCondition(c.Header); key == "" {
+			return fmt.Errorf("Invalid header format : %s. Format should be KEY, KEY:* or KEY:VALUE", c.Header)
+		}
+	case c.Status != "":
+		if _, ok := parseStatusCode(c.Status); !ok {
+			return fmt.Errorf("Invalid status code : %s. Please use a code (eg. 200) or a class (eg. 2xx)", c.Status)
+		}
+	}
+	return nil
+}
+
+func (c *Condition) children() []*Condition {
+	children := append(append([]*Condition{}, c.And...), c.Or...)
+	if c.Not != nil {
+		children = append(children, c.Not)
+	}
+	return children
+}
+
+// Match tells whether the response satisfies the tree, the body strings being compared regardless of the case when caseInsensitive is set
+func (c *Condition) Match(resp *internal.HTTPResponse, caseInsensitive bool) bool {
+	switch {
+	case len(c.And) > 0:
+		for _, child := range c.And {
+			if !child.Match(resp, caseInsensitive) {
+				return false
+			}
+		}
+		return true
+	case len(c.Or) > 0:
+		for _, child := range c.Or {
+			if child.Match(resp, caseInsensitive) {
+				return true
+			}
+		}
+		return false
+	case c.Not != nil:
+		return !c.Not.Match(resp, caseInsensitive)
+	case c.Body != "":
+		if caseInsensitive {
+			return strings.Contains(strings.ToLower(resp.Body), strings.ToLower(c.Body))
+		}
+		return strings.Contains(resp.Body, c.Body)
+	case c.BodyRegex != "":
+		return regexMatch(c.compiled(c.BodyRegex), resp.Body)
+	case c.Header != "":
+		return headerFound(resp.Header, c.Header)
+	case c.HeaderRegex != "":
+		_, pattern := splitHeaderRegex(c.HeaderRegex)
+		ok, _ := headerRegexMatch(resp.Header, c.HeaderRegex, c.compiled(pattern))
+		return ok
+	case c.Status != "":
+		return statusCodeIn(resp.StatusCode, []string{c.Status})
+	}
+	return false
+}
+
+// compiled returns the regex of the node, compiled on the fly when the tree was not validated. An invalid pattern never matches.
+func (c *Condition) compiled(pattern string) *regexp.Regexp {
+	if c.regex != nil {
+		return c.regex
+	}
+	regex, _ := regexp.Compile(pattern)
+	return regex
+}
+
+// String renders the tree as an expression, eg. (body "A" or body "B") and not body "C" and status 200
+func (c *Condition) String() string {
+	switch {
+	case len(c.And) > 0:
+		return joinConditions(c.And, " and ")
+	case len(c.Or) > 0:
+		return joinConditions(c.Or, " or ")
+	case c.Not != nil:
+		return "not " + c.Not.operand()
+	case c.Body != "":
+		return fmt.Sprintf("body %q", c.Body)
+	case c.BodyRegex != "":
+		return fmt.Sprintf("body_regex %q", c.BodyRegex)
+	case c.Header != "":
+		return fmt.Sprintf("header %q", c.Header)
+	case c.HeaderRegex != "":
+		return fmt.Sprintf("header_regex %q", c.HeaderRegex)
+	case c.Status != "":
+		return fmt.Sprintf("status %s", c.Status)
+	}
+	return ""
+}
+
+// operand renders the node within a group, the groups of several conditions being parenthesized
+func (c *Condition) operand() string {
+	if len(c.And) > 1 || len(c.Or) > 1 {
+		return "(" + c.String() + ")"
+	}
+	return c.String()
+}
+
+func joinConditions(conditions []*Condition, operator string) string {
+	operands := make([]string, len(conditions))
+	for i, condition := range conditions {
+		operands[i] = condition.operand()
+	}
+	return strings.Join(operands, operator)
+}
+
+// bodyTerms returns the body strings the tree looks for, the ones under a not being skipped as they are never found in a match
+func (c *Condition) bodyTerms() []string {
+	if c == nil || c.Not != nil {
+		return nil
+	}
+	terms := []string{}
+	if c.Body != "" {
+		terms = append(terms, c.Body)
+	}
+	for _, child := range c.children() {
+		terms = append(terms, child.bodyTerms()...)
+	}
+	return terms
+}
+
+func (c *Condition) Equals(condition *Condition) bool {
+	if c == nil || condition == nil {
+		return c == condition
+	}
+	if c.Body != condition.Body || c.BodyRegex != condition.BodyRegex || c.Header != condition.Header || c.HeaderRegex != condition.HeaderRegex || c.Status != condition.Status {
+		return false
+	}
+	if len(c.And) != len(condition.And) || len(c.Or) != len(condition.Or) || !c.Not.Equals(condition.Not) {
+		return false
+	}
+	for i := range c.And {
+		if !c.And[i].Equals(condition.And[i]) {
+			return false
+		}
+	}
+	for i := range c.Or {
+		if !c.Or[i].Equals(condition.Or[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"net/http"
+	"testing"
+)
+
+func TestCheckMatchConditions(t *testing.T) {
+	// (A or B) and not C and status 200
+	tree := &core.Condition{And: []*core.Condition{
+		{Or: []*core.Condition{{Body: "A"}, {Body: "B"}}},
+		{Not: &core.Condition{Body: "C"}},
+		{Status: "200"},
+	}}
+	var tests = map[string]struct {
+		body       string
+		statusCode int
+		header     http.Header
+		conditions *core.Condition
+		check      core.Check
+		want       bool
+	}{
+		"first of the or":         {body: "A", statusCode: 200, conditions: tree, want: true},
+		"second of the or":        {body: "B", statusCode: 200, conditions: tree, want: true},
+		"none of the or":          {body: "D", statusCode: 200, conditions: tree, want: false},
+		"excluded by the not":     {body: "A C", statusCode: 200, conditions: tree, want: false},
+		"other status":            {body: "A", statusCode: 404, conditions: tree, want: false},
+		"status class":            {statusCode: 302, conditions: &core.Condition{Status: "3xx"}, want: true},
+		"header":                  {header: http.Header{"Server": {"nginx"}}, conditions: &core.Condition{Header: "Server:nginx"}, want: true},
+		"missing header":          {conditions: &core.Condition{Header: "Server"}, want: false},
+		"header regex":            {header: http.Header{"Server": {"nginx/1.14"}}, conditions: &core.Condition{HeaderRegex: `Server: ^nginx/1\.1[0-8]`}, want: true},
+		"body regex":              {body: "version 2.4", conditions: &core.Condition{BodyRegex: `version 2\.[0-9]+`}, want: true},
+		"case insensitive":        {body: "ADMIN", conditions: &core.Condition{Body: "admin"}, check: core.Check{CaseInsensitive: true}, want: true},
+		"case sensitive":          {body: "ADMIN", conditions: &core.Condition{Body: "admin"}, want: false},
+		"along with legacy field": {body: "A", statusCode: 200, conditions: tree, check: core.Check{MustNotMatch: []string{"A"}}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			check := tc.check
+			check.Conditions = tc.conditions
+			if err := check.Conditions.Validate(); err != nil {
+				t.Fatalf("expected: no error, got: %v", err)
+			}
+			have := check.Match(&internal.HTTPResponse{Body: tc.body, StatusCode: tc.statusCode, Header: tc.header})
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestConditionValidate(t *testing.T) {
+	var tests = map[string]struct {
+		condition *core.Condition
+		nilErr    bool
+	}{
+		"nested groups":       {condition: &core.Condition{Or: []*core.Condition{{And: []*core.Condition{{Body: "A"}, {Status: "2xx"}}}, {Not: &core.Condition{Header: "X-Debug"}}}}, nilErr: true},
+		"two fields":          {condition: &core.Condition{Body: "A", Status: "200"}, nilErr: false},
+		"no field":            {condition: &core.Condition{}, nilErr: false},
+		"invalid nested node": {condition: &core.Condition{And: []*core.Condition{{Body: "A"}, {}}}, nilErr: false},
+		"invalid regex":       {condition: &core.Condition{BodyRegex: "("}, nilErr: false},
+		"invalid header":      {condition: &core.Condition{HeaderRegex: "no key"}, nilErr: false},
+		"invalid status":      {condition: &core.Condition{Status: "6xx"}, nilErr: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.condition.Validate()
+			if (err == nil) != tc.nilErr {
+				t.Errorf("expected a nil error: %v, got: %v", tc.nilErr, err)
+			}
+		})
+	}
+}
+
+func TestConditionString(t *testing.T) {
+	condition := &core.Condition{And: []*core.Condition{
+		{Or: []*core.Condition{{Body: "A"}, {Body: "B"}}},
+		{Not: &core.Condition{Body: "C"}},
+		{Status: "200"},
+	}}
+	want := `(body "A" or body "B") and not body "C" and status 200`
+	if have := condition.String(); have != want {
+		t.Errorf("expected: %v, got: %v", want, have)
+	}
+}
@@ -0,0 +1,19 @@
+package core
+
+import "strings"
+
+// confidences are how certain the match of a check is, from the most to the least certain
+var confidences = [3]string{"High", "Medium", "Low"}
+
+func ValidConfidence(confidence string) bool {
+	for _, c := range confidences {
+		if confidence == c {
+			return true
+		}
+	}
+	return false
+}
+
+func ConfidencesAsString() string {
+	return strings.Join(confidences[:], ", ")
+}
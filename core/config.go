@@ -1,18 +1,117 @@
 package core
 
+import (
+	"net/url"
+	"time"
+)
+
+// Version of ChopChop, announced in the default User-Agent
+var Version = "dev"
+
+// DefaultUserAgent identifies ChopChop in the logs of the scanned servers
+func DefaultUserAgent() string {
+	return "gochopchop/" + Version
+}
+
 // Struct for config flags
 type Config struct {
 	HTTP           HTTPConfig
 	MaxSeverity    string
+	WarnSeverity   string
 	ExportFormats  []string
+	Stream         bool
 	Urls           []string
 	ExportFilename string
 	SeverityFilter string
-	PluginFilter   []string
-	Threads        int
+	// MinSeverity keeps the checks of this severity or more critical, unlike the exact SeverityFilter
+	MinSeverity  string
+	PluginFilter []string
+	// Tags keeps the plugins having one of them, ExcludeTags removes the plugins having one of them
+	Tags        []string
+	ExcludeTags []string
+	// StrictCategories only accepts the known plugin categories
+	StrictCategories bool
+	Threads          int
+	ValidateOnly     bool
+	// DryRun prints the urls to request instead of scanning them
+	DryRun     bool
+	OnComplete string
+	// Webhook receives a JSON summary of the findings once the scan is over
+	Webhook string
+	// Baseline is the json export of a previous run the findings are compared to
+	Baseline string
+	// FailOnNew makes the scan fail on the new findings only
+	FailOnNew bool
+	// FailFast stops the scan on the first finding over MaxSeverity
+	FailFast    bool
+	BasePath    string
+	RiskScore   bool
+	RiskWeights map[string]int
+	// TableLimit is the number of findings above which the table is replaced by compact lines
+	TableLimit int
+	// AllowEmpty lets a scan without url or signature succeed
+	AllowEmpty bool
+	// Columns selected for the table and CSV outputs, all of them when empty
+	Columns []string
+	// ShowTiming adds the response time of the findings to the table
+	ShowTiming bool
+	// RequestedURLsFile receives the urls requested during the scan
+	RequestedURLsFile string
+	// ResumeFile records the progress of the scan, to restart it where it was interrupted
+	ResumeFile string
+	// RateLimits are the requests per second allowed for the checks of each severity
+	RateLimits map[string]float64
+	// RateLimit is the requests per second allowed to each host, unlimited when 0
+	RateLimit float64
+	// MaxPerHost caps the requests in flight to each host, unlimited when 0
+	MaxPerHost int
+	// NoFindings is what is printed when nothing is found (log, silent or json)
+	NoFindings         string
+	NoFindingsExitCode int
+	// FindingsExitCode is the exit code when something is found without blocking, BlockExitCode when the CI is blocked
+	FindingsExitCode int
+	BlockExitCode    int
+	// LowMemory streams the findings to the exports and only keeps their counts
+	LowMemory bool
+	ASFF      ASFFConfig
+	// Adaptive adjusts the requests in flight to the error rate of the targets
+	Adaptive bool
+	// Progress is the interval of the progress reports during the scan, none when 0
+	Progress time.Duration
+	// InsecureTargets are the urls whose TLS certificate is not verified, unlike the other targets
+	InsecureTargets map[string]bool
+	// CaptureEvidence records in the findings the part of the body their check matched
+	CaptureEvidence bool
+	// DetectBlocking flags the hosts refusing every request, eg. behind a WAF
+	DetectBlocking bool
+}
+
+// ASFFConfig identifies the AWS account the findings are imported in
+type ASFFConfig struct {
+	AccountID string
+	Region    string
+	// ProductArn defaults to the default product of the account
+	ProductArn string
 }
 
 type HTTPConfig struct {
 	Insecure bool
 	Timeout  int
+	// Proxy credentials, never to be logged
+	ProxyUser string
+	ProxyPass string
+	// Proxy of all the requests, the environment variables are used when nil
+	Proxy *url.URL
+	// Authorization header of all the requests, unless a plugin sets its own, never to be logged
+	Authorization string
+	// UserAgent of all the requests, unless a plugin sets its own
+	UserAgent string
+	// MaxRedirects followed by the plugins following the redirects, 10 when 0
+	MaxRedirects int
+	// HTTPVersion of the requests: auto (negotiated, the default when empty), 1.1 or 2
+	HTTPVersion string
+	// Resolver is the DNS server ("host:port") resolving the hosts, the system resolver when empty
+	Resolver string
+	// DNSCacheTTL is how long a resolution is reused, 60s when 0
+	DNSCacheTTL time.Duration
 }
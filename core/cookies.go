@@ -0,0 +1,102 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CookieCheck asserts the presence or absence of the security flags of a named cookie
+// set by the response. A nil flag is not checked.
+type CookieCheck struct {
+	Name     string `yaml:"name"`
+	Secure   *bool  `yaml:"secure"`
+	HttpOnly *bool  `yaml:"http_only"`
+	SameSite *bool  `yaml:"same_site"`
+}
+
+// Match parses the Set-Cookie headers and reports whether the named cookie is set
+// with every flag in the expected state. When it matches, the returned string names
+// the cookie and describes its flags.
+func (c *CookieCheck) Match(header http.Header) (bool, string) {
+	resp := &http.Response{Header: header}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name != c.Name {
+			continue
+		}
+		flags := map[string]bool{
+			"Secure":   cookie.Secure,
+			"HttpOnly": cookie.HttpOnly,
+			"SameSite": cookie.SameSite != 0,
+		}
+		expected := map[string]*bool{
+			"Secure":   c.Secure,
+			"HttpOnly": c.HttpOnly,
+			"SameSite": c.SameSite,
+		}
+		var missing, present []string
+		for _, flag := range []string{"Secure", "HttpOnly", "SameSite"} {
+			if expected[flag] == nil {
+				continue
+			}
+			if flags[flag] != *expected[flag] {
+				return false, ""
+			}
+			if flags[flag] {
+				present = append(present, flag)
+			} else {
+				missing = append(missing, flag)
+			}
+		}
+		detail := fmt.Sprintf("cookie %s", c.Name)
+		if len(missing) > 0 {
+			detail = fmt.Sprintf("%s is missing %s", detail, strings.Join(missing, ", "))
+		}
+		if len(present) > 0 {
+			if len(missing) > 0 {
+				detail += " and"
+			}
+			detail = fmt.Sprintf("%s has %s", detail, strings.Join(present, ", "))
+		}
+		return true, detail
+	}
+	return false, ""
+}
+
+func (c *CookieCheck) Equals(cookie *CookieCheck) bool {
+	if c == nil || cookie == nil {
+		return c == cookie
+	}
+	return c.Name == cookie.Name &&
+		boolPtrEqual(c.Secure, cookie.Secure) &&
+		boolPtrEqual(c.HttpOnly, cookie.HttpOnly) &&
+		boolPtrEqual(c.SameSite, cookie.SameSite)
+}
+
+func boolPtrEqual(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func intPtrEqual(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func stringPtrEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func int32PtrEqual(a, b *int32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,44 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"net/http"
+	"testing"
+)
+
+func createBool(b bool) *bool {
+	return &b
+}
+
+func TestCookieCheckMatch(t *testing.T) {
+	header := http.Header{
+		"Set-Cookie": []string{
+			"session=abc; Path=/; HttpOnly",
+			"prefs=dark; Secure; HttpOnly; SameSite=Strict",
+		},
+	}
+	var tests = map[string]struct {
+		cookie *core.CookieCheck
+		want   bool
+	}{
+		"Missing Secure flag":     {cookie: &core.CookieCheck{Name: "session", Secure: createBool(false)}, want: true},
+		"Secure flag present":     {cookie: &core.CookieCheck{Name: "prefs", Secure: createBool(false)}, want: false},
+		"Missing SameSite flag":   {cookie: &core.CookieCheck{Name: "session", SameSite: createBool(false)}, want: true},
+		"All flags present":       {cookie: &core.CookieCheck{Name: "prefs", Secure: createBool(true), HttpOnly: createBool(true), SameSite: createBool(true)}, want: true},
+		"HttpOnly not expected":   {cookie: &core.CookieCheck{Name: "session", HttpOnly: createBool(false)}, want: false},
+		"Cookie not set":          {cookie: &core.CookieCheck{Name: "unknown", Secure: createBool(false)}, want: false},
+		"Cookie set, no flag set": {cookie: &core.CookieCheck{Name: "session"}, want: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have, detail := tc.cookie.Match(header)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+			if have && detail == "" {
+				t.Errorf("expected a detail naming the cookie")
+			}
+		})
+	}
+}
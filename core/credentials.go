@@ -0,0 +1,79 @@
+package core
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxAttempts caps the number of credentials tried per url, to avoid locking accounts out
+const DefaultMaxAttempts = 10
+
+// DefaultCredentials are the credential pairs tried against a login endpoint.
+// The checks of the plugin describe what a successful login looks like.
+type DefaultCredentials struct {
+	File        string        `yaml:"file"`
+	MaxAttempts int           `yaml:"max_attempts"`
+	Credentials []*Credential `yaml:"-"`
+}
+
+type Credential struct {
+	User     string
+	Password string
+}
+
+func (c *Credential) String() string {
+	return fmt.Sprintf("%s:%s", c.User, c.Password)
+}
+
+// BasicAuthorization returns the value of the Authorization header for the credential
+func (c *Credential) BasicAuthorization() string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.String()))
+}
+
+// Attempts returns the maximum number of credentials to try
+func (d *DefaultCredentials) Attempts() int {
+	if d.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+	return d.MaxAttempts
+}
+
+// Load reads the user:password pairs of the credentials file, one per line.
+// Empty lines and lines starting with # are skipped.
+// A relative path is resolved from dir, usually the directory of the signature file.
+func (d *DefaultCredentials) Load(dir string) error {
+	path := d.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not read default credentials file: %v", err)
+	}
+	defer file.Close()
+
+	d.Credentials = nil
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("Invalid credential format in %s : %s. Format should be USER:PASSWORD", path, line)
+		}
+		d.Credentials = append(d.Credentials, &Credential{User: parts[0], Password: parts[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(d.Credentials) == 0 {
+		return fmt.Errorf("default credentials file %s is empty", path)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package core_test
+
+import (
+	"context"
+	"gochopchop/core"
+	"gochopchop/internal"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+type loginFetcher struct {
+	mux      sync.Mutex
+	attempts int
+}
+
+// Fetch only accepts the admin:admin credentials
+func (f *loginFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.attempts++
+	if req.Header.Get("Authorization") == "Basic YWRtaW46YWRtaW4=" {
+		return &internal.HTTPResponse{StatusCode: 200, Body: "Welcome admin"}, nil
+	}
+	return &internal.HTTPResponse{StatusCode: 401}, nil
+}
+
+func TestScanDefaultCredentials(t *testing.T) {
+	credentials := []*core.Credential{
+		{User: "root", Password: "root"},
+		{User: "admin", Password: "password"},
+		{User: "admin", Password: "admin"},
+		{User: "tomcat", Password: "tomcat"},
+	}
+	var tests = map[string]struct {
+		maxAttempts  int
+		wantFindings int
+		wantAttempts int
+	}{
+		"credentials accepted":   {maxAttempts: 0, wantFindings: 1, wantAttempts: 3},
+		"max attempts reached":   {maxAttempts: 2, wantFindings: 0, wantAttempts: 2},
+		"stops at first success": {maxAttempts: 4, wantFindings: 1, wantAttempts: 3},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := &loginFetcher{}
+			check := &core.Check{Name: "Default credentials", Severity: "High", StatusCode: createInt32(200)}
+			plugin := &core.Plugin{
+				Endpoint:           "/manager/html",
+				Checks:             []*core.Check{check},
+				DefaultCredentials: &core.DefaultCredentials{MaxAttempts: tc.maxAttempts, Credentials: credentials},
+			}
+			scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: []*core.Plugin{plugin}}, 1)
+			output, _ := scanner.Scan(context.Background(), []string{"http://tomcat"})
+			if len(output) != tc.wantFindings {
+				t.Errorf("expected: %v findings, got: %v", tc.wantFindings, len(output))
+			}
+			if fetcher.attempts != tc.wantAttempts {
+				t.Errorf("expected: %v attempts, got: %v", tc.wantAttempts, fetcher.attempts)
+			}
+		})
+	}
+}
+
+func TestDefaultCredentialsLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chopchop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	content := "# tomcat defaults\nadmin:admin\n\ntomcat:s3cr:et\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "creds.txt"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	credentials := &core.DefaultCredentials{File: "creds.txt"}
+	if err := credentials.Load(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(credentials.Credentials) != 2 {
+		t.Fatalf("expected: 2 credentials, got: %v", len(credentials.Credentials))
+	}
+	if credentials.Credentials[1].Password != "s3cr:et" {
+		t.Errorf("expected: s3cr:et, got: %v", credentials.Credentials[1].Password)
+	}
+}
@@ -0,0 +1,57 @@
+package core
+
+// Statuses of the findings compared to the findings of a previous run
+const (
+	StatusNew      = "new"
+	StatusExisting = "existing"
+	StatusResolved = "resolved"
+)
+
+// Diff compares the findings to the ones of a previous run, keyed by domain, plugin and tested url.
+// The findings are marked new or existing, and the previous findings not found anymore are appended as resolved.
+// The previous run may itself be a diff, its resolved findings are ignored.
+func Diff(previous []Output, outputs []Output) []Output {
+	known := make(map[string]bool, len(previous))
+	for _, output := range previous {
+		if output.Status != StatusResolved {
+			known[diffKey(output)] = true
+		}
+	}
+	found := make(map[string]bool, len(outputs))
+	diff := make([]Output, 0, len(outputs))
+	for _, output := range outputs {
+		key := diffKey(output)
+		found[key] = true
+		output.Status = StatusNew
+		if known[key] {
+			output.Status = StatusExisting
+		}
+		diff = append(diff, output)
+	}
+	for _, output := range previous {
+		key := diffKey(output)
+		if found[key] || output.Status == StatusResolved {
+			continue
+		}
+		// a finding reported several times in the previous run is resolved once
+		found[key] = true
+		output.Status = StatusResolved
+		diff = append(diff, output)
+	}
+	return diff
+}
+
+// NewFindings keeps the findings that were not found by the previous run
+func NewFindings(outputs []Output) []Output {
+	var news []Output
+	for _, output := range outputs {
+		if output.Status == StatusNew {
+			news = append(news, output)
+		}
+	}
+	return news
+}
+
+func diffKey(output Output) string {
+	return output.Column("domain") + "\n" + output.Name + "\n" + output.URL
+}
@@ -0,0 +1,75 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	git := core.Output{URL: "https://foobar.com/.git/config", Name: "Git exposed", Severity: "High"}
+	server := core.Output{URL: "https://foobar.com/", Name: "Server header", Severity: "Low"}
+	otherServer := core.Output{URL: "https://other.com/", Name: "Server header", Severity: "Low"}
+	resolvedGit := git
+	resolvedGit.Status = core.StatusResolved
+
+	var tests = map[string]struct {
+		previous []core.Output
+		outputs  []core.Output
+		want     map[string]string
+	}{
+		"no previous run": {
+			previous: nil,
+			outputs:  []core.Output{git, server},
+			want:     map[string]string{git.URL: core.StatusNew, server.URL: core.StatusNew},
+		},
+		"new, existing and resolved": {
+			previous: []core.Output{git, otherServer},
+			outputs:  []core.Output{git, server},
+			want:     map[string]string{git.URL: core.StatusExisting, server.URL: core.StatusNew, otherServer.URL: core.StatusResolved},
+		},
+		"resolved once": {
+			previous: []core.Output{otherServer, otherServer},
+			outputs:  []core.Output{},
+			want:     map[string]string{otherServer.URL: core.StatusResolved},
+		},
+		"previous diff": {
+			previous: []core.Output{resolvedGit},
+			outputs:  []core.Output{git},
+			want:     map[string]string{git.URL: core.StatusNew},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			diff := core.Diff(tc.previous, tc.outputs)
+			got := make(map[string]string)
+			for _, output := range diff {
+				got[output.URL] = output.Status
+			}
+			if len(diff) != len(tc.want) || len(got) != len(tc.want) {
+				t.Fatalf("expected: %v, got: %v", tc.want, diff)
+			}
+			for url, status := range tc.want {
+				if got[url] != status {
+					t.Errorf("expected: %v, got: %v", tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewFindings(t *testing.T) {
+	previous := []core.Output{{URL: "https://foobar.com/", Name: "Server header", Severity: "Low"}}
+	outputs := []core.Output{
+		{URL: "https://foobar.com/", Name: "Server header", Severity: "Low"},
+		{URL: "https://foobar.com/.git/config", Name: "Git exposed", Severity: "High"},
+	}
+	news := core.NewFindings(core.Diff(previous, outputs))
+	if len(news) != 1 || news[0].Name != "Git exposed" {
+		t.Errorf("expected: %v, got: %v", outputs[1:], news)
+	}
+	summary := core.Summarize(core.Diff(outputs, previous))
+	if summary.Total != 1 {
+		t.Errorf("expected: %v, got: %v", 1, summary.Total)
+	}
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// endpointPlaceholderRegex matches the {name} placeholders of the endpoints. The {{name}} placeholders of the steps and the
+// ${NAME} environment variables are matched too, so they are left as is rather than taken for a {name} one.
+var endpointPlaceholderRegex = regexp.MustCompile(`{{[^}]*}}|\$\{[^}]*\}|{[^{}]*}`)
+
+// EndpointPlaceholders are the placeholders replaced in the endpoints by the parts of the scanned url
+var EndpointPlaceholders = []string{"host", "scheme", "port"}
+
+// ExpandEndpoint replaces the {host}, {scheme} and {port} placeholders of the endpoint with the parts of the scanned url,
+// eg. /users/{host}/config for https://example.com is /users/example.com/config.
+// The port is the default one of the scheme when the url doesn't set one.
+func ExpandEndpoint(endpoint string, target string) string {
+	if !strings.Contains(endpoint, "{") {
+		return endpoint
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return endpoint
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if strings.EqualFold(u.Scheme, "https") {
+			port = "443"
+		}
+	}
+	values := map[string]string{"host": u.Hostname(), "scheme": u.Scheme, "port": port}
+	return endpointPlaceholderRegex.ReplaceAllStringFunc(endpoint, func(placeholder string) string {
+		if name, ok := endpointPlaceholder(placeholder); ok {
+			if value, ok := values[name]; ok {
+				return value
+			}
+		}
+		return placeholder
+	})
+}
+
+// endpointPlaceholder returns the name of a {name} placeholder, false for the {{name}} and ${NAME} ones
+func endpointPlaceholder(placeholder string) (string, bool) {
+	if strings.HasPrefix(placeholder, "{{") || strings.HasPrefix(placeholder, "$") {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(placeholder, "{"), "}"), true
+}
+
+// ValidateEndpoints ensures the endpoints of the plugin and of its steps only use the known placeholders
+func (p *Plugin) ValidateEndpoints() error {
+	endpoints := append([]string{p.Endpoint}, p.Endpoints...)
+	for _, step := range p.Steps {
+		endpoints = append(endpoints, step.Endpoint)
+	}
+	for _, endpoint := range endpoints {
+		for _, placeholder := range endpointPlaceholderRegex.FindAllString(endpoint, -1) {
+			if name, ok := endpointPlaceholder(placeholder); ok && !contains(EndpointPlaceholders, name) {
+				return fmt.Errorf("Invalid placeholder : %s in endpoint %s. Please use : {%s}", placeholder, endpoint, strings.Join(EndpointPlaceholders, "}, {"))
+			}
+		}
+	}
+	return nil
+}
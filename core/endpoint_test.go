@@ -0,0 +1,56 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"testing"
+)
+
+func TestExpandEndpoint(t *testing.T) {
+	var tests = map[string]struct {
+		endpoint string
+		target   string
+		want     string
+	}{
+		"no placeholder":     {endpoint: "/.git/config", target: "https://example.com", want: "/.git/config"},
+		"host":               {endpoint: "/users/{host}/config", target: "https://example.com:8443", want: "/users/example.com/config"},
+		"scheme and port":    {endpoint: "/redirect?to={scheme}://{host}:{port}/", target: "http://10.0.0.1:8080", want: "/redirect?to=http://10.0.0.1:8080/"},
+		"default http port":  {endpoint: "/{port}", target: "http://example.com", want: "/80"},
+		"default https port": {endpoint: "/{port}", target: "https://example.com", want: "/443"},
+		"step placeholder":   {endpoint: "/admin/{host}?token={{token}}", target: "http://example.com", want: "/admin/example.com?token={{token}}"},
+		"step named host":    {endpoint: "/{{host}}", target: "http://example.com", want: "/{{host}}"},
+		"environment":        {endpoint: "/${host}/{host}", target: "http://example.com", want: "/${host}/example.com"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := core.ExpandEndpoint(tc.endpoint, tc.target)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestPluginValidateEndpoints(t *testing.T) {
+	var tests = map[string]struct {
+		plugin *core.Plugin
+		nilErr bool
+	}{
+		"no placeholder":      {plugin: &core.Plugin{Endpoint: "/.git/config"}, nilErr: true},
+		"known placeholders":  {plugin: &core.Plugin{Endpoints: []string{"/{host}", "/{scheme}/{port}"}}, nilErr: true},
+		"unknown placeholder": {plugin: &core.Plugin{Endpoint: "/users/{user}/config"}, nilErr: false},
+		"empty placeholder":   {plugin: &core.Plugin{Endpoint: "/users/{}/config"}, nilErr: false},
+		"step placeholders":   {plugin: &core.Plugin{Steps: []*core.Step{{Endpoint: "/login/{host}"}, {Endpoint: "/admin?token={{token}}"}}}, nilErr: true},
+		"unknown in a step":   {plugin: &core.Plugin{Steps: []*core.Step{{Endpoint: "/login/{domain}"}}}, nilErr: false},
+		"environment":         {plugin: &core.Plugin{Endpoint: "/api/${API_VERSION}/{host}"}, nilErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.plugin.ValidateEndpoints()
+			if (err == nil) != tc.nilErr {
+				t.Errorf("expected a nil error: %v, got: %v", tc.nilErr, err)
+			}
+		})
+	}
+}
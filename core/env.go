@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// envVarRegex matches the ${NAME} references to environment variables
+var envVarRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Interpolate replaces the ${NAME} references in the endpoints, query strings, bodies and headers of the plugins,
+// and in the match and headers values of their checks, so secrets (eg. API tokens) don't have to be written in the signatures.
+// The values are looked up with lookup, usually os.LookupEnv.
+func (s *Signatures) Interpolate(lookup func(string) (string, bool)) error {
+	for _, plugin := range s.Plugins {
+		if err := plugin.Interpolate(lookup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Interpolate replaces the ${NAME} references of the plugin and its checks, it fails on the first variable which isn't set
+func (p *Plugin) Interpolate(lookup func(string) (string, bool)) error {
+	i := interpolator{lookup: lookup}
+	i.string(&p.Endpoint)
+	i.strings(p.Endpoints)
+	i.string(&p.QueryString)
+	i.strings(p.QueryStrings)
+	i.string(&p.Body)
+	i.strings(p.RequestHeaders)
+	if p.Request != nil {
+		i.string(&p.Request.Body)
+		i.strings(p.Request.Headers)
+	}
+	for _, step := range p.Steps {
+		i.string(&step.Endpoint)
+		i.string(&step.Body)
+		i.strings(step.Headers)
+	}
+	for _, check := range p.Checks {
+		i.strings(check.MustMatchOne)
+		i.strings(check.MustMatchAll)
+		i.strings(check.MustNotMatch)
+		i.strings(check.Headers)
+		i.strings(check.NoHeaders)
+	}
+	if i.missing != "" {
+		return fmt.Errorf("Environment variable %s is not set in %s plugin checks. Stopping execution", i.missing, p.ID())
+	}
+	return nil
+}
+
+// interpolator remembers the first variable which isn't set, the references to it are left as is
+type interpolator struct {
+	lookup  func(string) (string, bool)
+	missing string
+}
+
+func (i *interpolator) string(s *string) {
+	*s = envVarRegex.ReplaceAllStringFunc(*s, func(reference string) string {
+		name := envVarRegex.FindStringSubmatch(reference)[1]
+		value, ok := i.lookup(name)
+		if !ok {
+			if i.missing == "" {
+				i.missing = name
+			}
+			return reference
+		}
+		return value
+	})
+}
+
+func (i *interpolator) strings(s []string) {
+	for j := range s {
+		i.string(&s[j])
+	}
+}
@@ -0,0 +1,70 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"reflect"
+	"testing"
+)
+
+func TestPluginInterpolate(t *testing.T) {
+	env := map[string]string{"TOKEN": "s3cr3t", "HOST_ID": "42", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	var tests = map[string]struct {
+		plugin    *core.Plugin
+		expected  *core.Plugin
+		expectErr bool
+	}{
+		"endpoint and query string": {
+			plugin:   &core.Plugin{Endpoint: "/api/${HOST_ID}", QueryString: "token=${TOKEN}&empty=${EMPTY}"},
+			expected: &core.Plugin{Endpoint: "/api/42", QueryString: "token=s3cr3t&empty="},
+		},
+		"headers of the requests": {
+			plugin: &core.Plugin{
+				Endpoints:      []string{"/${HOST_ID}/a"},
+				RequestHeaders: []string{"Authorization: Bearer ${TOKEN}"},
+				Request:        &core.RequestOptions{Headers: []string{"X-Token: ${TOKEN}"}, Body: "id=${HOST_ID}"},
+				Steps:          []*core.Step{{Endpoint: "/${HOST_ID}", Headers: []string{"X-Csrf: {{csrf}}"}, Body: "token=${TOKEN}"}},
+			},
+			expected: &core.Plugin{
+				Endpoints:      []string{"/42/a"},
+				RequestHeaders: []string{"Authorization: Bearer s3cr3t"},
+				Request:        &core.RequestOptions{Headers: []string{"X-Token: s3cr3t"}, Body: "id=42"},
+				Steps:          []*core.Step{{Endpoint: "/42", Headers: []string{"X-Csrf: {{csrf}}"}, Body: "token=s3cr3t"}},
+			},
+		},
+		"match values of the checks": {
+			plugin:   &core.Plugin{Checks: []*core.Check{{Name: "Leak", MustMatchOne: []string{"${TOKEN}"}, NoHeaders: []string{"X-Host: ${HOST_ID}"}, Description: "${TOKEN}"}}},
+			expected: &core.Plugin{Checks: []*core.Check{{Name: "Leak", MustMatchOne: []string{"s3cr3t"}, NoHeaders: []string{"X-Host: 42"}, Description: "${TOKEN}"}}},
+		},
+		"not a reference": {
+			plugin:   &core.Plugin{Endpoint: "/price$5", QueryString: "a=$TOKEN&b=${1X}"},
+			expected: &core.Plugin{Endpoint: "/price$5", QueryString: "a=$TOKEN&b=${1X}"},
+		},
+		"variable not set": {
+			plugin:    &core.Plugin{Endpoint: "/${TOKEN}/${UNSET}", Checks: []*core.Check{{Name: "Leak"}}},
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.plugin.Interpolate(lookup)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("expected an error, got: %v", tt.plugin)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.plugin, tt.expected) {
+				t.Errorf("expected: %+v, got: %+v", tt.expected, tt.plugin)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"fmt"
+	"gochopchop/internal"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// EvidenceSnippetLength is the maximum length in bytes of the body snippet kept as evidence
+const EvidenceSnippetLength = 200
+
+// redacted replaces the secrets found in the evidence
+const redacted = "[REDACTED]"
+
+// secretPatterns are the obvious secrets redacted from the evidence, so the exports don't spread them.
+// The patterns with a group only redact the value of that group, eg. the value of a password field but not its name.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?(?:-----END [A-Z ]*PRIVATE KEY-----|$)`),
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/-]+=*)`),
+	regexp.MustCompile(`(?i)(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key)["']?\s*[:=]\s*["']?([^\s"'&,;<]+)`),
+}
+
+// Evidence is the part of the response body a check matched, to show what was actually seen
+type Evidence struct {
+	// Match is the term or the text of the regex found in the body
+	Match string `json:"match"`
+	// Offset is the position in bytes of the match in the body
+	Offset int `json:"offset"`
+	// Snippet is the body around the match, at most EvidenceSnippetLength bytes
+	Snippet string `json:"snippet"`
+}
+
+func (e *Evidence) String() string {
+	return fmt.Sprintf("%q at offset %d: %s", e.Match, e.Offset, e.Snippet)
+}
+
+// Evidence returns the first match of the body conditions of the check in the response, with the secrets redacted.
+// It is nil when the check has no body condition, eg. a check of the headers or the status code only.
+func (check *Check) Evidence(resp *internal.HTTPResponse) *Evidence {
+	start, end := check.bodyMatch(resp.Body)
+	if start < 0 {
+		return nil
+	}
+	return &Evidence{
+		Match:   Redact(resp.Body[start:end]),
+		Offset:  start,
+		Snippet: Redact(snippet(resp.Body, start, end)),
+	}
+}
+
+// bodyMatch returns the position of the first term, regex or reference file of the check found in the body, -1 if none
+func (check *Check) bodyMatch(body string) (int, int) {
+	terms := append(append(append([]string{}, check.MustMatchOne...), check.MustMatchAll...), check.Conditions.bodyTerms()...)
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+		if check.CaseInsensitive {
+			// the position is looked up in the body as is, its lowercase may be of another length
+			if loc := regexp.MustCompile("(?i)" + regexp.QuoteMeta(term)).FindStringIndex(body); loc != nil {
+				return loc[0], loc[1]
+			}
+		} else if i := strings.Index(body, term); i >= 0 {
+			return i, i + len(term)
+		}
+	}
+	patterns := append(append([]string{}, check.MatchRegex...), check.AllMatchRegex...)
+	compiled := append(append([]*regexp.Regexp{}, regexes(check.MatchRegex, check.matchRegex)...), regexes(check.AllMatchRegex, check.allMatchRegex)...)
+	for i := range patterns {
+		if compiled[i] == nil {
+			continue
+		}
+		if loc := compiled[i].FindStringIndex(body); loc != nil {
+			return loc[0], loc[1]
+		}
+	}
+	if check.MatchFileContent != "" {
+		if i := strings.Index(body, check.MatchFileContent); i >= 0 {
+			return i, i + len(check.MatchFileContent)
+		}
+	}
+	return -1, -1
+}
+
+// snippet returns the body around the match, cut to EvidenceSnippetLength bytes on rune boundaries
+func snippet(body string, start int, end int) string {
+	if end-start >= EvidenceSnippetLength {
+		end = start + EvidenceSnippetLength
+	} else {
+		margin := (EvidenceSnippetLength - (end - start)) / 2
+		from, to := start-margin, end+margin
+		if from < 0 {
+			to -= from
+			from = 0
+		}
+		if to > len(body) {
+			from -= to - len(body)
+			to = len(body)
+		}
+		if from < 0 {
+			from = 0
+		}
+		start, end = from, to
+	}
+	for start > 0 && !utf8.RuneStart(body[start]) {
+		start++
+	}
+	for end < len(body) && !utf8.RuneStart(body[end]) {
+		end--
+	}
+	return body[start:end]
+}
+
+// Redact replaces the obvious secrets of the text, such as private keys, AWS access keys, JWTs and passwords
+func Redact(text string) string {
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllStringFunc(text, func(secret string) string {
+			loc := pattern.FindStringSubmatchIndex(secret)
+			if len(loc) < 4 || loc[2] < 0 {
+				return redacted
+			}
+			return secret[:loc[2]] + redacted + secret[loc[3]:]
+		})
+	}
+	return text
+}
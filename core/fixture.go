@@ -0,0 +1,199 @@
+package core
+
+import (
+	"fmt"
+	"gochopchop/internal"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// fixtureBody is the body of the fixtures of the checks without a body condition
+const fixtureBody = "chopchop selftest"
+
+// Fixture is a response built to satisfy the conditions of a check, to make sure the check fires on what it looks for
+type Fixture struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// NewFixture returns the response the check should fire on. It fails for the conditions a response can't be derived from,
+// such as a regex which is not a literal, a JSON path, the TLS connection or a deviation from the baseline.
+func NewFixture(plugin *Plugin, check *Check) (*Fixture, error) {
+	for _, field := range []struct {
+		name string
+		set  bool
+	}{
+		{"steps", len(plugin.Steps) > 0},
+		{"default_credentials", plugin.DefaultCredentials != nil},
+		{"initial_status_code", check.InitialStatusCode != nil},
+		{"server_version", check.ServerVersion != nil},
+		{"json_match", check.JSONMatch != nil},
+		{"tls", check.TLS != nil},
+		{"mixed_content", check.MixedContent},
+		{"size_ratio", check.SizeRatio != 0},
+		{"conditions", check.Conditions != nil},
+	} {
+		if field.set {
+			return nil, fmt.Errorf("no fixture for %s", field.name)
+		}
+	}
+
+	fixture := &Fixture{StatusCode: fixtureStatusCode(check), Header: make(http.Header)}
+
+	// the strings to find in the body, the first one being enough for the match and match_regex lists
+	terms := append([]string{}, check.MustMatchAll...)
+	if len(check.MustMatchOne) > 0 {
+		terms = append(terms, check.MustMatchOne[0])
+	}
+	patterns := append([]string{}, check.AllMatchRegex...)
+	if len(check.MatchRegex) > 0 {
+		patterns = append(patterns, check.MatchRegex[0])
+	}
+	for _, pattern := range patterns {
+		literal, err := regexLiteral(pattern)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, literal)
+	}
+	if check.MatchFile != "" {
+		terms = append(terms, check.MatchFileContent)
+	}
+	fixture.Body = strings.Join(terms, "\n")
+	if check.BodyEquals != nil {
+		// the other body conditions are met by the exact body or can't be at all
+		fixture.Body = *check.BodyEquals
+		if check.TrimBody {
+			fixture.Body += "\n"
+		}
+	} else if check.EmptyBody || (check.MaxBodySize != nil && *check.MaxBodySize == 0) {
+		if fixture.Body != "" {
+			return nil, fmt.Errorf("no fixture for an empty body with body conditions")
+		}
+	} else if fixture.Body == "" {
+		fixture.Body = fixtureBody
+	}
+	if check.MinBodySize != nil && len(fixture.Body) < *check.MinBodySize {
+		fixture.Body += strings.Repeat(" ", *check.MinBodySize-len(fixture.Body))
+	}
+	if check.MaxBodySize != nil && len(fixture.Body) > *check.MaxBodySize {
+		return nil, fmt.Errorf("no fixture for a body of at most %d bytes", *check.MaxBodySize)
+	}
+
+	for _, header := range check.Headers {
+		key, value, anyValue := ParseHeaderCondition(header)
+		if anyValue {
+			value = fixtureBody
+		}
+		fixture.Header.Add(key, value)
+	}
+	for _, condition := range check.HeadersRegex {
+		key, pattern := splitHeaderRegex(condition)
+		literal, err := regexLiteral(pattern)
+		if err != nil {
+			return nil, err
+		}
+		fixture.Header.Add(key, literal)
+	}
+	if check.ContentType != "" {
+		fixture.Header.Set("Content-Type", fixtureMediaType(check.ContentType))
+	} else if check.NotContentType != "" && mediaTypeMatch("text/plain", check.NotContentType) {
+		fixture.Header.Set("Content-Type", "application/octet-stream")
+	} else if fixture.Header.Get("Content-Type") == "" {
+		fixture.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	if check.Cookie != nil {
+		fixture.Header.Add("Set-Cookie", fixtureCookie(check.Cookie))
+	}
+	if check.WWWAuthenticate != nil {
+		scheme := check.WWWAuthenticate.Scheme
+		if scheme == "" {
+			scheme = "Basic"
+		}
+		fixture.Header.Add("WWW-Authenticate", fmt.Sprintf("%s realm=%q", scheme, check.WWWAuthenticate.Realm))
+	}
+	return fixture, nil
+}
+
+// fixtureStatusCode returns the status code the check expects, 200 unless told otherwise
+func fixtureStatusCode(check *Check) int {
+	if check.StatusCode != nil {
+		return int(*check.StatusCode)
+	}
+	if len(check.StatusCodeIn) > 0 {
+		entry := strings.ToLower(strings.TrimSpace(check.StatusCodeIn[0]))
+		if strings.HasSuffix(entry, "xx") {
+			// eg. 200 for 2xx, the first code of the class that isn't excluded
+			for code := int(entry[0]-'0') * 100; code < int(entry[0]-'0')*100+100; code++ {
+				if check.StatusCodeNot == nil || int32(code) != *check.StatusCodeNot {
+					return code
+				}
+			}
+		}
+		var code int
+		fmt.Sscanf(entry, "%d", &code)
+		return code
+	}
+	if check.StatusCodeNot != nil && *check.StatusCodeNot == http.StatusOK {
+		return http.StatusCreated
+	}
+	return http.StatusOK
+}
+
+// fixtureMediaType completes the partial media types of content_type, eg. json or text/
+func fixtureMediaType(contentType string) string {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	switch {
+	case strings.HasSuffix(contentType, "/"):
+		return contentType + "plain"
+	case !strings.Contains(contentType, "/"):
+		return "application/" + contentType
+	}
+	return contentType
+}
+
+// fixtureCookie returns the Set-Cookie header of the cookie with the expected flags
+func fixtureCookie(cookie *CookieCheck) string {
+	header := cookie.Name + "=" + strings.ReplaceAll(fixtureBody, " ", "-") + "; Path=/"
+	if cookie.Secure != nil && *cookie.Secure {
+		header += "; Secure"
+	}
+	if cookie.HttpOnly != nil && *cookie.HttpOnly {
+		header += "; HttpOnly"
+	}
+	if cookie.SameSite != nil && *cookie.SameSite {
+		header += "; SameSite=Lax"
+	}
+	return header
+}
+
+// regexLiteral returns the string matched by a regex made of a literal only, eg. "Index of /" but not "version [0-9]+"
+func regexLiteral(pattern string) (string, error) {
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	literal, complete := regex.LiteralPrefix()
+	if !complete {
+		return "", fmt.Errorf("no fixture for the regex %q", pattern)
+	}
+	return literal, nil
+}
+
+// ServeHTTP answers any request with the fixture
+func (f *Fixture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for key, values := range f.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(f.StatusCode)
+	fmt.Fprint(w, f.Body)
+}
+
+// Response returns the fixture as the response of a fetcher
+func (f *Fixture) Response() *internal.HTTPResponse {
+	return &internal.HTTPResponse{StatusCode: f.StatusCode, Header: f.Header, Body: f.Body}
+}
@@ -0,0 +1,46 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"testing"
+)
+
+func TestNewFixture(t *testing.T) {
+	var tests = map[string]struct {
+		plugin *core.Plugin
+		check  *core.Check
+		nilErr bool
+	}{
+		"body terms":            {check: &core.Check{MustMatchAll: []string{"[core]", "bare"}, MustMatchOne: []string{"repositoryformatversion"}}, nilErr: true},
+		"status class":          {check: &core.Check{StatusCodeIn: []string{"2xx"}, StatusCodeNot: createInt32(200)}, nilErr: true},
+		"not 200":               {check: &core.Check{StatusCodeNot: createInt32(200)}, nilErr: true},
+		"headers":               {check: &core.Check{Headers: []string{"Server", "X-Powered-By:PHP"}, HeadersRegex: []string{"X-Debug: enabled"}}, nilErr: true},
+		"literal regex":         {check: &core.Check{MatchRegex: []string{`Index of /`}}, nilErr: true},
+		"content type":          {check: &core.Check{ContentType: "json", NotContentType: "html"}, nilErr: true},
+		"empty body":            {check: &core.Check{EmptyBody: true, StatusCode: createInt32(200)}, nilErr: true},
+		"body size":             {check: &core.Check{MinBodySize: createInt(100), NonEmptyBody: true}, nilErr: true},
+		"body equals":           {check: &core.Check{BodyEquals: createString("OK"), TrimBody: true, MustMatchOne: []string{"OK"}}, nilErr: true},
+		"cookie":                {check: &core.Check{Cookie: &core.CookieCheck{Name: "SID", Secure: createBool(false), HttpOnly: createBool(true)}}, nilErr: true},
+		"authentication":        {check: &core.Check{WWWAuthenticate: &core.WWWAuthenticateCheck{Scheme: "Basic", Realm: "Manager"}}, nilErr: true},
+		"regex":                 {check: &core.Check{MatchRegex: []string{"v[0-9]+"}}, nilErr: false},
+		"empty body with terms": {check: &core.Check{EmptyBody: true, MustMatchOne: []string{"a"}}, nilErr: false},
+		"tls":                   {check: &core.Check{TLS: &core.TLSCheck{}}, nilErr: false},
+		"steps":                 {plugin: &core.Plugin{Steps: []*core.Step{{}}}, check: &core.Check{}, nilErr: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			plugin := tc.plugin
+			if plugin == nil {
+				plugin = &core.Plugin{}
+			}
+			fixture, err := core.NewFixture(plugin, tc.check)
+			if (err == nil) != tc.nilErr {
+				t.Fatalf("expected a nil error: %v, got: %v", tc.nilErr, err)
+			}
+			if err == nil && !tc.check.Match(fixture.Response()) {
+				t.Errorf("expected the check to match its fixture %+v", fixture)
+			}
+		})
+	}
+}
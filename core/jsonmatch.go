@@ -0,0 +1,84 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// jsonPathSegment matches the .key, ['key'] and [index] segments of a JSONPath expression
+var jsonPathSegment = regexp.MustCompile(`^(?:\.([A-Za-z0-9_\-]+)|\['([^']*)'\]|\[(\d+)\])`)
+
+// JSONMatchCheck asserts the value at a JSONPath expression (eg. $.status or $.items[0]['name']) of a JSON response.
+// A body which is not JSON, or without a value at the path, does not match.
+type JSONMatchCheck struct {
+	Path  string      `yaml:"path"`
+	Value interface{} `yaml:"value"`
+	keys  []string
+}
+
+// Validate parses the path of the check
+func (j *JSONMatchCheck) Validate() error {
+	keys, err := ParseJSONPath(j.Path)
+	if err != nil {
+		return err
+	}
+	if j.Value == nil {
+		return fmt.Errorf("Missing value of json_match %s", j.Path)
+	}
+	j.keys = keys
+	return nil
+}
+
+// ParseJSONPath splits a JSONPath expression made of keys and array indexes, from the root $
+func ParseJSONPath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("Invalid JSONPath : %q. It must start with $", path)
+	}
+	var keys []string
+	rest := path[1:]
+	for rest != "" {
+		m := jsonPathSegment.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("Invalid JSONPath : %q. Only .key, ['key'] and [index] are supported", path)
+		}
+		keys = append(keys, m[1]+m[2]+m[3])
+		rest = rest[len(m[0]):]
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("Invalid JSONPath : %q. The root can't be matched", path)
+	}
+	return keys, nil
+}
+
+// Match reports whether the JSON body has the expected value at the path.
+// When it matches, the returned string describes the value.
+func (j *JSONMatchCheck) Match(header http.Header, body string) (bool, string) {
+	if !strings.Contains(strings.ToLower(header.Get("Content-Type")), "json") {
+		return false, "response is not JSON"
+	}
+	keys := j.keys
+	if keys == nil {
+		var err error
+		if keys, err = ParseJSONPath(j.Path); err != nil {
+			return false, err.Error()
+		}
+	}
+	value, err := jsonValue(body, keys, j.Path)
+	if err != nil {
+		return false, err.Error()
+	}
+	expected := fmt.Sprint(j.Value)
+	if value != expected {
+		return false, fmt.Sprintf("%s is %q", j.Path, value)
+	}
+	return true, fmt.Sprintf("%s is %q", j.Path, value)
+}
+
+func (j *JSONMatchCheck) Equals(check *JSONMatchCheck) bool {
+	if j == nil || check == nil {
+		return j == check
+	}
+	return j.Path == check.Path && fmt.Sprint(j.Value) == fmt.Sprint(check.Value)
+}
@@ -0,0 +1,67 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"net/http"
+	"testing"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	var tests = map[string]struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		"dotted keys":      {path: "$.data.status", want: []string{"data", "status"}},
+		"array index":      {path: "$.items[1].name", want: []string{"items", "1", "name"}},
+		"bracket key":      {path: "$['debug-mode'].enabled", want: []string{"debug-mode", "enabled"}},
+		"missing root":     {path: "status", wantErr: true},
+		"root only":        {path: "$", wantErr: true},
+		"unsupported":      {path: "$..status", wantErr: true},
+		"wildcard":         {path: "$.items[*]", wantErr: true},
+		"unclosed bracket": {path: "$['status", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			keys, err := core.ParseJSONPath(tc.path)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if !core.SliceStringEqual(keys, tc.want) {
+				t.Errorf("expected: %v, got: %v", tc.want, keys)
+			}
+		})
+	}
+}
+
+func TestCheckMatchJSON(t *testing.T) {
+	jsonHeader := http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}
+	body := `{"status": "debug", "version": 2, "features": [{"name": "actuator", "enabled": true}]}`
+	var tests = map[string]struct {
+		check  *core.JSONMatchCheck
+		header http.Header
+		body   string
+		want   bool
+	}{
+		"string value":        {check: &core.JSONMatchCheck{Path: "$.status", Value: "debug"}, header: jsonHeader, body: body, want: true},
+		"other string value":  {check: &core.JSONMatchCheck{Path: "$.status", Value: "production"}, header: jsonHeader, body: body, want: false},
+		"number value":        {check: &core.JSONMatchCheck{Path: "$.version", Value: 2}, header: jsonHeader, body: body, want: true},
+		"nested bool value":   {check: &core.JSONMatchCheck{Path: "$.features[0].enabled", Value: true}, header: jsonHeader, body: body, want: true},
+		"missing path":        {check: &core.JSONMatchCheck{Path: "$.features[3].enabled", Value: true}, header: jsonHeader, body: body, want: false},
+		"invalid JSON":        {check: &core.JSONMatchCheck{Path: "$.status", Value: "debug"}, header: jsonHeader, body: `{"status": "debug"`, want: false},
+		"not a JSON response": {check: &core.JSONMatchCheck{Path: "$.status", Value: "debug"}, header: http.Header{"Content-Type": []string{"text/html"}}, body: body, want: false},
+		"problem+json":        {check: &core.JSONMatchCheck{Path: "$.status", Value: "debug"}, header: http.Header{"Content-Type": []string{"application/problem+json"}}, body: body, want: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			check := &core.Check{JSONMatch: tc.check}
+			have := check.Match(&internal.HTTPResponse{StatusCode: 200, Header: tc.header, Body: tc.body})
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
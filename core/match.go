@@ -0,0 +1,522 @@
+package core
+
+import (
+	"fmt"
+	"gochopchop/internal"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// ConditionResult is the outcome of a single condition of a check against a response
+type ConditionResult struct {
+	Condition string
+	Passed    bool
+	Detail    string
+}
+
+// Match analyses the HTTP Request
+// a match means that all the conditions of the check have been met.
+// It stops at the first failing condition, the cheap ones on the status code and headers being looked at before the body.
+// Evaluate must report the same outcome, condition by condition.
+func (check *Check) Match(resp *internal.HTTPResponse) bool {
+	// status code must match
+	if check.StatusCode != nil && int32(resp.StatusCode) != *check.StatusCode {
+		return false
+	}
+	if check.StatusCodeNot != nil && int32(resp.StatusCode) == *check.StatusCodeNot {
+		return false
+	}
+	if check.InitialStatusCode != nil {
+		initial := resp.StatusCode
+		if resp.InitialStatusCode != 0 {
+			initial = resp.InitialStatusCode
+		}
+		if int32(initial) != *check.InitialStatusCode {
+			return false
+		}
+	}
+	if len(check.StatusCodeIn) > 0 && !statusCodeIn(resp.StatusCode, check.StatusCodeIn) {
+		return false
+	}
+
+	// the media type must be the expected one
+	if check.ContentType != "" || check.NotContentType != "" {
+		mediaType := MediaType(resp.Header)
+		if check.ContentType != "" && !mediaTypeMatch(mediaType, check.ContentType) {
+			return false
+		}
+		if check.NotContentType != "" && mediaTypeMatch(mediaType, check.NotContentType) {
+			return false
+		}
+	}
+
+	// the headers
+	for _, header := range check.Headers {
+		if !headerFound(resp.Header, header) {
+			return false
+		}
+	}
+	for _, header := range check.NoHeaders {
+		if headerFound(resp.Header, header) {
+			return false
+		}
+	}
+	for i, regex := range regexes(headerPatterns(check.HeadersRegex), check.headersRegex) {
+		if ok, _ := headerRegexMatch(resp.Header, check.HeadersRegex[i], regex); !ok {
+			return false
+		}
+	}
+	for i, regex := range regexes(headerPatterns(check.NoHeadersRegex), check.noHeadersRegex) {
+		if ok, _ := headerRegexMatch(resp.Header, check.NoHeadersRegex[i], regex); ok {
+			return false
+		}
+	}
+	if check.Cookie != nil {
+		if ok, _ := check.Cookie.Match(resp.Header); !ok {
+			return false
+		}
+	}
+	if check.WWWAuthenticate != nil {
+		if ok, _ := check.WWWAuthenticate.Match(resp.Header); !ok {
+			return false
+		}
+	}
+	if check.ServerVersion != nil {
+		if ok, _ := check.ServerVersion.Match(resp.Header); !ok {
+			return false
+		}
+	}
+
+	// the body size
+	compared := check.comparedBody(resp.Body)
+	if check.EmptyBody && len(compared) != 0 {
+		return false
+	}
+	if check.NonEmptyBody && len(compared) == 0 {
+		return false
+	}
+	if check.MinBodySize != nil && len(resp.Body) < *check.MinBodySize {
+		return false
+	}
+	if check.MaxBodySize != nil && len(resp.Body) > *check.MaxBodySize {
+		return false
+	}
+	if check.BodyEquals != nil {
+		if check.CaseInsensitive && !strings.EqualFold(compared, *check.BodyEquals) {
+			return false
+		}
+		if !check.CaseInsensitive && compared != *check.BodyEquals {
+			return false
+		}
+	}
+
+	// the body is lowercased once for the case-insensitive checks
+	body := resp.Body
+	term := func(match string) string { return match }
+	if check.CaseInsensitive {
+		body = strings.ToLower(body)
+		term = strings.ToLower
+	}
+	for _, match := range check.MustMatchAll {
+		if !strings.Contains(body, term(match)) {
+			return false
+		}
+	}
+	if len(check.MustMatchOne) > 0 {
+		found := false
+		for _, match := range check.MustMatchOne {
+			if strings.Contains(body, term(match)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, match := range check.MustNotMatch {
+		if strings.Contains(body, term(match)) {
+			return false
+		}
+	}
+	if check.MatchFile != "" && !strings.Contains(resp.Body, check.MatchFileContent) {
+		return false
+	}
+
+	// the regexes
+	for _, regex := range regexes(check.AllMatchRegex, check.allMatchRegex) {
+		if !regexMatch(regex, resp.Body) {
+			return false
+		}
+	}
+	if len(check.MatchRegex) > 0 {
+		found := false
+		for _, regex := range regexes(check.MatchRegex, check.matchRegex) {
+			if regexMatch(regex, resp.Body) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, regex := range regexes(check.NoMatchRegex, check.noMatchRegex) {
+		if regexMatch(regex, resp.Body) {
+			return false
+		}
+	}
+
+	// the costly conditions, parsing the body or comparing it to the baseline
+	if check.Conditions != nil && !check.Conditions.Match(resp, check.CaseInsensitive) {
+		return false
+	}
+	if check.JSONMatch != nil {
+		if ok, _ := check.JSONMatch.Match(resp.Header, resp.Body); !ok {
+			return false
+		}
+	}
+	if check.SizeRatio != 0 {
+		if ok, _ := sizeDeviation(resp, check.SizeRatio); !ok {
+			return false
+		}
+	}
+	if check.MixedContent {
+		if ok, _ := mixedContent(resp); !ok {
+			return false
+		}
+	}
+	if check.TLS != nil {
+		if ok, _ := check.TLS.Match(resp.TLS); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate reports the outcome of every condition of the check against the response.
+// Unlike Match it does not stop at the first failing condition, so it is only used to debug a check (run-check, debug logs).
+func (check *Check) Evaluate(resp *internal.HTTPResponse) []ConditionResult {
+	var results []ConditionResult
+	add := func(condition string, passed bool, detail string) {
+		results = append(results, ConditionResult{Condition: condition, Passed: passed, Detail: detail})
+	}
+
+	// status code must match
+	if check.StatusCode != nil {
+		add(fmt.Sprintf("status_code %d", *check.StatusCode), int32(resp.StatusCode) == *check.StatusCode, fmt.Sprintf("got %d", resp.StatusCode))
+	}
+	if check.StatusCodeNot != nil {
+		add(fmt.Sprintf("status_code_not %d", *check.StatusCodeNot), int32(resp.StatusCode) != *check.StatusCodeNot, fmt.Sprintf("got %d", resp.StatusCode))
+	}
+	if check.InitialStatusCode != nil {
+		initial := resp.StatusCode
+		if resp.InitialStatusCode != 0 {
+			initial = resp.InitialStatusCode
+		}
+		add(fmt.Sprintf("initial_status_code %d", *check.InitialStatusCode), int32(initial) == *check.InitialStatusCode, fmt.Sprintf("got %d", initial))
+	}
+	if len(check.StatusCodeIn) > 0 {
+		add(fmt.Sprintf("status_code_in %s", strings.Join(check.StatusCodeIn, ", ")), statusCodeIn(resp.StatusCode, check.StatusCodeIn), fmt.Sprintf("got %d", resp.StatusCode))
+	}
+
+	// the media type must be the expected one, before the body is looked at
+	if check.ContentType != "" || check.NotContentType != "" {
+		mediaType := MediaType(resp.Header)
+		detail := fmt.Sprintf("got %q", mediaType)
+		if check.ContentType != "" {
+			add(fmt.Sprintf("content_type %q", check.ContentType), mediaTypeMatch(mediaType, check.ContentType), detail)
+		}
+		if check.NotContentType != "" {
+			add(fmt.Sprintf("not_content_type %q", check.NotContentType), !mediaTypeMatch(mediaType, check.NotContentType), detail)
+		}
+	}
+
+	// the body is lowercased once for the case-insensitive checks
+	body := resp.Body
+	term := func(match string) string { return match }
+	if check.CaseInsensitive {
+		body = strings.ToLower(body)
+		term = strings.ToLower
+	}
+
+	// all element must be found
+	for i, match := range check.MustMatchAll {
+		add(fmt.Sprintf("all_match[%d] %q", i, match), strings.Contains(body, term(match)), "")
+	}
+
+	// one element must be found
+	if len(check.MustMatchOne) > 0 {
+		found := false
+		var terms []string
+		for _, match := range check.MustMatchOne {
+			if strings.Contains(body, term(match)) {
+				found = true
+				terms = append(terms, fmt.Sprintf("%q found", match))
+			} else {
+				terms = append(terms, fmt.Sprintf("%q not found", match))
+			}
+		}
+		add("match (one of)", found, strings.Join(terms, ", "))
+	}
+
+	// no element should match
+	for i, match := range check.MustNotMatch {
+		add(fmt.Sprintf("no_match[%d] %q", i, match), !strings.Contains(body, term(match)), "")
+	}
+
+	// all the regexes must match
+	for i, regex := range regexes(check.AllMatchRegex, check.allMatchRegex) {
+		add(fmt.Sprintf("all_match_regex[%d] %q", i, check.AllMatchRegex[i]), regexMatch(regex, resp.Body), "")
+	}
+
+	// one of the regexes must match
+	if len(check.MatchRegex) > 0 {
+		found := false
+		var terms []string
+		for i, regex := range regexes(check.MatchRegex, check.matchRegex) {
+			if regexMatch(regex, resp.Body) {
+				found = true
+				terms = append(terms, fmt.Sprintf("%q matched", check.MatchRegex[i]))
+			} else {
+				terms = append(terms, fmt.Sprintf("%q not matched", check.MatchRegex[i]))
+			}
+		}
+		add("match_regex (one of)", found, strings.Join(terms, ", "))
+	}
+
+	// no regex should match
+	for i, regex := range regexes(check.NoMatchRegex, check.noMatchRegex) {
+		add(fmt.Sprintf("no_match_regex[%d] %q", i, check.NoMatchRegex[i]), !regexMatch(regex, resp.Body), "")
+	}
+
+	// must contain all these headers
+	for i, header := range check.Headers {
+		add(fmt.Sprintf("headers[%d] %q", i, header), headerFound(resp.Header, header), "")
+	}
+
+	// must not contain these headers
+	for i, header := range check.NoHeaders {
+		add(fmt.Sprintf("no_headers[%d] %q", i, header), !headerFound(resp.Header, header), "")
+	}
+
+	// a value of these headers must match the regex
+	for i, regex := range regexes(headerPatterns(check.HeadersRegex), check.headersRegex) {
+		ok, detail := headerRegexMatch(resp.Header, check.HeadersRegex[i], regex)
+		add(fmt.Sprintf("headers_regex[%d] %q", i, check.HeadersRegex[i]), ok, detail)
+	}
+
+	// no value of these headers should match the regex, a missing header never matches
+	for i, regex := range regexes(headerPatterns(check.NoHeadersRegex), check.noHeadersRegex) {
+		ok, detail := headerRegexMatch(resp.Header, check.NoHeadersRegex[i], regex)
+		add(fmt.Sprintf("no_headers_regex[%d] %q", i, check.NoHeadersRegex[i]), !ok, detail)
+	}
+
+	// the content of the reference file must be found
+	if check.MatchFile != "" {
+		add(fmt.Sprintf("match_file %s", check.MatchFile), strings.Contains(resp.Body, check.MatchFileContent), "")
+	}
+
+	// body must be empty or not, or be exactly the expected one
+	compared := check.comparedBody(resp.Body)
+	if check.EmptyBody {
+		add("empty_body", len(compared) == 0, fmt.Sprintf("body is %d bytes", len(compared)))
+	}
+	if check.NonEmptyBody {
+		add("non_empty_body", len(compared) != 0, fmt.Sprintf("body is %d bytes", len(compared)))
+	}
+	if check.BodyEquals != nil {
+		equal := compared == *check.BodyEquals
+		if check.CaseInsensitive {
+			equal = strings.EqualFold(compared, *check.BodyEquals)
+		}
+		add(fmt.Sprintf("body_equals %q", *check.BodyEquals), equal, fmt.Sprintf("body is %d bytes", len(compared)))
+	}
+
+	// body size must be within the bounds
+	if check.MinBodySize != nil {
+		add(fmt.Sprintf("min_body_size %d", *check.MinBodySize), len(resp.Body) >= *check.MinBodySize, fmt.Sprintf("body is %d bytes", len(resp.Body)))
+	}
+	if check.MaxBodySize != nil {
+		add(fmt.Sprintf("max_body_size %d", *check.MaxBodySize), len(resp.Body) <= *check.MaxBodySize, fmt.Sprintf("body is %d bytes", len(resp.Body)))
+	}
+
+	// the named cookie must be set with the expected flags
+	if check.Cookie != nil {
+		ok, detail := check.Cookie.Match(resp.Header)
+		add(fmt.Sprintf("cookie %s", check.Cookie.Name), ok, detail)
+	}
+
+	// the response must ask for the expected authentication
+	if check.WWWAuthenticate != nil {
+		ok, detail := check.WWWAuthenticate.Match(resp.Header)
+		add("www_authenticate", ok, detail)
+	}
+
+	// the version announced by the Server header must satisfy the constraint
+	if check.ServerVersion != nil {
+		ok, detail := check.ServerVersion.Match(resp.Header)
+		add(fmt.Sprintf("server_version %s", check.ServerVersion.Version), ok, detail)
+	}
+
+	// the HTTPS page must load http:// resources
+	if check.MixedContent {
+		ok, detail := mixedContent(resp)
+		add("mixed_content", ok, detail)
+	}
+
+	// the JSON body must have the expected value
+	if check.JSONMatch != nil {
+		ok, detail := check.JSONMatch.Match(resp.Header, resp.Body)
+		add(fmt.Sprintf("json_match %s", check.JSONMatch.Path), ok, detail)
+	}
+
+	// the body size must deviate from the baseline of the host
+	if check.SizeRatio != 0 {
+		ok, detail := sizeDeviation(resp, check.SizeRatio)
+		add(fmt.Sprintf("size_ratio %g", check.SizeRatio), ok, detail)
+	}
+
+	// the tree of conditions must be satisfied
+	if check.Conditions != nil {
+		add(fmt.Sprintf("conditions %s", check.Conditions), check.Conditions.Match(resp, check.CaseInsensitive), "")
+	}
+
+	// the TLS connection must be weak
+	if check.TLS != nil {
+		ok, detail := check.TLS.Match(resp.TLS)
+		add("tls", ok, detail)
+	}
+	return results
+}
+
+// comparedBody returns the body compared by empty_body, non_empty_body and body_equals, without its trailing whitespace with trim_body
+func (check *Check) comparedBody(body string) string {
+	if check.TrimBody {
+		return strings.TrimRight(body, " \t\r\n")
+	}
+	return body
+}
+
+// MediaType returns the lowercased media type of the Content-Type header, without its parameters (eg. charset).
+// It is empty when the header is missing.
+func MediaType(header http.Header) string {
+	contentType := header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+}
+
+// mediaTypeMatch tells whether the media type contains the expected one, eg. "text/" or "json".
+// A missing media type never matches.
+func mediaTypeMatch(mediaType string, expected string) bool {
+	return mediaType != "" && strings.Contains(mediaType, strings.ToLower(strings.TrimSpace(expected)))
+}
+
+// ParseHeaderCondition splits a KEY:VALUE header condition of a check.
+// A missing or * value stands for any value, the header then only has to be present.
+func ParseHeaderCondition(condition string) (key string, value string, anyValue bool) {
+	parts := strings.SplitN(condition, ":", 2)
+	key = strings.TrimSpace(parts[0])
+	if len(parts) == 1 {
+		return key, "", true
+	}
+	value = strings.TrimSpace(parts[1])
+	return key, value, value == "" || value == "*"
+}
+
+// headerFound tells whether the response has the header of the condition, with a value containing the expected one
+func headerFound(header http.Header, condition string) bool {
+	key, value, anyValue := ParseHeaderCondition(condition)
+	values := headerValues(header, key)
+	if anyValue {
+		return len(values) > 0
+	}
+	for _, v := range values {
+		if strings.Contains(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerRegexMatch tells whether a value of the header of the KEY: REGEX condition matches the regex
+func headerRegexMatch(header http.Header, condition string, regex *regexp.Regexp) (bool, string) {
+	key, _ := splitHeaderRegex(condition)
+	values := headerValues(header, key)
+	if len(values) == 0 {
+		return false, fmt.Sprintf("no %s header", key)
+	}
+	for _, value := range values {
+		if regexMatch(regex, value) {
+			return true, fmt.Sprintf("%s: %s", key, value)
+		}
+	}
+	return false, fmt.Sprintf("%s: %s", key, strings.Join(values, ", "))
+}
+
+func headerValues(header http.Header, key string) []string {
+	values := header.Values(key)
+	if len(values) == 0 {
+		// headers built by hand may not be canonicalized
+		values = header[key]
+	}
+	return values
+}
+
+func mixedContent(resp *internal.HTTPResponse) (bool, string) {
+	// the url is unknown when the response doesn't come from the network, the page is then assumed to be HTTPS
+	if resp.URL != "" && !strings.HasPrefix(strings.ToLower(resp.URL), "https://") {
+		return false, "page is not served over HTTPS"
+	}
+	resources := MixedContent(resp.Body)
+	if len(resources) == 0 {
+		return false, "no http:// resource"
+	}
+	return true, fmt.Sprintf("mixed content: %s", strings.Join(resources, ", "))
+}
+
+// Details returns a human readable explanation of what triggered the check.
+// It only makes sense for checks that matched the response.
+func (check *Check) Details(resp *internal.HTTPResponse) string {
+	var details []string
+	if check.Cookie != nil {
+		if _, detail := check.Cookie.Match(resp.Header); detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if check.WWWAuthenticate != nil {
+		if _, detail := check.WWWAuthenticate.Match(resp.Header); detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if check.ServerVersion != nil {
+		if _, detail := check.ServerVersion.Match(resp.Header); detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if check.MixedContent {
+		if ok, detail := mixedContent(resp); ok {
+			details = append(details, detail)
+		}
+	}
+	if check.JSONMatch != nil {
+		if ok, detail := check.JSONMatch.Match(resp.Header, resp.Body); ok {
+			details = append(details, detail)
+		}
+	}
+	if check.SizeRatio != 0 {
+		if ok, detail := sizeDeviation(resp, check.SizeRatio); ok {
+			details = append(details, detail)
+		}
+	}
+	if check.TLS != nil {
+		if ok, detail := check.TLS.Match(resp.TLS); ok {
+			details = append(details, detail)
+		}
+	}
+	return strings.Join(details, "; ")
+}
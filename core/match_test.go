@@ -0,0 +1,301 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"net/http"
+	"testing"
+)
+
+func TestCheckMatch(t *testing.T) {
+	var tests = map[string]struct {
+		check *core.Check
+		resp  *internal.HTTPResponse
+		want  bool
+	}{
+		"Empty body matches": {
+			check: &core.Check{StatusCode: createInt32(200), EmptyBody: true},
+			resp:  &internal.HTTPResponse{StatusCode: 200},
+			want:  true,
+		},
+		"Empty body does not match": {
+			check: &core.Check{EmptyBody: true},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "content"},
+			want:  false,
+		},
+		"Match file content found": {
+			check: &core.Check{MatchFile: "backup.sql", MatchFileContent: "CREATE TABLE users"},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "-- dump\nCREATE TABLE users (id int);"},
+			want:  true,
+		},
+		"Match file content not found": {
+			check: &core.Check{MatchFile: "backup.sql", MatchFileContent: "CREATE TABLE users"},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "<html>Not found</html>"},
+			want:  false,
+		},
+		"Non empty body matches": {
+			check: &core.Check{NonEmptyBody: true},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "content"},
+			want:  true,
+		},
+		"Non empty body does not match": {
+			check: &core.Check{NonEmptyBody: true},
+			resp:  &internal.HTTPResponse{StatusCode: 200},
+			want:  false,
+		},
+		"Body size within the bounds": {
+			check: &core.Check{MinBodySize: createInt(5), MaxBodySize: createInt(10)},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "content"},
+			want:  true,
+		},
+		"Body smaller than the min size": {
+			check: &core.Check{MinBodySize: createInt(10)},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "content"},
+			want:  false,
+		},
+		"Body larger than the max size": {
+			check: &core.Check{MaxBodySize: createInt(5)},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "content"},
+			want:  false,
+		},
+		"Empty 200 with a max size of 0": {
+			check: &core.Check{StatusCode: createInt32(200), MaxBodySize: createInt(0)},
+			resp:  &internal.HTTPResponse{StatusCode: 200},
+			want:  true,
+		},
+		"Case-sensitive match by default": {
+			check: &core.Check{MustMatchOne: []string{"admin"}},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "<h1>ADMIN Panel</h1>"},
+			want:  false,
+		},
+		"Case-insensitive match": {
+			check: &core.Check{MustMatchOne: []string{"admin"}, CaseInsensitive: true},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "<h1>ADMIN Panel</h1>"},
+			want:  true,
+		},
+		"Case-insensitive all match": {
+			check: &core.Check{MustMatchAll: []string{"Admin", "PANEL"}, CaseInsensitive: true},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "<h1>admin panel</h1>"},
+			want:  true,
+		},
+		"Case-insensitive no match": {
+			check: &core.Check{MustNotMatch: []string{"Login"}, CaseInsensitive: true},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "<h1>Admin LOGIN</h1>"},
+			want:  false,
+		},
+		"Body equals": {
+			check: &core.Check{StatusCode: createInt32(200), BodyEquals: createString("OK")},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "OK"},
+			want:  true,
+		},
+		"Body containing more than the expected one": {
+			check: &core.Check{BodyEquals: createString("OK")},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "OK, logged in"},
+			want:  false,
+		},
+		"Body equals with a trailing newline": {
+			check: &core.Check{BodyEquals: createString("OK")},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "OK\n"},
+			want:  false,
+		},
+		"Body equals trimmed": {
+			check: &core.Check{BodyEquals: createString("OK"), TrimBody: true},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "OK \r\n"},
+			want:  true,
+		},
+		"Body equals case-insensitive": {
+			check: &core.Check{BodyEquals: createString("ok"), CaseInsensitive: true},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "OK"},
+			want:  true,
+		},
+		"Whitespace body is not empty": {
+			check: &core.Check{EmptyBody: true},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: "\n"},
+			want:  false,
+		},
+		"Whitespace body is empty trimmed": {
+			check: &core.Check{EmptyBody: true, TrimBody: true},
+			resp:  &internal.HTTPResponse{StatusCode: 200, Body: " \n"},
+			want:  true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := tc.check.Match(tc.resp)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+			// Evaluate must agree with the short-circuiting Match
+			evaluated := true
+			for _, result := range tc.check.Evaluate(tc.resp) {
+				evaluated = evaluated && result.Passed
+			}
+			if evaluated != tc.want {
+				t.Errorf("expected Evaluate to agree: %v, got: %v", tc.want, evaluated)
+			}
+		})
+	}
+}
+
+func createInt(x int) *int {
+	return &x
+}
+
+func createInt32(x int32) *int32 {
+	return &x
+}
+
+func createString(s string) *string {
+	return &s
+}
+
+func TestCheckEvaluate(t *testing.T) {
+	check := &core.Check{
+		StatusCode:   createInt32(200),
+		MustMatchAll: []string{"MATCHONE", "MATCHTHREE"},
+		MustNotMatch: []string{"NOTMATCH"},
+	}
+	resp := &internal.HTTPResponse{StatusCode: 200, Body: "MATCHONE MATCHTWO"}
+
+	want := []bool{true, true, false, true}
+	results := check.Evaluate(resp)
+	if len(results) != len(want) {
+		t.Fatalf("expected %d conditions, got: %v", len(want), results)
+	}
+	for i, result := range results {
+		if result.Passed != want[i] {
+			t.Errorf("condition %s: expected: %v, got: %v", result.Condition, want[i], result.Passed)
+		}
+	}
+}
+
+func TestCheckMatchHeaders(t *testing.T) {
+	resp := &internal.HTTPResponse{Header: http.Header{
+		"X-Powered-By": []string{"Express"},
+		"X-Debug":      []string{"1"},
+		"Location":     []string{"http://foobar:8080/login"},
+	}}
+	var tests = map[string]struct {
+		check *core.Check
+		want  bool
+	}{
+		"header present":                 {check: &core.Check{Headers: []string{"X-Debug"}}, want: true},
+		"header present with wildcard":   {check: &core.Check{Headers: []string{"X-Debug:*"}}, want: true},
+		"header missing":                 {check: &core.Check{Headers: []string{"X-Frame-Options"}}, want: false},
+		"header value found":             {check: &core.Check{Headers: []string{"X-Powered-By: Express"}}, want: true},
+		"header value with colon":        {check: &core.Check{Headers: []string{"Location:foobar:8080"}}, want: true},
+		"header key case-insensitive":    {check: &core.Check{Headers: []string{"x-powered-by:Express"}}, want: true},
+		"absent header is absent":        {check: &core.Check{NoHeaders: []string{"X-AspNet-Version"}}, want: true},
+		"present header is not absent":   {check: &core.Check{NoHeaders: []string{"X-Debug"}}, want: false},
+		"wildcard on present header":     {check: &core.Check{NoHeaders: []string{"X-Debug:*"}}, want: false},
+		"wildcard on absent header":      {check: &core.Check{NoHeaders: []string{"X-AspNet-Version:*"}}, want: true},
+		"empty value on present header":  {check: &core.Check{NoHeaders: []string{"X-Debug:"}}, want: false},
+		"value not contained":            {check: &core.Check{NoHeaders: []string{"X-Powered-By:PHP"}}, want: true},
+		"value contained":                {check: &core.Check{NoHeaders: []string{"X-Powered-By: Express"}}, want: false},
+		"value of absent header":         {check: &core.Check{NoHeaders: []string{"Server:Apache"}}, want: true},
+		"key case-insensitive in absent": {check: &core.Check{NoHeaders: []string{"x-debug"}}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := tc.check.Match(resp)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestCheckMatchContentType(t *testing.T) {
+	var tests = map[string]struct {
+		contentType string
+		check       *core.Check
+		want        bool
+	}{
+		"same media type":            {contentType: "text/plain", check: &core.Check{ContentType: "text/plain"}, want: true},
+		"charset ignored":            {contentType: "text/plain; charset=utf-8", check: &core.Check{ContentType: "text/plain"}, want: true},
+		"case-insensitive":           {contentType: "Text/Plain", check: &core.Check{ContentType: "text/PLAIN"}, want: true},
+		"prefix":                     {contentType: "text/plain", check: &core.Check{ContentType: "text/"}, want: true},
+		"substring":                  {contentType: "application/vnd.api+json", check: &core.Check{ContentType: "json"}, want: true},
+		"other media type":           {contentType: "text/html; charset=utf-8", check: &core.Check{ContentType: "text/plain"}, want: false},
+		"missing header":             {contentType: "", check: &core.Check{ContentType: "text/plain"}, want: false},
+		"soft 404 excluded":          {contentType: "text/html", check: &core.Check{NotContentType: "html"}, want: false},
+		"text not excluded":          {contentType: "text/plain", check: &core.Check{NotContentType: "html"}, want: true},
+		"missing header not matched": {contentType: "", check: &core.Check{NotContentType: "html"}, want: true},
+		"malformed parameters":       {contentType: "text/plain; charset", check: &core.Check{ContentType: "text/plain"}, want: true},
+		"both": {
+			contentType: "text/plain",
+			check:       &core.Check{ContentType: "text/", NotContentType: "html", MustMatchOne: []string{"[core]"}},
+			want:        true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			if tc.contentType != "" {
+				header.Set("Content-Type", tc.contentType)
+			}
+			resp := &internal.HTTPResponse{StatusCode: 200, Header: header, Body: "[core]"}
+			have := tc.check.Match(resp)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestParseHeaderCondition(t *testing.T) {
+	var tests = map[string]struct {
+		condition string
+		key       string
+		value     string
+		anyValue  bool
+	}{
+		"key and value":       {condition: "Server:Apache", key: "Server", value: "Apache"},
+		"surrounding spaces":  {condition: " X-Powered-By :  PHP ", key: "X-Powered-By", value: "PHP"},
+		"url value":           {condition: "Location:https://example.com", key: "Location", value: "https://example.com"},
+		"url value with port": {condition: "Location: https://example.com:8443/login", key: "Location", value: "https://example.com:8443/login"},
+		"date value":          {condition: "Date: Tue, 15 Nov 1994 08:12:31 GMT", key: "Date", value: "Tue, 15 Nov 1994 08:12:31 GMT"},
+		"key only":            {condition: "X-Debug", key: "X-Debug", anyValue: true},
+		"wildcard":            {condition: "X-Debug: *", key: "X-Debug", value: "*", anyValue: true},
+		"empty value":         {condition: "X-Debug:", key: "X-Debug", anyValue: true},
+		"value of colons":     {condition: "X-Sep:::", key: "X-Sep", value: "::"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			key, value, anyValue := core.ParseHeaderCondition(tc.condition)
+			if key != tc.key || value != tc.value || anyValue != tc.anyValue {
+				t.Errorf("expected: %q %q %v, got: %q %q %v", tc.key, tc.value, tc.anyValue, key, value, anyValue)
+			}
+		})
+	}
+}
+
+func TestCheckMatchHeaderValuesWithColons(t *testing.T) {
+	resp := &internal.HTTPResponse{Header: http.Header{
+		"Location": []string{"https://example.com:8443/login?next=/admin"},
+		"Date":     []string{"Tue, 15 Nov 1994 08:12:31 GMT"},
+	}}
+	var tests = map[string]struct {
+		check *core.Check
+		want  bool
+	}{
+		"location with port":       {check: &core.Check{Headers: []string{"Location:https://example.com:8443"}}, want: true},
+		"location with other port": {check: &core.Check{Headers: []string{"Location: https://example.com:9443"}}, want: false},
+		"date with time":           {check: &core.Check{Headers: []string{"Date: 08:12:31"}}, want: true},
+		"absent location value":    {check: &core.Check{NoHeaders: []string{"Location: http://example.com:80"}}, want: true},
+		"present location value":   {check: &core.Check{NoHeaders: []string{"Location:https://example.com:8443/"}}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := tc.check.Match(resp)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
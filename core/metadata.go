@@ -0,0 +1,35 @@
+package core
+
+import "time"
+
+// Metadata describes the run the findings come from, so the archived exports are self-describing
+type Metadata struct {
+	Version   string    `json:"version"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	// Targets is the number of scanned urls
+	Targets        int      `json:"targets"`
+	SignatureFiles []string `json:"signatureFiles,omitempty"`
+	// the severity thresholds and filters of the command line
+	MaxSeverity    string `json:"maxSeverity,omitempty"`
+	WarnSeverity   string `json:"warnSeverity,omitempty"`
+	MinSeverity    string `json:"minSeverity,omitempty"`
+	SeverityFilter string `json:"severityFilter,omitempty"`
+	// RiskScores are the scores of the hosts, from the riskiest to the safest, when --risk-score is set
+	RiskScores []HostRisk `json:"riskScores,omitempty"`
+}
+
+// NewMetadata describes a scan of the configuration with the signatures, started at begin and ending now
+func NewMetadata(config *Config, signatures *Signatures, begin time.Time) Metadata {
+	return Metadata{
+		Version:        Version,
+		StartTime:      begin.UTC(),
+		EndTime:        time.Now().UTC(),
+		Targets:        len(config.Urls),
+		SignatureFiles: signatures.Files,
+		MaxSeverity:    config.MaxSeverity,
+		WarnSeverity:   config.WarnSeverity,
+		MinSeverity:    config.MinSeverity,
+		SeverityFilter: config.SeverityFilter,
+	}
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// mixedContentAttributes lists, per tag, the attributes loading a resource into the page
+var mixedContentAttributes = map[string][]string{
+	"script": {"src"},
+	"img":    {"src", "srcset"},
+	"iframe": {"src"},
+	"frame":  {"src"},
+	"audio":  {"src"},
+	"video":  {"src", "poster"},
+	"source": {"src", "srcset"},
+	"track":  {"src"},
+	"embed":  {"src"},
+	"object": {"data"},
+	"link":   {"href"},
+}
+
+// linkResourceRels are the rel values of the link tags actually loading a resource
+var linkResourceRels = []string{"stylesheet", "icon", "preload", "prefetch", "modulepreload", "manifest"}
+
+// MixedContent parses the HTML body and returns the http:// resources it loads, without duplicates.
+// Links to other pages (eg. <a href>, <link rel="canonical">) are not resources and are ignored.
+func MixedContent(body string) []string {
+	var resources []string
+	seen := make(map[string]bool)
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return resources
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+		token := tokenizer.Token()
+		attributes, ok := mixedContentAttributes[token.Data]
+		if !ok || (token.Data == "link" && !loadsResource(token)) {
+			continue
+		}
+		for _, attr := range token.Attr {
+			if !contains(attributes, attr.Key) {
+				continue
+			}
+			for _, candidate := range resourceURLs(attr.Key, attr.Val) {
+				if strings.HasPrefix(strings.ToLower(candidate), "http://") && !seen[candidate] {
+					seen[candidate] = true
+					resources = append(resources, candidate)
+				}
+			}
+		}
+	}
+}
+
+func loadsResource(link html.Token) bool {
+	for _, attr := range link.Attr {
+		if attr.Key != "rel" {
+			continue
+		}
+		for _, rel := range strings.Fields(strings.ToLower(attr.Val)) {
+			if contains(linkResourceRels, rel) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resourceURLs splits the srcset candidates ("url 2x, url 640w"), other attributes hold a single url
+func resourceURLs(key string, value string) []string {
+	if key != "srcset" {
+		return []string{strings.TrimSpace(value)}
+	}
+	var urls []string
+	for _, candidate := range strings.Split(value, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			urls = append(urls, fields[0])
+		}
+	}
+	return urls
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
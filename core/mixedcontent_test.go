@@ -0,0 +1,59 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"testing"
+)
+
+func TestMixedContent(t *testing.T) {
+	var tests = map[string]struct {
+		body string
+		want []string
+	}{
+		"No resource":        {body: `<html><body>Hello</body></html>`, want: nil},
+		"HTTPS resources":    {body: `<script src="https://cdn/app.js"></script><img src="/logo.png">`, want: nil},
+		"HTTP script":        {body: `<script src="http://cdn/app.js"></script>`, want: []string{"http://cdn/app.js"}},
+		"HTTP stylesheet":    {body: `<link rel="stylesheet" href="HTTP://cdn/app.css">`, want: []string{"HTTP://cdn/app.css"}},
+		"Canonical link":     {body: `<link rel="canonical" href="http://foobar.com/">`, want: nil},
+		"Anchor":             {body: `<a href="http://foobar.com/">foobar</a>`, want: nil},
+		"Text only":          {body: `<p>see http://foobar.com/app.js</p>`, want: nil},
+		"Srcset":             {body: `<img srcset="https://cdn/a.png 1x, http://cdn/b.png 2x">`, want: []string{"http://cdn/b.png"}},
+		"Duplicates":         {body: `<img src="http://cdn/a.png"><img src="http://cdn/a.png"/><iframe src="http://ads/"></iframe>`, want: []string{"http://cdn/a.png", "http://ads/"}},
+		"Unclosed malformed": {body: `<div><img src="http://cdn/a.png"`, want: nil},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := core.MixedContent(tc.body)
+			if !core.SliceStringEqual(have, tc.want) {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestCheckMatchMixedContent(t *testing.T) {
+	check := &core.Check{MixedContent: true}
+	body := `<script src="http://cdn/app.js"></script>`
+	var tests = map[string]struct {
+		resp *internal.HTTPResponse
+		want bool
+	}{
+		"HTTPS page":          {resp: &internal.HTTPResponse{URL: "https://foobar.com/", Body: body}, want: true},
+		"HTTP page":           {resp: &internal.HTTPResponse{URL: "http://foobar.com/", Body: body}, want: false},
+		"HTTPS page, no http": {resp: &internal.HTTPResponse{URL: "https://foobar.com/", Body: "<p></p>"}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := check.Match(tc.resp)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+			if have && check.Details(tc.resp) != "mixed content: http://cdn/app.js" {
+				t.Errorf("expected the resource in the details, got: %v", check.Details(tc.resp))
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"gochopchop/internal"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Probe is an url expected to answer with a status code
+type Probe struct {
+	URL        string
+	StatusCode int32
+}
+
+// ParseProbes reads one "URL STATUS" pair per line, eg. "https://foobar.com/health 200".
+// Empty lines and lines starting with # are skipped.
+func ParseProbes(r io.Reader) ([]Probe, error) {
+	var probes []Probe
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("Invalid probe on line %d : %s. Format should be URL STATUS", line, text)
+		}
+		u, err := url.ParseRequestURI(fields[0])
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("Invalid url on line %d : %s", line, fields[0])
+		}
+		status, err := strconv.Atoi(fields[1])
+		if err != nil || status < 100 || status > 599 {
+			return nil, fmt.Errorf("Invalid status code on line %d : %s", line, fields[1])
+		}
+		probes = append(probes, Probe{URL: fields[0], StatusCode: int32(status)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return probes, nil
+}
+
+// Monitor requests each probe and reports the deviations from the expected status codes as findings,
+// with the given severity. The status code is matched the same way as the status_code of the checks.
+// The deviations are returned in the order of the probes, whatever thread requested them first.
+func Monitor(ctx context.Context, fetcher IFetcher, probes []Probe, threads int, severity string) []Output {
+	safeData := &SafeData{out: make([]Output, 0)}
+	wg := new(sync.WaitGroup)
+	jobs := make(chan int)
+
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				if output, deviated := monitorProbe(fetcher, probes[i], severity); deviated {
+					safeData.add(output, findingOrder{i})
+				}
+			}
+		}()
+	}
+
+	for i := range probes {
+		select {
+		case <-ctx.Done():
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return safeData.sorted()
+}
+
+func monitorProbe(fetcher IFetcher, probe Probe, severity string) (Output, bool) {
+	output := Output{
+		URL:         probe.URL,
+		Endpoint:    probe.URL,
+		Name:        "Unexpected status code",
+		Severity:    severity,
+		Remediation: "Check the availability of the url",
+		Description: fmt.Sprintf("The url is expected to answer with a %d status code", probe.StatusCode),
+	}
+	if u, err := url.Parse(probe.URL); err == nil {
+		output.Endpoint = u.RequestURI()
+	}
+
+	resp, err := fetcher.Fetch(&internal.HTTPRequest{URL: probe.URL})
+	if err != nil {
+		output.Details = fmt.Sprintf("expected %d, request failed: %v", probe.StatusCode, err)
+		return output, true
+	}
+	check := &Check{StatusCode: &probe.StatusCode}
+	if check.Match(resp) {
+		return output, false
+	}
+	output.Details = fmt.Sprintf("expected %d, got %d", probe.StatusCode, resp.StatusCode)
+	return output, true
+}
@@ -0,0 +1,93 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal"
+	"strings"
+	"testing"
+)
+
+type statusFetcher map[string]int
+
+func (f statusFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	status, ok := f[req.URL]
+	if !ok {
+		return nil, fmt.Errorf("connection refused")
+	}
+	return &internal.HTTPResponse{StatusCode: status}, nil
+}
+
+func TestParseProbes(t *testing.T) {
+	var tests = map[string]struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		"probes":         {input: "# health checks\nhttp://foobar/health 200\n\nhttp://foobar/admin   403\n", want: 2},
+		"missing status": {input: "http://foobar/health\n", wantErr: true},
+		"invalid status": {input: "http://foobar/health 20O\n", wantErr: true},
+		"invalid url":    {input: "foobar/health 200\n", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			probes, err := core.ParseProbes(strings.NewReader(tc.input))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if len(probes) != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, len(probes))
+			}
+		})
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	fetcher := statusFetcher{"http://foobar/health": 200, "http://foobar/admin": 200}
+	probes := []core.Probe{
+		{URL: "http://foobar/health", StatusCode: 200},
+		{URL: "http://foobar/admin", StatusCode: 403},
+		{URL: "http://down/health", StatusCode: 200},
+	}
+
+	output := core.Monitor(context.Background(), fetcher, probes, 2, "Medium")
+	if len(output) != 2 {
+		t.Fatalf("expected: 2 deviations, got: %v", output)
+	}
+	if output[0].URL != "http://foobar/admin" || output[1].URL != "http://down/health" {
+		t.Errorf("expected: the deviations in the order of the probes, got: %v", output)
+	}
+	details := map[string]string{}
+	for _, o := range output {
+		details[o.URL] = o.Details
+		if o.Severity != "Medium" {
+			t.Errorf("expected: %v, got: %v", "Medium", o.Severity)
+		}
+	}
+	if details["http://foobar/admin"] != "expected 403, got 200" {
+		t.Errorf("expected: %v, got: %v", "expected 403, got 200", details["http://foobar/admin"])
+	}
+	if !strings.HasPrefix(details["http://down/health"], "expected 200, request failed") {
+		t.Errorf("expected a failed request, got: %v", details["http://down/health"])
+	}
+}
+
+func TestMonitorOrder(t *testing.T) {
+	fetcher := statusFetcher{}
+	var probes []core.Probe
+	for i := 0; i < 50; i++ {
+		probes = append(probes, core.Probe{URL: fmt.Sprintf("http://down/%d", i), StatusCode: 200})
+	}
+
+	output := core.Monitor(context.Background(), fetcher, probes, 8, "High")
+	if len(output) != len(probes) {
+		t.Fatalf("expected: %v deviations, got: %v", len(probes), len(output))
+	}
+	for i, o := range output {
+		if o.URL != probes[i].URL {
+			t.Fatalf("expected: %v at %d, got: %v", probes[i].URL, i, o.URL)
+		}
+	}
+}
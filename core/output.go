@@ -2,9 +2,25 @@ package core
 
 // Output structure for each findings
 type Output struct {
-	URL         string `json:"url"`
-	Endpoint    string `json:"endpoint"`
-	Name        string `json:"checkName"`
-	Severity    string `json:"severity"`
-	Remediation string `json:"remediation"`
+	URL         string   `json:"url"`
+	Endpoint    string   `json:"endpoint"`
+	Name        string   `json:"checkName"`
+	Severity    string   `json:"severity"`
+	Remediation string   `json:"remediation"`
+	Description string   `json:"description,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Details     string   `json:"details,omitempty"`
+	Advisory    bool     `json:"advisory,omitempty"`
+	Confidence  string   `json:"confidence,omitempty"`
+	References  []string `json:"references,omitempty"`
+	// DurationMs is the response time of the request the finding was found in
+	DurationMs int64 `json:"durationMs"`
+	// FinalURL is the url the redirects of the request landed on, when they were followed
+	FinalURL string `json:"finalUrl,omitempty"`
+	// Evidence is the part of the body the check matched, when the evidence is captured
+	Evidence *Evidence `json:"evidence,omitempty"`
+	// PossiblyBlocked is set when the host seems to block all the requests, eg. behind a WAF, so other findings may be missing
+	PossiblyBlocked bool `json:"possiblyBlocked,omitempty"`
+	// Status is new, existing or resolved when the findings are compared to a previous run
+	Status string `json:"status,omitempty"`
 }
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// Pauser lets an operator pause the dispatch of new requests and resume it later.
+// The requests in flight are not interrupted.
+type Pauser struct {
+	mux sync.Mutex
+	// resume is closed when the scan is resumed, nil while the scan is running
+	resume chan struct{}
+}
+
+// NewPauser returns a running Pauser
+func NewPauser() *Pauser {
+	return &Pauser{}
+}
+
+// Pause stops the dispatch, it returns false if the scan was already paused
+func (p *Pauser) Pause() bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.resume != nil {
+		return false
+	}
+	p.resume = make(chan struct{})
+	return true
+}
+
+// Resume restarts the dispatch, it returns false if the scan was not paused
+func (p *Pauser) Resume() bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.resume == nil {
+		return false
+	}
+	close(p.resume)
+	p.resume = nil
+	return true
+}
+
+func (p *Pauser) Paused() bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.resume != nil
+}
+
+// Wait blocks while the scan is paused, or until the context is done.
+// A nil Pauser never blocks.
+func (p *Pauser) Wait(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	p.mux.Lock()
+	resume := p.resume
+	p.mux.Unlock()
+	if resume == nil {
+		return nil
+	}
+	select {
+	case <-resume:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,76 @@
+package core_test
+
+import (
+	"context"
+	"gochopchop/core"
+	"testing"
+	"time"
+)
+
+func TestPauser(t *testing.T) {
+	pauser := core.NewPauser()
+	if err := pauser.Wait(context.Background()); err != nil {
+		t.Fatalf("expected a running pauser not to block, got: %v", err)
+	}
+	if !pauser.Pause() || pauser.Pause() {
+		t.Fatalf("expected only the first pause to change the state")
+	}
+
+	done := make(chan error)
+	go func() { done <- pauser.Wait(context.Background()) }()
+	select {
+	case <-done:
+		t.Fatalf("expected a paused pauser to block")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if !pauser.Resume() || pauser.Resume() {
+		t.Fatalf("expected only the first resume to change the state")
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected: %v, got: %v", nil, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the resume to release the waiters")
+	}
+
+	pauser.Pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := pauser.Wait(ctx); err != context.Canceled {
+		t.Errorf("expected: %v, got: %v", context.Canceled, err)
+	}
+}
+
+func TestScanPaused(t *testing.T) {
+	fetcher := &flakyFetcher{}
+	plugins := []*core.Plugin{{Endpoints: []string{"/a", "/b"}, Checks: []*core.Check{{Name: "A"}}}}
+	scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: plugins}, 1)
+	scanner.Pauser = core.NewPauser()
+	scanner.Pauser.Pause()
+
+	done := make(chan struct{})
+	go func() {
+		scanner.Scan(context.Background(), []string{"http://problems"})
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	fetcher.mux.Lock()
+	calls := fetcher.calls
+	fetcher.mux.Unlock()
+	if calls != 0 {
+		t.Fatalf("expected: no request while paused, got: %v", calls)
+	}
+
+	scanner.Pauser.Resume()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the scan to finish once resumed")
+	}
+	if fetcher.calls != 2 {
+		t.Errorf("expected: %v, got: %v", 2, fetcher.calls)
+	}
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Progress counts the jobs of a scan and the completed ones, to report how far along the scan is.
+// A job sends the request of one or more plugins against an url. A nil Progress counts nothing.
+type Progress struct {
+	total     int64
+	completed int64
+	start     time.Time
+}
+
+// ProgressState is the progress of the scan at a point in time
+type ProgressState struct {
+	Completed int
+	Total     int
+	Elapsed   time.Duration
+}
+
+func NewProgress() *Progress {
+	return &Progress{start: time.Now()}
+}
+
+func (p *Progress) add(jobs int) {
+	if p == nil {
+		return
+	}
+	atomic.AddInt64(&p.total, int64(jobs))
+}
+
+func (p *Progress) done() {
+	if p == nil {
+		return
+	}
+	atomic.AddInt64(&p.completed, 1)
+}
+
+// State returns the completed and total jobs so far
+func (p *Progress) State() ProgressState {
+	return ProgressState{
+		Completed: int(atomic.LoadInt64(&p.completed)),
+		Total:     int(atomic.LoadInt64(&p.total)),
+		Elapsed:   time.Since(p.start),
+	}
+}
+
+// Percent of the jobs completed
+func (s ProgressState) Percent() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Completed) * 100 / float64(s.Total)
+}
+
+// Remaining estimates the time left from the pace of the completed jobs, false until a job is completed
+func (s ProgressState) Remaining() (time.Duration, bool) {
+	if s.Completed == 0 {
+		return 0, false
+	}
+	if s.Completed >= s.Total {
+		return 0, true
+	}
+	perJob := s.Elapsed / time.Duration(s.Completed)
+	return (perJob * time.Duration(s.Total-s.Completed)).Round(time.Second), true
+}
+
+func (s ProgressState) String() string {
+	eta := "estimating the time remaining"
+	if remaining, ok := s.Remaining(); ok {
+		eta = fmt.Sprintf("%s remaining", remaining)
+	}
+	return fmt.Sprintf("%d/%d requests (%.1f%%), %s", s.Completed, s.Total, s.Percent(), eta)
+}
@@ -0,0 +1,66 @@
+package core_test
+
+import (
+	"context"
+	"gochopchop/core"
+	"testing"
+	"time"
+)
+
+func TestProgressState(t *testing.T) {
+	var tests = map[string]struct {
+		state         core.ProgressState
+		wantRemaining time.Duration
+		wantKnown     bool
+		wantString    string
+	}{
+		"Not started": {
+			state:      core.ProgressState{Completed: 0, Total: 10, Elapsed: time.Second},
+			wantString: "0/10 requests (0.0%), estimating the time remaining",
+		},
+		"Halfway": {
+			state:         core.ProgressState{Completed: 5, Total: 10, Elapsed: 30 * time.Second},
+			wantRemaining: 30 * time.Second,
+			wantKnown:     true,
+			wantString:    "5/10 requests (50.0%), 30s remaining",
+		},
+		"Done": {
+			state:      core.ProgressState{Completed: 3, Total: 3, Elapsed: time.Minute},
+			wantKnown:  true,
+			wantString: "3/3 requests (100.0%), 0s remaining",
+		},
+		"No jobs": {
+			state:      core.ProgressState{},
+			wantString: "0/0 requests (0.0%), estimating the time remaining",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			remaining, known := tc.state.Remaining()
+			if remaining != tc.wantRemaining || known != tc.wantKnown {
+				t.Errorf("expected: %v %v, got: %v %v", tc.wantRemaining, tc.wantKnown, remaining, known)
+			}
+			if have := tc.state.String(); have != tc.wantString {
+				t.Errorf("expected: %v, got: %v", tc.wantString, have)
+			}
+		})
+	}
+}
+
+func TestScanProgress(t *testing.T) {
+	plugins := []*core.Plugin{
+		{Endpoints: []string{"/a", "/b"}, Checks: []*core.Check{{Name: "A"}}},
+		// sharing the request of /a, it isn't counted twice
+		{Endpoint: "/a", Checks: []*core.Check{{Name: "B"}}},
+	}
+	fetcher := &countingFetcher{calls: make(map[string]int)}
+	scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: plugins}, 2)
+	scanner.Progress = core.NewProgress()
+	scanner.Scan(context.Background(), []string{"http://problems", "http://other"})
+
+	state := scanner.Progress.State()
+	if state.Completed != 4 || state.Total != 4 {
+		t.Errorf("expected: 4/4, got: %v/%v", state.Completed, state.Total)
+	}
+}
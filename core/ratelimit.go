@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// ParseRateLimits reads SEVERITY=REQUESTS_PER_SECOND pairs.
+// Severities without a limit, or with a 0 limit, are not throttled.
+func ParseRateLimits(pairs []string) (map[string]float64, error) {
+	limits := make(map[string]float64)
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid rate limit : %s. Format should be SEVERITY=REQUESTS_PER_SECOND", pair)
+		}
+		if !ValidSeverity(parts[0]) {
+			return nil, fmt.Errorf("Invalid severity level : %s. Please use : %s", parts[0], SeveritiesAsString())
+		}
+		limit, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("Invalid rate limit : %s. Limit should be a positive number of requests per second", pair)
+		}
+		limits[parts[0]] = limit
+	}
+	return limits, nil
+}
+
+// SeverityLimiter throttles the requests according to the severity of the checks they are sent for,
+// so the intrusive checks can be sent slower than the informational ones
+type SeverityLimiter struct {
+	limiters map[string]*rate.Limiter
+}
+
+// NewSeverityLimiter returns a limiter per severity, from the requests per second of each severity
+func NewSeverityLimiter(limits map[string]float64) *SeverityLimiter {
+	limiters := make(map[string]*rate.Limiter)
+	for severity, limit := range limits {
+		if limit > 0 {
+			limiters[severity] = rate.NewLimiter(rate.Limit(limit), 1)
+		}
+	}
+	return &SeverityLimiter{limiters: limiters}
+}
+
+// Wait blocks until a request can be sent for the severity, or until the context is done.
+// A nil SeverityLimiter never blocks.
+func (l *SeverityLimiter) Wait(ctx context.Context, severity string) error {
+	if l == nil {
+		return nil
+	}
+	limiter, ok := l.limiters[severity]
+	if !ok {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
+
+// Severity returns the highest severity of the checks of the plugin, which drives the throttling of its requests
+func (p *Plugin) Severity() string {
+	severity := ""
+	for _, check := range p.Checks {
+		if severity == "" || SeverityRank(check.Severity) < SeverityRank(severity) {
+			severity = check.Severity
+		}
+	}
+	return severity
+}
+
+// HostLimiter throttles the requests sent to each host, so many plugins at high thread counts
+// don't trip the WAFs and rate limiters of the targets
+type HostLimiter struct {
+	mux      sync.Mutex
+	limit    rate.Limit
+	limiters map[string]*rate.Limiter
+}
+
+// NewHostLimiter returns a limiter allowing the requests per second to each host
+func NewHostLimiter(limit float64) *HostLimiter {
+	return &HostLimiter{limit: rate.Limit(limit), limiters: make(map[string]*rate.Limiter)}
+}
+
+// Wait blocks until a request can be sent to the host of the url, or until the context is done.
+// A nil HostLimiter never blocks.
+func (l *HostLimiter) Wait(ctx context.Context, rawURL string) error {
+	if l == nil {
+		return nil
+	}
+	host := hostname(rawURL)
+	l.mux.Lock()
+	limiter, ok := l.limiters[host]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, 1)
+		l.limiters[host] = limiter
+	}
+	l.mux.Unlock()
+	return limiter.Wait(ctx)
+}
+
+// HostSemaphore caps the requests in flight to each host, while the threads keep working on the other hosts
+type HostSemaphore struct {
+	mux   sync.Mutex
+	max   int
+	slots map[string]chan struct{}
+}
+
+// NewHostSemaphore returns a semaphore allowing max requests in flight to each host
+func NewHostSemaphore(max int) *HostSemaphore {
+	return &HostSemaphore{max: max, slots: make(map[string]chan struct{})}
+}
+
+// Acquire blocks until a request can be sent to the host of the url, or until the context is done.
+// A nil HostSemaphore never blocks.
+func (s *HostSemaphore) Acquire(ctx context.Context, rawURL string) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s.hostSlots(rawURL) <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees the slot acquired for the url
+func (s *HostSemaphore) Release(rawURL string) {
+	if s == nil {
+		return
+	}
+	<-s.hostSlots(rawURL)
+}
+
+func (s *HostSemaphore) hostSlots(rawURL string) chan struct{} {
+	host := hostname(rawURL)
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	slots, ok := s.slots[host]
+	if !ok {
+		slots = make(chan struct{}, s.max)
+		s.slots[host] = slots
+	}
+	return slots
+}
+
+// hostname returns the host of the url without its port, or the url itself if it can't be parsed
+func hostname(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return rawURL
+}
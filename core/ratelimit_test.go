@@ -0,0 +1,127 @@
+package core_test
+
+import (
+	"context"
+	"gochopchop/core"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	var tests = map[string]struct {
+		pairs   []string
+		want    map[string]float64
+		wantErr bool
+	}{
+		"no limit":           {pairs: nil, want: map[string]float64{}},
+		"limits":             {pairs: []string{"High=1", "Informational=0.5"}, want: map[string]float64{"High": 1, "Informational": 0.5}},
+		"invalid format":     {pairs: []string{"High:1"}, wantErr: true},
+		"invalid severity":   {pairs: []string{"Urgent=1"}, wantErr: true},
+		"negative limit":     {pairs: []string{"High=-1"}, wantErr: true},
+		"not a number limit": {pairs: []string{"High=fast"}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have, err := core.ParseRateLimits(tc.pairs)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if len(have) != len(tc.want) {
+				t.Fatalf("expected: %v, got: %v", tc.want, have)
+			}
+			for severity, limit := range tc.want {
+				if have[severity] != limit {
+					t.Errorf("expected: %v, got: %v", tc.want, have)
+				}
+			}
+		})
+	}
+}
+
+func TestPluginSeverity(t *testing.T) {
+	plugin := &core.Plugin{Checks: []*core.Check{{Severity: "Low"}, {Severity: "High"}, {Severity: "Medium"}}}
+	if plugin.Severity() != "High" {
+		t.Errorf("expected: %v, got: %v", "High", plugin.Severity())
+	}
+}
+
+func TestSeverityLimiter(t *testing.T) {
+	limiter := core.NewSeverityLimiter(map[string]float64{"High": 20, "Low": 0})
+	ctx := context.Background()
+
+	begin := time.Now()
+	for i := 0; i < 3; i++ {
+		limiter.Wait(ctx, "High")
+	}
+	if elapsed := time.Since(begin); elapsed < 90*time.Millisecond {
+		t.Errorf("expected the High requests to be throttled, got: %v", elapsed)
+	}
+
+	begin = time.Now()
+	for i := 0; i < 100; i++ {
+		limiter.Wait(ctx, "Low")
+		limiter.Wait(ctx, "Medium")
+	}
+	if elapsed := time.Since(begin); elapsed > 50*time.Millisecond {
+		t.Errorf("expected the unlimited severities not to be throttled, got: %v", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := limiter.Wait(cancelled, "High"); err == nil {
+		t.Errorf("expected the wait to stop with the context")
+	}
+}
+
+func TestHostLimiter(t *testing.T) {
+	limiter := core.NewHostLimiter(20)
+	ctx := context.Background()
+
+	begin := time.Now()
+	for i := 0; i < 3; i++ {
+		limiter.Wait(ctx, "http://foobar/a")
+		limiter.Wait(ctx, "https://other:8443/b")
+	}
+	if elapsed := time.Since(begin); elapsed < 90*time.Millisecond || elapsed > 200*time.Millisecond {
+		t.Errorf("expected each host to be throttled on its own, got: %v", elapsed)
+	}
+
+	var unlimited *core.HostLimiter
+	if err := unlimited.Wait(ctx, "http://foobar/a"); err != nil {
+		t.Errorf("expected a nil limiter not to block, got: %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := limiter.Wait(cancelled, "http://foobar/a"); err == nil {
+		t.Errorf("expected the wait to stop with the context")
+	}
+}
+
+func TestHostSemaphore(t *testing.T) {
+	semaphore := core.NewHostSemaphore(1)
+	ctx := context.Background()
+
+	if err := semaphore.Acquire(ctx, "http://foobar/a"); err != nil {
+		t.Fatalf("expected a nil error, got: %v", err)
+	}
+	if err := semaphore.Acquire(ctx, "https://other:8443/b"); err != nil {
+		t.Errorf("expected each host to have its own slots, got: %v", err)
+	}
+	full, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := semaphore.Acquire(full, "http://foobar:8080/c"); err == nil {
+		t.Errorf("expected the host to be full")
+	}
+	semaphore.Release("http://foobar/a")
+	if err := semaphore.Acquire(ctx, "http://foobar/c"); err != nil {
+		t.Errorf("expected the released slot to be available, got: %v", err)
+	}
+
+	var unlimited *core.HostSemaphore
+	if err := unlimited.Acquire(ctx, "http://foobar/a"); err != nil {
+		t.Errorf("expected a nil semaphore not to block, got: %v", err)
+	}
+	unlimited.Release("http://foobar/a")
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CompileRegexes compiles the regex conditions of the check, so they are compiled once when the signatures are loaded
+func (check *Check) CompileRegexes() error {
+	var err error
+	if check.matchRegex, err = compileRegexes("match_regex", check.Name, check.MatchRegex); err != nil {
+		return err
+	}
+	if check.allMatchRegex, err = compileRegexes("all_match_regex", check.Name, check.AllMatchRegex); err != nil {
+		return err
+	}
+	if check.noMatchRegex, err = compileRegexes("no_match_regex", check.Name, check.NoMatchRegex); err != nil {
+		return err
+	}
+	if check.headersRegex, err = compileHeaderRegexes("headers_regex", check.Name, check.HeadersRegex); err != nil {
+		return err
+	}
+	if check.noHeadersRegex, err = compileHeaderRegexes("no_headers_regex", check.Name, check.NoHeadersRegex); err != nil {
+		return err
+	}
+	return nil
+}
+
+// compileHeaderRegexes compiles the regexes of the KEY: REGEX header conditions
+func compileHeaderRegexes(field string, name string, conditions []string) ([]*regexp.Regexp, error) {
+	for _, condition := range conditions {
+		if key, _ := splitHeaderRegex(condition); key == "" || !strings.Contains(condition, ":") {
+			return nil, fmt.Errorf("Invalid header regex format : %s in %s of %s check. Format should be KEY: REGEX", condition, field, name)
+		}
+	}
+	return compileRegexes(field, name, headerPatterns(conditions))
+}
+
+// splitHeaderRegex splits a KEY: REGEX header condition, the spaces around the regex being ignored
+func splitHeaderRegex(condition string) (key string, pattern string) {
+	parts := strings.SplitN(condition, ":", 2)
+	if len(parts) == 1 {
+		return strings.TrimSpace(parts[0]), ""
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
+func headerPatterns(conditions []string) []string {
+	patterns := make([]string, len(conditions))
+	for i, condition := range conditions {
+		_, patterns[i] = splitHeaderRegex(condition)
+	}
+	return patterns
+}
+
+func compileRegexes(field string, name string, patterns []string) ([]*regexp.Regexp, error) {
+	regexes := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid regex %q in %s of %s check : %v", pattern, field, name, err)
+		}
+		regexes = append(regexes, regex)
+	}
+	return regexes, nil
+}
+
+// regexes returns the compiled patterns, compiling them on the fly when the check was not loaded from a signature file.
+// An invalid pattern never matches.
+func regexes(patterns []string, compiled []*regexp.Regexp) []*regexp.Regexp {
+	if len(compiled) == len(patterns) {
+		return compiled
+	}
+	compiled = make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		compiled[i], _ = regexp.Compile(pattern)
+	}
+	return compiled
+}
+
+func regexMatch(regex *regexp.Regexp, body string) bool {
+	return regex != nil && regex.MatchString(body)
+}
@@ -0,0 +1,95 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"net/http"
+	"testing"
+)
+
+func TestCheckMatchRegex(t *testing.T) {
+	resp := &internal.HTTPResponse{StatusCode: 200, Body: "<title>Jenkins</title> version 2.263.1 token=ab12cd"}
+	var tests = map[string]struct {
+		check *core.Check
+		want  bool
+	}{
+		"match regex":                {check: &core.Check{MatchRegex: []string{`version 1\.\d+`, `version 2\.\d+`}}, want: true},
+		"match regex not found":      {check: &core.Check{MatchRegex: []string{`version 3\.\d+`}}, want: false},
+		"all match regex":            {check: &core.Check{AllMatchRegex: []string{`<title>\w+</title>`, `token=[a-f0-9]{6}`}}, want: true},
+		"all match regex missing":    {check: &core.Check{AllMatchRegex: []string{`<title>\w+</title>`, `token=[0-9]{6}`}}, want: false},
+		"no match regex":             {check: &core.Check{NoMatchRegex: []string{`(?i)login`}}, want: true},
+		"no match regex found":       {check: &core.Check{NoMatchRegex: []string{`(?i)jenkins`}}, want: false},
+		"with substring conditions":  {check: &core.Check{MustMatchOne: []string{"Jenkins"}, MatchRegex: []string{`2\.263\.\d`}}, want: true},
+		"substring condition failed": {check: &core.Check{MustNotMatch: []string{"Jenkins"}, MatchRegex: []string{`2\.263\.\d`}}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.check.CompileRegexes(); err != nil {
+				t.Fatalf("expected: no error, got: %v", err)
+			}
+			have := tc.check.Match(resp)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestCheckMatchHeadersRegex(t *testing.T) {
+	resp := &internal.HTTPResponse{StatusCode: 200, Header: http.Header{
+		"Server":     []string{"nginx/1.14.2"},
+		"Set-Cookie": []string{"lang=en", "session=ab12; HttpOnly"},
+	}}
+	var tests = map[string]struct {
+		check *core.Check
+		want  bool
+	}{
+		"header regex":                {check: &core.Check{HeadersRegex: []string{`Server: ^nginx/1\.1[0-8]`}}, want: true},
+		"header regex not matched":    {check: &core.Check{HeadersRegex: []string{`Server: ^nginx/1\.2\d`}}, want: false},
+		"header regex missing header": {check: &core.Check{HeadersRegex: []string{`X-Powered-By: .*`}}, want: false},
+		"header regex any value":      {check: &core.Check{HeadersRegex: []string{`set-cookie: ^session=\w+;`}}, want: true},
+		"no header regex":             {check: &core.Check{NoHeadersRegex: []string{`Set-Cookie: (?i)secure`}}, want: true},
+		"no header regex matched":     {check: &core.Check{NoHeadersRegex: []string{`Server: nginx`}}, want: false},
+		"no header regex missing":     {check: &core.Check{NoHeadersRegex: []string{`X-Powered-By: PHP`}}, want: true},
+		"header absent":               {check: &core.Check{NoHeaders: []string{"X-Frame-Options"}}, want: true},
+		"header present":              {check: &core.Check{NoHeaders: []string{"Server"}}, want: false},
+		"with substring header":       {check: &core.Check{Headers: []string{"Server: nginx"}, NoHeadersRegex: []string{`Server: /2\.`}}, want: true},
+		"substring header failed":     {check: &core.Check{Headers: []string{"Server: Apache"}, HeadersRegex: []string{`Server: nginx`}}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.check.CompileRegexes(); err != nil {
+				t.Fatalf("expected: no error, got: %v", err)
+			}
+			have := tc.check.Match(resp)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestCheckCompileRegexes(t *testing.T) {
+	var tests = map[string]struct {
+		check   *core.Check
+		wantErr bool
+	}{
+		"valid regexes":            {check: &core.Check{Name: "Jenkins", MatchRegex: []string{`\d+`}, NoMatchRegex: []string{`^$`}}},
+		"invalid regex":            {check: &core.Check{Name: "Jenkins", AllMatchRegex: []string{`(unclosed`}}, wantErr: true},
+		"no regex condition":       {check: &core.Check{Name: "Jenkins"}},
+		"valid header regex":       {check: &core.Check{Name: "Jenkins", HeadersRegex: []string{`X-Jenkins: ^2\.`}}},
+		"header regex without key": {check: &core.Check{Name: "Jenkins", HeadersRegex: []string{`^2\.`}}, wantErr: true},
+		"invalid header regex":     {check: &core.Check{Name: "Jenkins", NoHeadersRegex: []string{`X-Jenkins: (unclosed`}}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.check.CompileRegexes()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package core
+
+import (
+	"fmt"
+	"gochopchop/internal"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MaxRetries caps the retries of a failed request
+const MaxRetries = 5
+
+var httpMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+// RequestOptions configure the requests of a plugin in one place.
+// They take precedence over the legacy fields of the plugin (eg. follow_redirects).
+type RequestOptions struct {
+	Method  string   `yaml:"method"`
+	Headers []string `yaml:"headers"`
+	Body    string   `yaml:"body"`
+	// FollowRedirects overrides the follow_redirects field of the plugin when set
+	FollowRedirects *bool `yaml:"follow_redirects"`
+	// Timeout in seconds overrides the --timeout flag when set
+	Timeout int `yaml:"timeout"`
+	// Retries of the requests failing before getting a response (eg. on a timeout)
+	Retries int `yaml:"retries"`
+	// Insecure overrides the --insecure flag when set
+	Insecure *bool `yaml:"insecure"`
+	// MaxRedirects overrides the --max-redirects flag when set
+	MaxRedirects int `yaml:"max_redirects"`
+}
+
+// Validate ensures the options are well formed
+func (r *RequestOptions) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if err := validateMethod(r.Method); err != nil {
+		return err
+	}
+	for _, header := range r.Headers {
+		if !strings.Contains(header, ":") {
+			return fmt.Errorf("Invalid header format : %s. Format should be KEY:VALUE", header)
+		}
+	}
+	if r.Timeout < 0 {
+		return fmt.Errorf("The request timeout must be positive")
+	}
+	if r.Retries < 0 || r.Retries > MaxRetries {
+		return fmt.Errorf("The request retries must be between 0 and %d", MaxRetries)
+	}
+	if r.MaxRedirects < 0 {
+		return fmt.Errorf("The request max_redirects must be positive")
+	}
+	return nil
+}
+
+// ValidateMethod ensures the method of the plugin is a known HTTP verb, and its timeout is positive
+func (p *Plugin) ValidateMethod() error {
+	if err := validateMethod(p.Method); err != nil {
+		return err
+	}
+	if p.Timeout < 0 {
+		return fmt.Errorf("The plugin timeout must be positive")
+	}
+	if p.Body != "" && (p.Method == "" || p.Method == http.MethodGet || p.Method == http.MethodHead) {
+		return fmt.Errorf("A body can't be sent with the %s method. Please set a method such as POST or PUT", p.method())
+	}
+	return nil
+}
+
+// ParseRequestHeaders parses the "Key: Value" request_headers of the plugin once, when the signatures are loaded
+func (p *Plugin) ParseRequestHeaders() error {
+	headers, err := parseRequestHeaders(p.RequestHeaders)
+	if err != nil {
+		return err
+	}
+	p.requestHeaders = headers
+	return nil
+}
+
+func parseRequestHeaders(lines []string) (http.Header, error) {
+	headers := make(http.Header)
+	for _, line := range lines {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("Invalid request header format : %s. Format should be Key: Value", line)
+		}
+		headers.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	return headers, nil
+}
+
+func validateMethod(method string) error {
+	if method != "" && !contains(httpMethods, method) {
+		return fmt.Errorf("Invalid method : %s. Please use : %s", method, strings.Join(httpMethods, ", "))
+	}
+	return nil
+}
+
+// method returns the method of the plugin, GET by default
+func (p *Plugin) method() string {
+	if p.Method == "" {
+		return http.MethodGet
+	}
+	return p.Method
+}
+
+// FollowsRedirects resolves whether the requests of the plugin follow the redirects
+func (p *Plugin) FollowsRedirects() bool {
+	if p.Request != nil && p.Request.FollowRedirects != nil {
+		return *p.Request.FollowRedirects
+	}
+	return p.FollowRedirects
+}
+
+// Retries resolves the number of retries of the failed requests of the plugin
+func (p *Plugin) Retries() int {
+	if p.Request == nil {
+		return 0
+	}
+	return p.Request.Retries
+}
+
+// NewRequest builds the request of the plugin for the url
+func (p *Plugin) NewRequest(url string) *internal.HTTPRequest {
+	req := &internal.HTTPRequest{URL: url, Method: p.method(), Header: make(http.Header), Body: p.Body}
+	if p.Request != nil && p.Request.Method != "" {
+		req.Method = p.Request.Method
+	}
+	if p.Request != nil && p.Request.Body != "" {
+		req.Body = p.Request.Body
+	}
+	p.applyRequestOptions(req)
+	return req
+}
+
+// applyRequestOptions sets the headers, timeout and TLS verification of the plugin on the request.
+// The headers already set on the request (eg. by a step) are kept.
+// The headers of the request block take precedence over the request_headers of the plugin.
+func (p *Plugin) applyRequestOptions(req *internal.HTTPRequest) {
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	if p.Request != nil {
+		for _, header := range p.Request.Headers {
+			parts := strings.SplitN(header, ":", 2)
+			key := strings.TrimSpace(parts[0])
+			if _, ok := req.Header[http.CanonicalHeaderKey(key)]; !ok {
+				req.Header.Add(key, strings.TrimSpace(parts[1]))
+			}
+		}
+	}
+	headers := p.requestHeaders
+	if headers == nil && len(p.RequestHeaders) > 0 {
+		// the plugin was not loaded from a signature file
+		headers, _ = parseRequestHeaders(p.RequestHeaders)
+	}
+	for key, values := range headers {
+		if _, ok := req.Header[key]; !ok {
+			req.Header[key] = append([]string(nil), values...)
+		}
+	}
+	if p.Timeout > 0 {
+		req.Timeout = time.Duration(p.Timeout) * time.Second
+	}
+	if p.Request != nil {
+		if p.Request.Timeout > 0 {
+			req.Timeout = time.Duration(p.Request.Timeout) * time.Second
+		}
+		req.Insecure = p.Request.Insecure
+		req.MaxRedirects = p.Request.MaxRedirects
+	}
+	// the expired or self-signed certificates of the TLS checks can't be verified, unless the plugin says otherwise
+	if req.Insecure == nil && p.hasTLSChecks() {
+		insecure := true
+		req.Insecure = &insecure
+	}
+}
+
+func (r *RequestOptions) Equals(request *RequestOptions) bool {
+	if r == nil || request == nil {
+		return r == request
+	}
+	return r.Method == request.Method && SliceStringEqual(r.Headers, request.Headers) && r.Body == request.Body &&
+		boolPtrEqual(r.FollowRedirects, request.FollowRedirects) && r.Timeout == request.Timeout &&
+		r.Retries == request.Retries && boolPtrEqual(r.Insecure, request.Insecure) && r.MaxRedirects == request.MaxRedirects
+}
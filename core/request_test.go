@@ -0,0 +1,214 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal"
+	"testing"
+	"time"
+)
+
+func TestPluginRequestOptions(t *testing.T) {
+	enabled, disabled := createBool(true), createBool(false)
+	var tests = map[string]struct {
+		plugin              *core.Plugin
+		wantFollowRedirects bool
+		wantMethod          string
+		wantTimeout         time.Duration
+		wantMaxRedirects    int
+	}{
+		"legacy field":            {plugin: &core.Plugin{FollowRedirects: true}, wantFollowRedirects: true, wantMethod: "GET"},
+		"plugin timeout":          {plugin: &core.Plugin{Timeout: 1}, wantMethod: "GET", wantTimeout: time.Second},
+		"request block timeout":   {plugin: &core.Plugin{Timeout: 1, Request: &core.RequestOptions{Timeout: 5}}, wantMethod: "GET", wantTimeout: 5 * time.Second},
+		"request block":           {plugin: &core.Plugin{Request: &core.RequestOptions{FollowRedirects: enabled, Method: "POST", Timeout: 3}}, wantFollowRedirects: true, wantMethod: "POST", wantTimeout: 3 * time.Second},
+		"block takes precedence":  {plugin: &core.Plugin{FollowRedirects: true, Request: &core.RequestOptions{FollowRedirects: disabled}}, wantFollowRedirects: false, wantMethod: "GET"},
+		"block without the field": {plugin: &core.Plugin{FollowRedirects: true, Request: &core.RequestOptions{Retries: 2}}, wantFollowRedirects: true, wantMethod: "GET"},
+		"plugin method":           {plugin: &core.Plugin{Method: "OPTIONS"}, wantMethod: "OPTIONS"},
+		"block method precedence": {plugin: &core.Plugin{Method: "PUT", Request: &core.RequestOptions{Method: "POST"}}, wantMethod: "POST"},
+		"block max redirects":     {plugin: &core.Plugin{Request: &core.RequestOptions{FollowRedirects: enabled, MaxRedirects: 3}}, wantFollowRedirects: true, wantMethod: "GET", wantMaxRedirects: 3},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if have := tc.plugin.FollowsRedirects(); have != tc.wantFollowRedirects {
+				t.Errorf("expected: %v, got: %v", tc.wantFollowRedirects, have)
+			}
+			req := tc.plugin.NewRequest("http://foobar/")
+			if req.Method != tc.wantMethod {
+				t.Errorf("expected: %v, got: %v", tc.wantMethod, req.Method)
+			}
+			if req.Timeout != tc.wantTimeout {
+				t.Errorf("expected: %v, got: %v", tc.wantTimeout, req.Timeout)
+			}
+			if req.MaxRedirects != tc.wantMaxRedirects {
+				t.Errorf("expected: %v, got: %v", tc.wantMaxRedirects, req.MaxRedirects)
+			}
+		})
+	}
+}
+
+func TestPluginRequestHeaders(t *testing.T) {
+	var tests = map[string]struct {
+		plugin  *core.Plugin
+		want    map[string]string
+		wantErr bool
+	}{
+		"request headers": {
+			plugin: &core.Plugin{RequestHeaders: []string{"X-Forwarded-For: 127.0.0.1", "authorization:Bearer a:b"}},
+			want:   map[string]string{"X-Forwarded-For": "127.0.0.1", "Authorization": "Bearer a:b"},
+		},
+		"request block takes precedence": {
+			plugin: &core.Plugin{RequestHeaders: []string{"User-Agent: chopchop", "X-Api: 1"}, Request: &core.RequestOptions{Headers: []string{"User-Agent: scanner"}}},
+			want:   map[string]string{"User-Agent": "scanner", "X-Api": "1"},
+		},
+		"missing colon": {plugin: &core.Plugin{RequestHeaders: []string{"X-Forwarded-For 127.0.0.1"}}, wantErr: true},
+		"missing key":   {plugin: &core.Plugin{RequestHeaders: []string{": value"}}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.plugin.ParseRequestHeaders()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if tc.wantErr {
+				return
+			}
+			req := tc.plugin.NewRequest("http://foobar/")
+			for key, value := range tc.want {
+				if have := req.Header.Get(key); have != value {
+					t.Errorf("expected: %v, got: %v", value, have)
+				}
+			}
+		})
+	}
+}
+
+func TestPluginValidateMethod(t *testing.T) {
+	var tests = map[string]struct {
+		plugin  *core.Plugin
+		wantErr bool
+	}{
+		"default method":    {plugin: &core.Plugin{}},
+		"post with a body":  {plugin: &core.Plugin{Method: "POST", Body: "a=b"}},
+		"unknown method":    {plugin: &core.Plugin{Method: "FETCH"}, wantErr: true},
+		"lowercase method":  {plugin: &core.Plugin{Method: "post"}, wantErr: true},
+		"body without verb": {plugin: &core.Plugin{Body: "a=b"}, wantErr: true},
+		"negative timeout":  {plugin: &core.Plugin{Timeout: -1}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.plugin.ValidateMethod()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRequestOptionsValidate(t *testing.T) {
+	var tests = map[string]struct {
+		request *core.RequestOptions
+		wantErr bool
+	}{
+		"no block":           {request: nil, wantErr: false},
+		"valid block":        {request: &core.RequestOptions{Method: "OPTIONS", Headers: []string{"X-Forwarded-For: 127.0.0.1"}, Timeout: 2, Retries: 1}, wantErr: false},
+		"unknown method":     {request: &core.RequestOptions{Method: "FETCH"}, wantErr: true},
+		"invalid header":     {request: &core.RequestOptions{Headers: []string{"X-Forwarded-For"}}, wantErr: true},
+		"negative timeout":   {request: &core.RequestOptions{Timeout: -1}, wantErr: true},
+		"too many retries":   {request: &core.RequestOptions{Retries: core.MaxRetries + 1}, wantErr: true},
+		"negative redirects": {request: &core.RequestOptions{MaxRedirects: -1}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.request.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// failingFetcher fails the first requests before answering
+type failingFetcher struct {
+	failures int
+	calls    int
+	headers  []string
+}
+
+func (f *failingFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	f.calls++
+	f.headers = append(f.headers, req.Header.Get("X-Forwarded-For"))
+	if f.calls <= f.failures {
+		return nil, fmt.Errorf("timeout")
+	}
+	return &internal.HTTPResponse{StatusCode: 200}, nil
+}
+
+func TestScanRetries(t *testing.T) {
+	var tests = map[string]struct {
+		retries      int
+		wantFindings int
+		wantCalls    int
+	}{
+		"no retry":           {retries: 0, wantFindings: 0, wantCalls: 1},
+		"retried":            {retries: 2, wantFindings: 1, wantCalls: 3},
+		"not enough retries": {retries: 1, wantFindings: 0, wantCalls: 2},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := &failingFetcher{failures: 2}
+			plugin := &core.Plugin{
+				Endpoint: "/",
+				Checks:   []*core.Check{{Name: "Up", StatusCode: createInt32(200)}},
+				Request:  &core.RequestOptions{Retries: tc.retries, Headers: []string{"X-Forwarded-For: 127.0.0.1"}},
+			}
+			scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: []*core.Plugin{plugin}}, 1)
+			output, _ := scanner.Scan(context.Background(), []string{"http://foobar"})
+			if len(output) != tc.wantFindings {
+				t.Errorf("expected: %v findings, got: %v", tc.wantFindings, len(output))
+			}
+			if fetcher.calls != tc.wantCalls {
+				t.Errorf("expected: %v calls, got: %v", tc.wantCalls, fetcher.calls)
+			}
+			if fetcher.headers[0] != "127.0.0.1" {
+				t.Errorf("expected: %v, got: %v", "127.0.0.1", fetcher.headers[0])
+			}
+		})
+	}
+}
+
+func TestScanRetriesThrottled(t *testing.T) {
+	var tests = map[string]struct {
+		limiter func(scanner *core.Scanner)
+	}{
+		"severity limit": {limiter: func(scanner *core.Scanner) { scanner.Limiter = core.NewSeverityLimiter(map[string]float64{"High": 20}) }},
+		"host limit":     {limiter: func(scanner *core.Scanner) { scanner.HostLimiter = core.NewHostLimiter(20) }},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := &failingFetcher{failures: 2}
+			plugin := &core.Plugin{
+				Endpoint: "/",
+				Checks:   []*core.Check{{Name: "Up", Severity: "High", StatusCode: createInt32(200)}},
+				Request:  &core.RequestOptions{Retries: 2},
+			}
+			scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: []*core.Plugin{plugin}}, 1)
+			tc.limiter(scanner)
+			begin := time.Now()
+			output, _ := scanner.Scan(context.Background(), []string{"http://foobar"})
+			if len(output) != 1 || fetcher.calls != 3 {
+				t.Fatalf("expected: 1 finding after 3 calls, got: %v after %v", len(output), fetcher.calls)
+			}
+			// the first attempt is sent at once, each of the 2 retries waits for the limiter (50ms at 20 requests per second)
+			if elapsed := time.Since(begin); elapsed < 90*time.Millisecond {
+				t.Errorf("expected the retries to be throttled, got: %v", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultRiskWeights are the points a finding adds to the risk score of its host, by severity
+var DefaultRiskWeights = map[string]int{
+	"Critical":      20,
+	"High":          10,
+	"Medium":        5,
+	"Low":           2,
+	"Informational": 0,
+}
+
+// HostRisk is the aggregated risk of a host
+type HostRisk struct {
+	Host     string `json:"host"`
+	Score    int    `json:"score"`
+	Findings int    `json:"findings"`
+}
+
+// ParseRiskWeights overrides the default weights with SEVERITY=WEIGHT pairs
+func ParseRiskWeights(pairs []string) (map[string]int, error) {
+	weights := make(map[string]int)
+	for severity, weight := range DefaultRiskWeights {
+		weights[severity] = weight
+	}
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid risk weight : %s. Format should be SEVERITY=WEIGHT", pair)
+		}
+		if !ValidSeverity(parts[0]) {
+			return nil, fmt.Errorf("Invalid severity level : %s. Please use : %s", parts[0], SeveritiesAsString())
+		}
+		weight, err := strconv.Atoi(parts[1])
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("Invalid risk weight : %s. Weight should be a positive integer", pair)
+		}
+		weights[parts[0]] = weight
+	}
+	return weights, nil
+}
+
+// RiskScores sums the weights of the findings of each host.
+// Hosts are sorted from the riskiest to the safest, the resolved findings of a previous run do not count.
+func RiskScores(outputs []Output, weights map[string]int) []HostRisk {
+	byHost := make(map[string]*HostRisk)
+	for _, output := range outputs {
+		if output.Status == StatusResolved {
+			continue
+		}
+		host := hostOf(output.URL)
+		risk, ok := byHost[host]
+		if !ok {
+			risk = &HostRisk{Host: host}
+			byHost[host] = risk
+		}
+		risk.Score += weights[output.Severity]
+		risk.Findings++
+	}
+
+	risks := make([]HostRisk, 0, len(byHost))
+	for _, risk := range byHost {
+		risks = append(risks, *risk)
+	}
+	sort.Slice(risks, func(i, j int) bool {
+		if risks[i].Score != risks[j].Score {
+			return risks[i].Score > risks[j].Score
+		}
+		return risks[i].Host < risks[j].Host
+	})
+	return risks
+}
+
+// hostOf returns the scheme and host of an url, or the url itself if it can't be parsed
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+}
@@ -0,0 +1,57 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"testing"
+)
+
+func TestRiskScores(t *testing.T) {
+	outputs := []core.Output{
+		{URL: "https://a.com/.git/config", Severity: "Low"},
+		{URL: "https://b.com/.env", Severity: "High"},
+		{URL: "https://a.com/server-status", Severity: "Medium"},
+		{URL: "https://b.com/.git/config", Severity: "Informational"},
+	}
+	weights, err := core.ParseRiskWeights([]string{"Informational=1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []core.HostRisk{
+		{Host: "https://b.com", Score: 11, Findings: 2},
+		{Host: "https://a.com", Score: 7, Findings: 2},
+	}
+	have := core.RiskScores(outputs, weights)
+	if len(have) != len(want) {
+		t.Fatalf("expected: %v, got: %v", want, have)
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("expected: %v, got: %v", want[i], have[i])
+		}
+	}
+}
+
+func TestParseRiskWeights(t *testing.T) {
+	var tests = map[string]struct {
+		pairs  []string
+		nilErr bool
+	}{
+		"Valid weights":    {pairs: []string{"High=20", "Low=1"}, nilErr: true},
+		"Unknown severity": {pairs: []string{"Urgent=20"}, nilErr: false},
+		"Negative weight":  {pairs: []string{"High=-1"}, nilErr: false},
+		"Missing weight":   {pairs: []string{"High"}, nilErr: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := core.ParseRiskWeights(tc.pairs)
+			if tc.nilErr && err != nil {
+				t.Errorf("expected a nil error, got : %v", err)
+			}
+			if !tc.nilErr && err == nil {
+				t.Errorf("expected a non-nil error")
+			}
+		})
+	}
+}
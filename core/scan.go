@@ -4,7 +4,13 @@ import (
 	"context"
 	"fmt"
 	"gochopchop/internal"
+	"net/http"
+	"net/http/cookiejar"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -12,16 +18,68 @@ import (
 type SafeData struct {
 	mux sync.Mutex
 	out []Output
+	// orders are the positions in the scan of the checks the findings were found by
+	orders []findingOrder
+}
+
+// findingOrder is the position of a check in the scan: the job, the plugin among the plugins sharing the request, then the check
+type findingOrder [3]int
+
+func (o findingOrder) less(other findingOrder) bool {
+	for i := range o {
+		if o[i] != other[i] {
+			return o[i] < other[i]
+		}
+	}
+	return false
 }
 
 func (s *SafeData) Add(d Output) {
+	s.add(d, findingOrder{})
+}
+
+func (s *SafeData) add(d Output, order findingOrder) {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 	s.out = append(s.out, d)
+	s.orders = append(s.orders, order)
+}
+
+// sorted returns a copy of the findings in the order of the checks that found them, whatever thread ran them first
+func (s *SafeData) sorted() []Output {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	indexes := make([]int, len(s.out))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool { return s.orders[indexes[i]].less(s.orders[indexes[j]]) })
+	out := make([]Output, len(s.out))
+	for i, index := range indexes {
+		out[i] = s.out[index]
+	}
+	return out
+}
+
+// safeURLs is the set of requested urls
+type safeURLs struct {
+	mux  sync.Mutex
+	urls map[string]bool
+}
+
+func (s *safeURLs) Add(url string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.urls[url] = true
+}
+
+// FindingWriter receives the findings as soon as they are found
+type FindingWriter interface {
+	Write(output Output) error
 }
 
 type IFetcher interface {
-	Fetch(url string) (*internal.HTTPResponse, error)
+	Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error)
 }
 
 type IScanner interface {
@@ -33,8 +91,39 @@ type Scanner struct {
 	Fetcher           IFetcher
 	NoRedirectFetcher IFetcher
 	// Two fetchers are needed because we can't use the same http client to follow redirects
-	safeData *SafeData
-	Threads  int
+	safeData      *SafeData
+	requestedURLs *safeURLs
+	// sent counts the HTTP requests, the retries included
+	sent      *int64
+	baselines *baselines
+	Threads   int
+	// BasePath is prepended to every plugin endpoint
+	BasePath string
+	// Writer, when set, streams the findings during the scan
+	Writer FindingWriter
+	// Pauser, when set, can hold the dispatch of the new requests
+	Pauser *Pauser
+	// Limiter, when set, throttles the requests by severity
+	Limiter *SeverityLimiter
+	// HostLimiter, when set, throttles the requests to each host
+	HostLimiter *HostLimiter
+	// HostSemaphore, when set, caps the requests in flight to each host
+	HostSemaphore *HostSemaphore
+	// Concurrency, when set, adapts the number of requests in flight to the error rate of the targets
+	Concurrency *AdaptiveConcurrency
+	// DiscardFindings only sends the findings to the Writer, to scan in a bounded memory
+	DiscardFindings bool
+	// Checkpoint, when set, skips the plugins already run against an url and records the completed ones
+	Checkpoint *Checkpoint
+	// Progress, when set, counts the jobs of the scan and the completed ones
+	Progress *Progress
+	// InsecureTargets are the scanned urls whose TLS certificate is not verified, unless a plugin says otherwise
+	InsecureTargets map[string]bool
+	// CaptureEvidence records in the findings the part of the body their check matched
+	CaptureEvidence bool
+	// DetectBlocking probes each host with a nonexistent path first, to flag the hosts blocking all the requests
+	DetectBlocking bool
+	blockers       *blockers
 }
 
 // NewScanner returns a pointer to a initialized Scanner
@@ -45,16 +134,31 @@ func NewScanner(fetcher IFetcher, noRedirectFetcher IFetcher, signatures *Signat
 		Fetcher:           fetcher,
 		NoRedirectFetcher: noRedirectFetcher,
 		safeData:          safeData,
+		requestedURLs:     &safeURLs{urls: make(map[string]bool)},
+		sent:              new(int64),
+		baselines:         &baselines{hosts: make(map[string]*baseline)},
+		blockers:          &blockers{hosts: make(map[string]*blocker)},
 		Threads:           threads,
 	}
 }
 
 type workerJob struct {
+	// host is the scanned url the job belongs to
+	host     string
 	url      string
 	endpoint string
 	plugin   *Plugin
+	request  *internal.HTTPRequest
+	// shared are the jobs of the other plugins sending the same request, their checks run against the same response
+	shared []workerJob
+	// seq is the position of the job in the scan and part the position of the plugin among the ones sharing its request,
+	// the findings are returned in this order
+	seq  int
+	part int
 }
 
+// Scan runs the plugins against the urls with a pool of Threads workers, so the memory stays flat however many urls there are.
+// The findings are returned in the order of the urls and the plugins, whatever thread found them first.
 func (s Scanner) Scan(ctx context.Context, urls []string) ([]Output, error) {
 	wg := new(sync.WaitGroup)
 	jobs := make(chan workerJob)
@@ -71,79 +175,531 @@ func (s Scanner) Scan(ctx context.Context, urls []string) ([]Output, error) {
 					if !ok { // no more jobs
 						return
 					}
-					resp, err := s.fetch(job.url, job.plugin.FollowRedirects)
-					if err != nil {
-						log.Error(err)
-						break
-					}
-					swg := new(sync.WaitGroup)
-					for _, check := range job.plugin.Checks {
-						swg.Add(1)
-						go func(check *Check) {
-							defer swg.Done()
-							select {
-							case <-ctx.Done():
-								return
-							default:
-								if check.Match(resp) {
-									o := Output{
-										URL:         job.url,
-										Name:        check.Name,
-										Endpoint:    job.endpoint,
-										Severity:    check.Severity,
-										Remediation: check.Remediation,
-									}
-									s.safeData.Add(o)
-								}
-							}
-						}(check)
-					}
-					swg.Wait()
+					s.runJob(ctx, job)
+					s.Progress.done()
 				}
 			}
 		}()
 	}
 
-	for _, url := range urls {
-		for _, plugin := range s.Signatures.Plugins {
-			if plugin.Endpoint != "" {
-				plugin.Endpoints = []string{plugin.Endpoint}
+	// the total is counted up front, for the progress to estimate the time remaining
+	if s.Progress != nil {
+		for _, url := range urls {
+			s.Progress.add(len(s.jobs(url)))
+		}
+	}
+
+	// with a cap per host, the jobs of several urls are interleaved so the threads don't all wait on the same host
+	window := 1
+	if s.HostSemaphore != nil {
+		window = s.Threads
+	}
+	seq := 0
+	for i := 0; i < len(urls); i += window {
+		end := i + window
+		if end > len(urls) {
+			end = len(urls)
+		}
+		for _, w := range s.interleavedJobs(urls[i:end]) {
+			if s.Pauser.Wait(ctx) != nil {
+				break
+			}
+			if len(w.plugin.Steps) > 0 {
+				log.Info("Testing steps of url : ", w.host)
+			} else {
+				log.Info("Testing url : ", w.url)
+			}
+			w.seq = seq
+			for j := range w.shared {
+				w.shared[j].seq = seq
+				w.shared[j].part = j + 1
+			}
+			seq++
+			select {
+			case <-ctx.Done():
+			case jobs <- w:
+			}
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return s.safeData.sorted(), nil
+}
+
+// jobs returns the jobs of the plugins for the scanned url.
+// The plugins sending the same request share a single job, so the request is only sent once for all their checks.
+func (s Scanner) jobs(url string) []workerJob {
+	var jobs []workerJob
+	index := make(map[string]int)
+	for _, plugin := range s.Signatures.Plugins {
+		if len(plugin.Steps) > 0 {
+			if !s.Checkpoint.Completed(url, plugin) {
+				jobs = append(jobs, workerJob{host: url, url: url, plugin: plugin})
 			}
-			for _, e := range plugin.Endpoints {
-				endpoint := e
-				if plugin.QueryString != "" {
-					endpoint = fmt.Sprintf("%s?%s", endpoint, plugin.QueryString)
+			continue
+		}
+		for _, target := range plugin.Targets(url, s.BasePath) {
+			if s.Checkpoint.Completed(target.URL, plugin) {
+				continue
+			}
+			w := workerJob{
+				host:     url,
+				url:      target.URL,
+				endpoint: target.Endpoint,
+				plugin:   plugin,
+				request:  plugin.NewRequest(target.URL),
+			}
+			s.applyTargetOptions(w.request, url)
+			// the default credentials send their own requests
+			if plugin.DefaultCredentials == nil {
+				key := requestKey(w.request, plugin)
+				if i, ok := index[key]; ok {
+					jobs[i].shared = append(jobs[i].shared, w)
+					continue
 				}
-				fullURL := fmt.Sprintf("%s%s", url, endpoint)
-				log.Info("Testing url : ", fullURL)
+				index[key] = len(jobs)
+			}
+			jobs = append(jobs, w)
+		}
+	}
+	return jobs
+}
+
+// interleavedJobs returns the jobs of the urls in turn: the first job of each url, then the second ones, ...
+func (s Scanner) interleavedJobs(urls []string) []workerJob {
+	if len(urls) == 1 {
+		return s.jobs(urls[0])
+	}
+	perURL := make([][]workerJob, len(urls))
+	total := 0
+	for i, url := range urls {
+		perURL[i] = s.jobs(url)
+		total += len(perURL[i])
+	}
+	jobs := make([]workerJob, 0, total)
+	for i := 0; len(jobs) < total; i++ {
+		for _, urlJobs := range perURL {
+			if i < len(urlJobs) {
+				jobs = append(jobs, urlJobs[i])
+			}
+		}
+	}
+	return jobs
+}
+
+// requestKey identifies the requests getting the same response: same method, url, headers and body,
+// sent with the same redirects policy, timeout and TLS verification
+func requestKey(req *internal.HTTPRequest, plugin *Plugin) string {
+	var key strings.Builder
+	fmt.Fprintf(&key, "%s %s redirects=%t max_redirects=%d timeout=%s", req.Method, req.URL, plugin.FollowsRedirects(), req.MaxRedirects, req.Timeout)
+	if req.Insecure != nil {
+		fmt.Fprintf(&key, " insecure=%t", *req.Insecure)
+	}
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&key, "\n%s: %q", name, req.Header[name])
+	}
+	fmt.Fprintf(&key, "\n\n%s", req.Body)
+	return key.String()
+}
+
+// applyTargetOptions skips the TLS verification of the requests to an insecure target,
+// unless the plugin sets its own verification
+func (s Scanner) applyTargetOptions(req *internal.HTTPRequest, host string) {
+	if req.Insecure == nil && s.InsecureTargets[host] {
+		insecure := true
+		req.Insecure = &insecure
+	}
+}
+
+// runJob sends the request of the job and runs the checks of its plugin against the response.
+// The job is recorded in the checkpoint unless the scan was interrupted meanwhile.
+func (s Scanner) runJob(ctx context.Context, job workerJob) {
+	if s.DetectBlocking {
+		s.probeBlocking(ctx, job.host)
+	}
+	s.runJobChecks(ctx, job)
+	if ctx.Err() != nil {
+		return
+	}
+	for _, j := range append([]workerJob{job}, job.shared...) {
+		s.Checkpoint.Done(j.url, j.plugin)
+	}
+}
 
-				w := workerJob{url: fullURL, endpoint: endpoint, plugin: plugin}
+func (s Scanner) runJobChecks(ctx context.Context, job workerJob) {
+	if job.plugin.DefaultCredentials != nil {
+		s.runCredentialsJob(ctx, job)
+		return
+	}
+	var resp *internal.HTTPResponse
+	var err error
+	if len(job.plugin.Steps) > 0 {
+		job, resp, err = s.runSteps(ctx, job)
+	} else {
+		resp, err = s.fetch(ctx, job.request, job.plugin)
+	}
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	all := append([]workerJob{job}, job.shared...)
+	for _, j := range all {
+		s.withBaseline(ctx, j, resp)
+	}
+	swg := new(sync.WaitGroup)
+	for _, j := range all {
+		for _, check := range j.plugin.Checks {
+			swg.Add(1)
+			go func(j workerJob, check *Check) {
+				defer swg.Done()
 				select {
 				case <-ctx.Done():
-					break
-				case jobs <- w:
+					return
+				default:
+					if s.matchRepeated(ctx, j, check, resp) {
+						s.addFinding(j, check, resp, "")
+					}
 				}
+			}(j, check)
+		}
+	}
+	swg.Wait()
+}
+
+// runCredentialsJob tries the default credentials of the plugin with basic authentication,
+// until one of them is accepted or the maximum number of attempts is reached
+func (s Scanner) runCredentialsJob(ctx context.Context, job workerJob) {
+	for i, credential := range job.plugin.DefaultCredentials.Credentials {
+		if i >= job.plugin.DefaultCredentials.Attempts() {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		req := *job.request
+		req.Header = cloneHeader(job.request.Header)
+		req.Header.Set("Authorization", credential.BasicAuthorization())
+		resp, err := s.fetch(ctx, &req, job.plugin)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		s.withBaseline(ctx, job, resp)
+		found := false
+		for _, check := range job.plugin.Checks {
+			if s.match(job, check, resp) {
+				s.addFinding(job, check, resp, fmt.Sprintf("default credentials accepted: %s", credential))
+				found = true
 			}
 		}
+		if found {
+			return
+		}
 	}
+}
 
-	close(jobs)
-	wg.Wait()
+// runSteps sends the requests of the steps in order, injecting the values extracted from the previous responses.
+// It returns the job of the last step along with its response, which the checks run against.
+func (s Scanner) runSteps(ctx context.Context, job workerJob) (workerJob, *internal.HTTPResponse, error) {
+	values := make(map[string]string)
+	// the steps share a session, the cookies set by a response are sent with the next steps
+	jar, _ := cookiejar.New(nil)
+	var resp *internal.HTTPResponse
+	for i, step := range job.plugin.Steps {
+		select {
+		case <-ctx.Done():
+			return job, nil, ctx.Err()
+		default:
+		}
+		req := step.Request(job.url, s.BasePath, values)
+		job.plugin.applyRequestOptions(req)
+		s.applyTargetOptions(req, job.host)
+		addSessionCookies(jar, req)
+		var err error
+		resp, err = s.fetch(ctx, req, job.plugin)
+		if err != nil {
+			return job, nil, err
+		}
+		saveSessionCookies(jar, req, resp)
+		for _, extractor := range step.Extract {
+			value, err := extractor.Extract(resp)
+			if err != nil {
+				return job, nil, fmt.Errorf("step %d of %s: %v", i+1, req.URL, err)
+			}
+			values[extractor.Name] = value
+		}
+		if i == len(job.plugin.Steps)-1 {
+			job = workerJob{
+				host:     job.host,
+				url:      req.URL,
+				endpoint: strings.TrimPrefix(req.URL, job.url),
+				plugin:   job.plugin,
+				request:  req,
+			}
+		}
+	}
+	return job, resp, nil
+}
+
+// addFinding records the finding of the check and streams it to the writer
+func (s Scanner) addFinding(job workerJob, check *Check, resp *internal.HTTPResponse, detail string) {
+	details := check.Details(resp)
+	if detail != "" {
+		if details != "" {
+			details = fmt.Sprintf("%s; %s", detail, details)
+		} else {
+			details = detail
+		}
+	}
+	o := Output{
+		URL:         job.url,
+		Name:        check.Name,
+		Endpoint:    job.endpoint,
+		Severity:    check.Severity,
+		Remediation: check.Remediation,
+		Description: check.Description,
+		Category:    job.plugin.Category,
+		Details:     details,
+		Advisory:    check.Advisory,
+		Confidence:  check.Confidence,
+		References:  check.References,
+		DurationMs:  resp.Duration.Milliseconds(),
+	}
+	if s.CaptureEvidence {
+		o.Evidence = check.Evidence(resp)
+	}
+	o.PossiblyBlocked = s.possiblyBlocked(job.host)
+	// the remediation applies to the page the redirects landed on
+	if job.request != nil && resp.URL != "" && resp.URL != job.request.URL {
+		o.FinalURL = resp.URL
+	}
+	if s.Checkpoint.Restored(o) {
+		return
+	}
+	if !s.DiscardFindings {
+		s.safeData.add(o, findingOrder{job.seq, job.part, job.plugin.checkIndex(check)})
+	}
+	if s.Writer != nil {
+		if err := s.Writer.Write(o); err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+func cloneHeader(header http.Header) http.Header {
+	if header == nil {
+		return make(http.Header)
+	}
+	return header.Clone()
+}
+
+// checkIndex returns the position of the check in the plugin, -1 if it is not one of its checks
+func (p *Plugin) checkIndex(check *Check) int {
+	for i, c := range p.Checks {
+		if c == check {
+			return i
+		}
+	}
+	return -1
+}
+
+// Target is an url requested by a plugin, along with its endpoint relative to the scanned url
+type Target struct {
+	URL      string
+	Endpoint string
+}
+
+// Targets returns the urls requested by the plugin for the scanned url,
+// each endpoint being expanded for the url, prefixed by the base path and followed by the query string.
+// The endpoints of the steps are returned as is, their placeholders are only replaced during the scan.
+func (p *Plugin) Targets(url string, basePath string) []Target {
+	var endpoints []string
+	switch {
+	case len(p.Steps) > 0:
+		for _, step := range p.Steps {
+			endpoints = append(endpoints, step.Endpoint)
+		}
+	case p.Endpoint != "":
+		endpoints = []string{p.Endpoint}
+	default:
+		endpoints = p.Endpoints
+	}
+	queryStrings := []string{""}
+	if len(p.Steps) == 0 {
+		queryStrings = p.queryStrings()
+	}
+	targets := make([]Target, 0, len(endpoints)*len(queryStrings))
+	for _, e := range endpoints {
+		if len(p.Steps) == 0 {
+			e = ExpandEndpoint(e, url)
+		}
+		for _, queryString := range queryStrings {
+			endpoint := JoinBasePath(basePath, e)
+			if queryString != "" {
+				endpoint = fmt.Sprintf("%s?%s", endpoint, queryString)
+			}
+			targets = append(targets, Target{URL: fmt.Sprintf("%s%s", url, endpoint), Endpoint: endpoint})
+		}
+	}
+	return targets
+}
+
+// queryStrings returns the query strings of the plugin, a single empty one when it has none
+func (p *Plugin) queryStrings() []string {
+	if p.QueryString != "" {
+		return []string{p.QueryString}
+	}
+	if len(p.QueryStrings) > 0 {
+		return p.QueryStrings
+	}
+	return []string{""}
+}
+
+// JoinBasePath prepends the base path to the endpoint with a single slash between them.
+// The endpoint is kept as is, eg. its .. segments and trailing slash, so the signatures send what they declare.
+func JoinBasePath(basePath string, endpoint string) string {
+	if basePath == "" {
+		return endpoint
+	}
+	if !strings.HasPrefix(basePath, "/") {
+		basePath = "/" + basePath
+	}
+	return strings.TrimRight(basePath, "/") + "/" + strings.TrimPrefix(endpoint, "/")
+}
+
+// matchRepeated evaluates the check against the response.
+// Checks with a repeat count re-issue the request until enough hits are found.
+func (s Scanner) matchRepeated(ctx context.Context, job workerJob, check *Check, resp *internal.HTTPResponse) bool {
+	if check.Repeat <= 1 {
+		return s.match(job, check, resp)
+	}
+	requireHits := check.RequireHits
+	if requireHits <= 0 {
+		requireHits = 1
+	}
+	hits := 0
+	for i := 0; i < check.Repeat; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return false
+			default:
+			}
+			var err error
+			resp, err = s.fetch(ctx, job.request, job.plugin)
+			if err != nil {
+				log.Error(err)
+				continue
+			}
+			s.withBaseline(ctx, job, resp)
+		}
+		if s.match(job, check, resp) {
+			hits++
+		}
+		if hits >= requireHits {
+			return true
+		}
+		if hits+check.Repeat-i-1 < requireHits {
+			// not enough attempts left to reach the required hits
+			return false
+		}
+	}
+	return false
+}
+
+// match evaluates the check against the response.
+// In debug mode the outcome of each condition is logged to help understanding why a check (doesn't) fire.
+func (s Scanner) match(job workerJob, check *Check, resp *internal.HTTPResponse) bool {
+	if !log.IsLevelEnabled(log.DebugLevel) {
+		return check.Match(resp)
+	}
+	matched := true
+	for _, result := range check.Evaluate(resp) {
+		log.WithFields(log.Fields{
+			"url":       job.url,
+			"check":     check.Name,
+			"condition": result.Condition,
+			"passed":    result.Passed,
+			"detail":    result.Detail,
+		}).Debug("Condition evaluated")
+		matched = matched && result.Passed
+	}
+	log.WithFields(log.Fields{"url": job.url, "check": check.Name, "matched": matched}).Debug("Check evaluated")
+	return matched
+}
+
+// Results returns the findings of the scan so far, eg. to export them when it is interrupted
+func (s Scanner) Results() []Output {
+	return s.safeData.sorted()
+}
+
+// RequestedURLs returns the sorted urls requested during the scan, without duplicates
+func (s Scanner) RequestedURLs() []string {
+	s.requestedURLs.mux.Lock()
+	defer s.requestedURLs.mux.Unlock()
+	urls := make([]string, 0, len(s.requestedURLs.urls))
+	for url := range s.requestedURLs.urls {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls
+}
 
-	return s.safeData.out, nil
+// RequestsSent returns the number of HTTP requests sent so far, the retries and the probes of the hosts included
+func (s Scanner) RequestsSent() int {
+	return int(atomic.LoadInt64(s.sent))
 }
 
-func (s Scanner) fetch(url string, followRedirects bool) (*internal.HTTPResponse, error) {
+func (s Scanner) fetch(ctx context.Context, req *internal.HTTPRequest, plugin *Plugin) (*internal.HTTPResponse, error) {
 	var httpResponse *internal.HTTPResponse
 	var err error
 
-	if !followRedirects {
-		httpResponse, err = s.NoRedirectFetcher.Fetch(url)
-	} else {
-		httpResponse, err = s.Fetcher.Fetch(url)
+	s.requestedURLs.Add(req.URL)
+
+	fetcher := s.Fetcher
+	if !plugin.FollowsRedirects() {
+		fetcher = s.NoRedirectFetcher
+	}
+	var begin time.Time
+	// each attempt is throttled, the retries are sent when the host is already struggling
+	for attempt := 0; ; attempt++ {
+		if err = s.Limiter.Wait(ctx, plugin.Severity()); err != nil {
+			return nil, err
+		}
+		if err = s.HostLimiter.Wait(ctx, req.URL); err != nil {
+			return nil, err
+		}
+		if err = s.HostSemaphore.Acquire(ctx, req.URL); err != nil {
+			return nil, err
+		}
+		if err = s.Concurrency.Acquire(ctx); err != nil {
+			s.HostSemaphore.Release(req.URL)
+			return nil, err
+		}
+		begin = time.Now()
+		atomic.AddInt64(s.sent, 1)
+		httpResponse, err = fetcher.Fetch(req)
+		s.Concurrency.Release(err != nil || httpResponse.StatusCode >= 500 || httpResponse.StatusCode == http.StatusTooManyRequests)
+		s.HostSemaphore.Release(req.URL)
+		if err == nil || attempt >= plugin.Retries() || ctx.Err() != nil {
+			break
+		}
+		log.Debug("Retrying ", req.URL, " after error: ", err)
 	}
 	if err != nil {
 		return nil, err
 	}
-	return httpResponse, nil
+	// the response is copied as the fetcher may return a shared one
+	timed := *httpResponse
+	timed.Duration = time.Since(begin)
+	return &timed, nil
 }
@@ -3,7 +3,11 @@ package core_test
 import (
 	"context"
 	"gochopchop/core"
+	"gochopchop/internal"
 	"gochopchop/mock"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -50,3 +54,392 @@ func TestScan(t *testing.T) {
 		})
 	}
 }
+
+type flakyFetcher struct {
+	mux   sync.Mutex
+	calls int
+}
+
+// Fetch only returns a matching response every third call
+func (f *flakyFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.calls++
+	if f.calls%3 == 0 {
+		return &internal.HTTPResponse{StatusCode: 200, Body: "FLAKY"}, nil
+	}
+	return &internal.HTTPResponse{StatusCode: 200}, nil
+}
+
+func TestScanRepeat(t *testing.T) {
+	var tests = map[string]struct {
+		repeat      int
+		requireHits int
+		want        int
+	}{
+		"no repeat":              {repeat: 0, want: 0},
+		"repeat until one hit":   {repeat: 3, want: 1},
+		"not enough hits":        {repeat: 3, requireHits: 2, want: 0},
+		"enough hits":            {repeat: 6, requireHits: 2, want: 1},
+		"not enough repetitions": {repeat: 2, want: 0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := &flakyFetcher{}
+			check := &core.Check{Name: "Flaky", Severity: "Low", MustMatchAll: []string{"FLAKY"}, Repeat: tc.repeat, RequireHits: tc.requireHits}
+			signatures := &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{check}}}}
+			scanner := core.NewScanner(fetcher, fetcher, signatures, 1)
+			output, _ := scanner.Scan(context.Background(), []string{"http://flaky"})
+			if len(output) != tc.want {
+				t.Errorf("expected: %v findings, got: %v", tc.want, len(output))
+			}
+		})
+	}
+}
+
+func TestJoinBasePath(t *testing.T) {
+	var tests = map[string]struct {
+		basePath string
+		endpoint string
+		want     string
+	}{
+		"No base path":           {basePath: "", endpoint: "/.git/config", want: "/.git/config"},
+		"Base path":              {basePath: "/app", endpoint: "/.git/config", want: "/app/.git/config"},
+		"Base path with slashes": {basePath: "app/", endpoint: "/.git/config", want: "/app/.git/config"},
+		"Root endpoint":          {basePath: "/app/", endpoint: "/", want: "/app/"},
+		"Trailing slash kept":    {basePath: "/app", endpoint: "/admin/", want: "/app/admin/"},
+		"Traversal kept":         {basePath: "/app", endpoint: "/../../etc/passwd", want: "/app/../../etc/passwd"},
+		"Dot segment kept":       {basePath: "/app/", endpoint: "/./admin/./", want: "/app/./admin/./"},
+		"Double slash kept":      {basePath: "/app", endpoint: "//admin//", want: "/app//admin//"},
+		"Endpoint without slash": {basePath: "/app", endpoint: "admin", want: "/app/admin"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := core.JoinBasePath(tc.basePath, tc.endpoint)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestPluginTargets(t *testing.T) {
+	var tests = map[string]struct {
+		plugin   *core.Plugin
+		basePath string
+		want     []core.Target
+	}{
+		"Endpoint":      {plugin: &core.Plugin{Endpoint: "/.git/config"}, want: []core.Target{{URL: "http://foo/.git/config", Endpoint: "/.git/config"}}},
+		"Endpoints":     {plugin: &core.Plugin{Endpoints: []string{"/a", "/b"}}, want: []core.Target{{URL: "http://foo/a", Endpoint: "/a"}, {URL: "http://foo/b", Endpoint: "/b"}}},
+		"Query string":  {plugin: &core.Plugin{Endpoint: "/search", QueryString: "q=chopchop"}, want: []core.Target{{URL: "http://foo/search?q=chopchop", Endpoint: "/search?q=chopchop"}}},
+		"Base path":     {plugin: &core.Plugin{Endpoint: "/", QueryString: "id=1"}, basePath: "/app", want: []core.Target{{URL: "http://foo/app/?id=1", Endpoint: "/app/?id=1"}}},
+		"Query strings": {plugin: &core.Plugin{Endpoints: []string{"/a", "/b"}, QueryStrings: []string{"id=1", "id=2"}}, want: []core.Target{{URL: "http://foo/a?id=1", Endpoint: "/a?id=1"}, {URL: "http://foo/a?id=2", Endpoint: "/a?id=2"}, {URL: "http://foo/b?id=1", Endpoint: "/b?id=1"}, {URL: "http://foo/b?id=2", Endpoint: "/b?id=2"}}},
+		"Steps":         {plugin: &core.Plugin{Steps: []*core.Step{{Endpoint: "/login"}, {Endpoint: "/admin?token={{token}}"}}}, want: []core.Target{{URL: "http://foo/login", Endpoint: "/login"}, {URL: "http://foo/admin?token={{token}}", Endpoint: "/admin?token={{token}}"}}},
+		"Placeholders":  {plugin: &core.Plugin{Endpoint: "/users/{host}/config"}, want: []core.Target{{URL: "http://foo/users/foo/config", Endpoint: "/users/foo/config"}}},
+		"No endpoint":   {plugin: &core.Plugin{}, want: []core.Target{}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := tc.plugin.Targets("http://foo", tc.basePath)
+			if !reflect.DeepEqual(have, tc.want) {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestScanRequestedURLs(t *testing.T) {
+	fetcher := &flakyFetcher{}
+	plugins := []*core.Plugin{
+		{Endpoints: []string{"/b", "/a"}, Checks: []*core.Check{{Name: "A"}}},
+		{Endpoint: "/a", Checks: []*core.Check{{Name: "B"}}},
+	}
+	scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: plugins}, 2)
+	scanner.Scan(context.Background(), []string{"http://problems"})
+
+	want := []string{"http://problems/a", "http://problems/b"}
+	have := scanner.RequestedURLs()
+	if !core.SliceStringEqual(have, want) {
+		t.Errorf("expected: %v, got: %v", want, have)
+	}
+}
+
+func TestScanRequestsSent(t *testing.T) {
+	plugins := []*core.Plugin{{Endpoints: []string{"/a", "/b"}, Checks: []*core.Check{{Name: "A"}}}}
+	scanner := core.NewScanner(slowFetcher(0), slowFetcher(0), &core.Signatures{Plugins: plugins}, 2)
+	scanner.Scan(context.Background(), []string{"http://problems", "http://other"})
+
+	if have := scanner.RequestsSent(); have != 4 {
+		t.Errorf("expected: %v, got: %v", 4, have)
+	}
+}
+
+type slowFetcher time.Duration
+
+func (f slowFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	time.Sleep(time.Duration(f))
+	return &internal.HTTPResponse{StatusCode: 200}, nil
+}
+
+func TestScanDuration(t *testing.T) {
+	fetcher := slowFetcher(20 * time.Millisecond)
+	plugins := []*core.Plugin{{Endpoint: "/slow", Checks: []*core.Check{{Name: "Slow"}}}}
+	scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: plugins}, 1)
+
+	output, _ := scanner.Scan(context.Background(), []string{"http://problems"})
+	if len(output) != 1 {
+		t.Fatalf("expected: 1 finding, got: %v", output)
+	}
+	if output[0].DurationMs < 20 {
+		t.Errorf("expected: at least 20ms, got: %vms", output[0].DurationMs)
+	}
+}
+
+type countingFetcher struct {
+	mux   sync.Mutex
+	calls map[string]int
+}
+
+func (f *countingFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.calls[req.URL]++
+	return &internal.HTTPResponse{StatusCode: 200}, nil
+}
+
+func TestScanSharedRequests(t *testing.T) {
+	enabled := createBool(true)
+	var tests = map[string]struct {
+		plugins   []*core.Plugin
+		wantCalls int
+	}{
+		"same endpoint":          {plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{{Name: "A"}}}, {Endpoints: []string{"/"}, Checks: []*core.Check{{Name: "B"}}}}, wantCalls: 1},
+		"different redirects":    {plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{{Name: "A"}}}, {Endpoint: "/", FollowRedirects: true, Checks: []*core.Check{{Name: "B"}}}}, wantCalls: 2},
+		"redirects in a block":   {plugins: []*core.Plugin{{Endpoint: "/", FollowRedirects: true, Checks: []*core.Check{{Name: "A"}}}, {Endpoint: "/", Request: &core.RequestOptions{FollowRedirects: enabled}, Checks: []*core.Check{{Name: "B"}}}}, wantCalls: 1},
+		"different methods":      {plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{{Name: "A"}}}, {Endpoint: "/", Method: "OPTIONS", Checks: []*core.Check{{Name: "B"}}}}, wantCalls: 2},
+		"different headers":      {plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{{Name: "A"}}}, {Endpoint: "/", RequestHeaders: []string{"X-Forwarded-For: 127.0.0.1"}, Checks: []*core.Check{{Name: "B"}}}}, wantCalls: 2},
+		"different query string": {plugins: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{{Name: "A"}}}, {Endpoint: "/", QueryString: "id=1", Checks: []*core.Check{{Name: "B"}}}}, wantCalls: 2},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := &countingFetcher{calls: make(map[string]int)}
+			scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: tc.plugins}, 2)
+			output, _ := scanner.Scan(context.Background(), []string{"http://problems"})
+			if len(output) != 2 {
+				t.Errorf("expected: 2 findings, got: %v", output)
+			}
+			calls := 0
+			for _, n := range fetcher.calls {
+				calls += n
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("expected: %v requests, got: %v", tc.wantCalls, calls)
+			}
+		})
+	}
+}
+
+// inFlightFetcher records the maximum of requests in flight to each host
+type inFlightFetcher struct {
+	mux      sync.Mutex
+	inFlight map[string]int
+	max      map[string]int
+}
+
+func (f *inFlightFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	host := strings.SplitN(strings.TrimPrefix(req.URL, "http://"), "/", 2)[0]
+	f.mux.Lock()
+	f.inFlight[host]++
+	if f.inFlight[host] > f.max[host] {
+		f.max[host] = f.inFlight[host]
+	}
+	f.mux.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	f.mux.Lock()
+	f.inFlight[host]--
+	f.mux.Unlock()
+	return &internal.HTTPResponse{StatusCode: 200}, nil
+}
+
+func TestScanMaxPerHost(t *testing.T) {
+	fetcher := &inFlightFetcher{inFlight: make(map[string]int), max: make(map[string]int)}
+	plugins := []*core.Plugin{{Endpoints: []string{"/a", "/b", "/c", "/d", "/e", "/f"}, Checks: []*core.Check{{Name: "A"}}}}
+	scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: plugins}, 6)
+	scanner.HostSemaphore = core.NewHostSemaphore(2)
+
+	output, _ := scanner.Scan(context.Background(), []string{"http://first", "http://second", "http://third"})
+	if len(output) != 18 {
+		t.Errorf("expected: 18 findings, got: %v", len(output))
+	}
+	for _, host := range []string{"first", "second", "third"} {
+		if fetcher.max[host] > 2 {
+			t.Errorf("expected: at most 2 requests in flight to %s, got: %v", host, fetcher.max[host])
+		}
+	}
+}
+
+// redirectFetcher answers every request with the page the redirects of /login land on
+type redirectFetcher struct{}
+
+func (f redirectFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	finalURL := req.URL
+	if strings.HasSuffix(req.URL, "/login") {
+		finalURL = "https://sso.foobar/login"
+	}
+	return &internal.HTTPResponse{URL: finalURL, StatusCode: 200}, nil
+}
+
+func TestScanFinalURL(t *testing.T) {
+	var ok int32 = 200
+	signatures := &core.Signatures{Plugins: []*core.Plugin{{
+		Endpoints:       []string{"/login", "/admin"},
+		FollowRedirects: true,
+		Checks:          []*core.Check{{Name: "Page found", Severity: "Low", StatusCode: &ok}},
+	}}}
+	scanner := core.NewScanner(redirectFetcher{}, redirectFetcher{}, signatures, 1)
+
+	output, _ := scanner.Scan(context.Background(), []string{"http://foobar"})
+	finalURLs := make(map[string]string)
+	for _, o := range output {
+		finalURLs[o.Endpoint] = o.FinalURL
+	}
+	want := map[string]string{"/login": "https://sso.foobar/login", "/admin": ""}
+	if !reflect.DeepEqual(finalURLs, want) {
+		t.Errorf("expected: %v, got: %v", want, finalURLs)
+	}
+}
+
+// insecureFetcher records the TLS verification asked for each requested url
+type insecureFetcher struct {
+	mux      sync.Mutex
+	insecure map[string]*bool
+}
+
+func (f *insecureFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.insecure[req.URL] = req.Insecure
+	return &internal.HTTPResponse{StatusCode: 200}, nil
+}
+
+func TestScanInsecureTargets(t *testing.T) {
+	var tests = map[string]struct {
+		request  *core.RequestOptions
+		insecure map[string]*bool
+	}{
+		"insecure target": {insecure: map[string]*bool{"https://self-signed/": createBool(true), "https://trusted/": nil}},
+		"plugin override": {request: &core.RequestOptions{Insecure: createBool(false)}, insecure: map[string]*bool{"https://self-signed/": createBool(false), "https://trusted/": createBool(false)}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := &insecureFetcher{insecure: make(map[string]*bool)}
+			plugins := []*core.Plugin{{Endpoint: "/", Request: tc.request, Checks: []*core.Check{{Name: "A"}}}}
+			scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: plugins}, 2)
+			scanner.InsecureTargets = map[string]bool{"https://self-signed": true}
+			scanner.Scan(context.Background(), []string{"https://self-signed", "https://trusted"})
+			if !reflect.DeepEqual(fetcher.insecure, tc.insecure) {
+				t.Errorf("expected: %v, got: %v", tc.insecure, fetcher.insecure)
+			}
+		})
+	}
+}
+
+// delayFetcher answers the requests of each host after its delay
+type delayFetcher map[string]time.Duration
+
+func (f delayFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	for host, delay := range f {
+		if strings.HasPrefix(req.URL, host) {
+			time.Sleep(delay)
+		}
+	}
+	return &internal.HTTPResponse{StatusCode: 200}, nil
+}
+
+func TestScanOrder(t *testing.T) {
+	fetcher := delayFetcher{"http://slow": 40 * time.Millisecond, "http://medium": 20 * time.Millisecond}
+	plugins := []*core.Plugin{
+		{Endpoint: "/", Checks: []*core.Check{{Name: "A"}, {Name: "B"}}},
+		{Endpoint: "/admin", Checks: []*core.Check{{Name: "C"}}},
+	}
+	scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: plugins}, 4)
+	output, _ := scanner.Scan(context.Background(), []string{"http://slow", "http://medium", "http://fast"})
+
+	want := []string{"http://slow/ A", "http://slow/ B", "http://slow/admin C", "http://medium/ A", "http://medium/ B", "http://medium/admin C", "http://fast/ A", "http://fast/ B", "http://fast/admin C"}
+	have := make([]string, len(output))
+	for i, o := range output {
+		have[i] = o.URL + " " + o.Name
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("expected: %v, got: %v", want, have)
+	}
+	if !reflect.DeepEqual(scanner.Results(), output) {
+		t.Errorf("expected: %v, got: %v", output, scanner.Results())
+	}
+}
+
+// wafFetcher refuses every request to the hosts behind the WAF, the other hosts only have the /admin page
+type wafFetcher map[string]bool
+
+func (f wafFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	for host := range f {
+		if strings.HasPrefix(req.URL, host) {
+			return &internal.HTTPResponse{StatusCode: 403, Body: "Request blocked"}, nil
+		}
+	}
+	if strings.HasSuffix(req.URL, "/admin") {
+		return &internal.HTTPResponse{StatusCode: 200}, nil
+	}
+	return &internal.HTTPResponse{StatusCode: 404}, nil
+}
+
+func TestScanDetectBlocking(t *testing.T) {
+	fetcher := wafFetcher{"http://waf": true}
+	plugins := []*core.Plugin{{Endpoint: "/admin", Checks: []*core.Check{{Name: "Admin", StatusCodeIn: []string{"2xx", "403"}}}}}
+	scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: plugins}, 2)
+	scanner.DetectBlocking = true
+	output, _ := scanner.Scan(context.Background(), []string{"http://waf", "http://open"})
+
+	want := map[string]bool{"http://waf/admin": true, "http://open/admin": false}
+	have := make(map[string]bool)
+	for _, o := range output {
+		have[o.URL] = o.PossiblyBlocked
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("expected: %v, got: %v", want, have)
+	}
+	if blocked := scanner.BlockedHosts(); !reflect.DeepEqual(blocked, []string{"http://waf"}) {
+		t.Errorf("expected: %v, got: %v", []string{"http://waf"}, blocked)
+	}
+}
+
+// traversalFetcher only leaks the passwd file to one of the traversal variants
+type traversalFetcher struct{}
+
+func (f traversalFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	if strings.HasSuffix(req.URL, "file=....//....//etc/passwd") {
+		return &internal.HTTPResponse{StatusCode: 200, Body: "root:x:0:0:root:/root:/bin/bash"}, nil
+	}
+	return &internal.HTTPResponse{StatusCode: 400, Body: "invalid file"}, nil
+}
+
+func TestScanQueryStrings(t *testing.T) {
+	plugins := []*core.Plugin{{
+		Endpoint:     "/download",
+		QueryStrings: []string{"file=../../etc/passwd", "file=....//....//etc/passwd"},
+		Checks:       []*core.Check{{Name: "Path traversal", MustMatchOne: []string{"root:x:0:0"}}},
+	}}
+	scanner := core.NewScanner(traversalFetcher{}, traversalFetcher{}, &core.Signatures{Plugins: plugins}, 2)
+	output, _ := scanner.Scan(context.Background(), []string{"http://problems"})
+	if len(output) != 1 {
+		t.Fatalf("expected: 1 finding, got: %v", output)
+	}
+	if want := "http://problems/download?file=....//....//etc/passwd"; output[0].URL != want {
+		t.Errorf("expected: %v, got: %v", want, output[0].URL)
+	}
+}
@@ -2,7 +2,7 @@ package core
 
 import "strings"
 
-var severities = [4]string{"High", "Medium", "Low", "Informational"}
+var severities = [5]string{"Critical", "High", "Medium", "Low", "Informational"}
 
 func ValidSeverity(severity string) bool {
 	for _, sv := range severities {
@@ -13,28 +13,27 @@ func ValidSeverity(severity string) bool {
 	return false
 }
 
+// SeverityRank returns the position of the severity in the ordering, 0 being the most critical.
+// Unknown severities are ranked last.
+func SeverityRank(severity string) int {
+	for i, sv := range severities {
+		if severity == sv {
+			return i
+		}
+	}
+	return len(severities)
+}
+
+// Severities returns the severities from the most to the least critical
+func Severities() []string {
+	return append([]string(nil), severities[:]...)
+}
+
 func SeveritiesAsString() string {
 	return strings.Join(severities[:], ", ")
 }
 
+// SeverityReached tells whether the severity is as or more critical than the max severity
 func SeverityReached(max string, severity string) bool {
-	switch max {
-	case "High":
-		if severity == "High" {
-			return true
-		}
-	case "Medium":
-		if severity == "High" || severity == "Medium" {
-			return true
-		}
-	case "Low":
-		if severity == "High" || severity == "Medium" || severity == "Low" {
-			return true
-		}
-	case "Informational":
-		if severity == "High" || severity == "Medium" || severity == "Low" || severity == "Informational" {
-			return true
-		}
-	}
-	return false
+	return ValidSeverity(max) && ValidSeverity(severity) && SeverityRank(severity) <= SeverityRank(max)
 }
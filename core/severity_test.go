@@ -1,8 +1,8 @@
 package core_test
 
 import (
-	"testing"
 	"gochopchop/core"
+	"testing"
 )
 
 func TestValidSeverity(t *testing.T) {
@@ -10,6 +10,7 @@ func TestValidSeverity(t *testing.T) {
 		severity string
 		want     bool
 	}{
+		"Critical":      {severity: "Critical", want: true},
 		"High":          {severity: "High", want: true},
 		"Medium":        {severity: "Medium", want: true},
 		"Low":           {severity: "Low", want: true},
@@ -28,7 +29,7 @@ func TestValidSeverity(t *testing.T) {
 }
 
 func TestSeveritiesAsString(t *testing.T) {
-	want := "High, Medium, Low, Informational"
+	want := "Critical, High, Medium, Low, Informational"
 	have := core.SeveritiesAsString()
 	if have != want {
 		t.Errorf("expected: %v, got: %v", want, have)
@@ -41,13 +42,16 @@ func TestSeverityReached(t *testing.T) {
 		severity string
 		want     bool
 	}{
-		"HighNotReached":       {max: "High", severity: "Informational", want: false},
-		"HighReached":          {max: "High", severity: "High", want: true},
-		"MediumReached":        {max: "Medium", severity: "High", want: true},
-		"MediumNotReached":     {max: "Medium", severity: "Low", want: false},
-		"LowReached":           {max: "Low", severity: "High", want: true},
-		"LowNotReached":        {max: "Low", severity: "Informational", want: false},
-		"InformationalReached": {max: "Informational", severity: "Informational", want: true},
+		"CriticalReached":       {max: "Critical", severity: "Critical", want: true},
+		"CriticalNotReached":    {max: "Critical", severity: "High", want: false},
+		"HighReachedByCritical": {max: "High", severity: "Critical", want: true},
+		"HighNotReached":        {max: "High", severity: "Informational", want: false},
+		"HighReached":           {max: "High", severity: "High", want: true},
+		"MediumReached":         {max: "Medium", severity: "High", want: true},
+		"MediumNotReached":      {max: "Medium", severity: "Low", want: false},
+		"LowReached":            {max: "Low", severity: "High", want: true},
+		"LowNotReached":         {max: "Low", severity: "Informational", want: false},
+		"InformationalReached":  {max: "Informational", severity: "Informational", want: true},
 	}
 
 	for name, tc := range tests {
@@ -59,3 +63,15 @@ func TestSeverityReached(t *testing.T) {
 		})
 	}
 }
+
+func TestSeverityRank(t *testing.T) {
+	if core.SeverityRank("Critical") >= core.SeverityRank("High") {
+		t.Errorf("expected Critical to be ranked before High")
+	}
+	if core.SeverityRank("High") >= core.SeverityRank("Medium") {
+		t.Errorf("expected High to be ranked before Medium")
+	}
+	if core.SeverityRank("Unknown") <= core.SeverityRank("Informational") {
+		t.Errorf("expected unknown severities to be ranked last")
+	}
+}
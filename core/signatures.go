@@ -1,35 +1,126 @@
 package core
 
 import (
-	"gochopchop/internal"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"regexp"
 	"strings"
 )
 
 // Signature struct to load the plugins/rules from the YAML file
 type Signatures struct {
 	Plugins []*Plugin `yaml:"plugins"`
+	// Insecure is the top-level setting of the previous signature files, ignored in favour of the --insecure flag.
+	// It is kept so these files still pass lint.
+	Insecure bool `yaml:"insecure"`
+	// Files the plugins were loaded from
+	Files []string `yaml:"-"`
 }
 
 type Plugin struct {
-	Endpoints       []string `yaml:"endpoints"`
-	Endpoint        string   `yaml:"endpoint"`
-	QueryString     string   `yaml:"query_string"`
+	Endpoints   []string `yaml:"endpoints"`
+	Endpoint    string   `yaml:"endpoint"`
+	QueryString string   `yaml:"query_string"`
+	// QueryStrings are variants of the query string, each one sent in its own request to the endpoints
+	QueryStrings    []string `yaml:"query_strings"`
 	Checks          []*Check `yaml:"checks"`
 	FollowRedirects bool     `yaml:"follow_redirects"`
+	Category        string   `yaml:"category"`
+	// Tags select the plugins to run with --tags and --exclude-tags, eg. cms or exposure
+	Tags []string `yaml:"tags"`
+	// Method of the request (default: GET) and its Body, eg. for POST or PUT
+	Method string `yaml:"method"`
+	Body   string `yaml:"body"`
+	// RequestHeaders are sent with the requests of the plugin, as "Key: Value"
+	RequestHeaders []string `yaml:"request_headers"`
+	requestHeaders http.Header
+	// Timeout in seconds of the requests of the plugin, overriding the --timeout flag when set
+	Timeout int `yaml:"timeout"`
+	// DefaultCredentials, when set, are tried with basic authentication instead of a single anonymous request
+	DefaultCredentials *DefaultCredentials `yaml:"default_credentials"`
+	// Steps, when set, replace the endpoint by a sequence of requests
+	Steps []*Step `yaml:"steps"`
+	// Request configures the requests of the plugin, it takes precedence over the legacy fields
+	Request *RequestOptions `yaml:"request"`
+	// Dir is the directory of the signature file of the plugin, the match_file and default_credentials paths are relative to
+	Dir string `yaml:"-"`
 }
 
 // Check Signature
 type Check struct {
-	MustMatchOne []string `yaml:"match"`
-	MustMatchAll []string `yaml:"all_match"`
-	MustNotMatch []string `yaml:"no_match"`
-	StatusCode   *int32   `yaml:"status_code"`
-	Name         string   `yaml:"name"`
-	Remediation  string   `yaml:"remediation"`
-	Severity     string   `yaml:"severity"`
-	Description  string   `yaml:"description"`
-	Headers      []string `yaml:"headers"`
-	NoHeaders    []string `yaml:"no_headers"`
+	MustMatchOne []string     `yaml:"match"`
+	MustMatchAll []string     `yaml:"all_match"`
+	MustNotMatch []string     `yaml:"no_match"`
+	StatusCode   *int32       `yaml:"status_code"`
+	Name         string       `yaml:"name"`
+	Remediation  string       `yaml:"remediation"`
+	Severity     string       `yaml:"severity"`
+	Description  string       `yaml:"description"`
+	Headers      []string     `yaml:"headers"`
+	NoHeaders    []string     `yaml:"no_headers"`
+	Cookie       *CookieCheck `yaml:"cookie"`
+	MatchFile    string       `yaml:"match_file"`
+	EmptyBody    bool         `yaml:"empty_body"`
+	NonEmptyBody bool         `yaml:"non_empty_body"`
+	// BodyEquals is the exact body of the response, eg. a whitelisted "OK" page. TrimBody ignores the trailing whitespace
+	// of the body for BodyEquals, EmptyBody and NonEmptyBody
+	BodyEquals *string `yaml:"body_equals"`
+	TrimBody   bool    `yaml:"trim_body"`
+	// StatusCodeNot is a status code the response must not have, eg. 404.
+	// StatusCodeIn lists the accepted status codes or classes of status codes, eg. [200, 3xx]
+	StatusCodeNot *int32   `yaml:"status_code_not"`
+	StatusCodeIn  []string `yaml:"status_code_in"`
+	// InitialStatusCode is the status code of the first response when the redirects are followed, eg. 301 to a sensitive location
+	InitialStatusCode *int32 `yaml:"initial_status_code"`
+	// MinBodySize and MaxBodySize bound the body size in bytes, either one may be omitted
+	MinBodySize *int `yaml:"min_body_size"`
+	MaxBodySize *int `yaml:"max_body_size"`
+	// WWWAuthenticate asserts the authentication scheme/realm asked by the response
+	WWWAuthenticate *WWWAuthenticateCheck `yaml:"www_authenticate"`
+	// Repeat the request N times and require at least RequireHits matches (default 1)
+	Repeat      int `yaml:"repeat"`
+	RequireHits int `yaml:"require_hits"`
+	// MatchFileContent is loaded from MatchFile along with the signatures
+	MatchFileContent string `yaml:"-"`
+	// Advisory checks are reported but never block the CI, whatever their severity
+	Advisory bool `yaml:"advisory"`
+	// Enabled is true unless set, a disabled check is kept in the signatures but not run
+	Enabled *bool `yaml:"enabled"`
+	// ContentType and NotContentType assert on the media type of the response, eg. text/plain rather than an HTML error page
+	ContentType    string `yaml:"content_type"`
+	NotContentType string `yaml:"not_content_type"`
+	// Confidence tells how certain a match is (High, Medium or Low), eg. a status code alone is weaker than a unique body string
+	Confidence string `yaml:"confidence"`
+	// MixedContent flags the http:// resources loaded by an HTTPS page
+	MixedContent bool `yaml:"mixed_content"`
+	// References are links documenting the issue, included in the reports
+	References []string `yaml:"references"`
+	// ServerVersion flags the outdated versions announced by the Server header
+	ServerVersion *ServerVersionCheck `yaml:"server_version"`
+	// SizeRatio flags the bodies at least this many times larger (or smaller) than the baseline of the host
+	SizeRatio float64 `yaml:"size_ratio"`
+	// JSONMatch asserts a value of the JSON responses
+	JSONMatch *JSONMatchCheck `yaml:"json_match"`
+	// TLS flags the weak TLS posture of the HTTPS responses (expired or self-signed certificate, old protocol)
+	TLS *TLSCheck `yaml:"tls"`
+	// Regex variants of match, all_match and no_match, compiled by CompileRegexes
+	MatchRegex    []string `yaml:"match_regex"`
+	AllMatchRegex []string `yaml:"all_match_regex"`
+	NoMatchRegex  []string `yaml:"no_match_regex"`
+	// HeadersRegex and NoHeadersRegex match the values of a response header, as "KEY: REGEX"
+	HeadersRegex   []string `yaml:"headers_regex"`
+	NoHeadersRegex []string `yaml:"no_headers_regex"`
+	// CaseInsensitive compares the match, all_match and no_match terms to the body regardless of the case
+	CaseInsensitive bool `yaml:"case_insensitive"`
+	// Conditions is a tree of and, or and not groups over the body, header and status predicates, matched along with the other fields
+	Conditions     *Condition `yaml:"conditions"`
+	matchRegex     []*regexp.Regexp
+	allMatchRegex  []*regexp.Regexp
+	noMatchRegex   []*regexp.Regexp
+	headersRegex   []*regexp.Regexp
+	noHeadersRegex []*regexp.Regexp
 }
 
 // NewSignatures returns a new initialized Signatures
@@ -37,12 +128,39 @@ func NewSignatures() *Signatures {
 	return &Signatures{}
 }
 
+// FilterBySeverity only keeps the checks of exactly this severity
 func (s *Signatures) FilterBySeverity(severity string) {
+	s.filterChecks(func(check *Check) bool {
+		return check.Severity == severity
+	})
+}
+
+// FilterByMinSeverity only keeps the checks as or more critical than the severity
+func (s *Signatures) FilterByMinSeverity(severity string) {
+	s.filterChecks(func(check *Check) bool {
+		return SeverityReached(severity, check.Severity)
+	})
+}
+
+// FilterDisabled removes the disabled checks
+func (s *Signatures) FilterDisabled() {
+	s.filterChecks(func(check *Check) bool {
+		return check.IsEnabled()
+	})
+}
+
+// IsEnabled tells whether the check is run, which is the default
+func (check *Check) IsEnabled() bool {
+	return check.Enabled == nil || *check.Enabled
+}
+
+// filterChecks only keeps the checks to keep, and the plugins having some left
+func (s *Signatures) filterChecks(keep func(check *Check) bool) {
 	filteredPlugins := s.Plugins[:0]
 	for _, plugin := range s.Plugins {
 		filteredChecks := plugin.Checks[:0]
 		for _, check := range plugin.Checks {
-			if check.Severity == severity {
+			if keep(check) {
 				filteredChecks = append(filteredChecks, check)
 			}
 		}
@@ -74,87 +192,61 @@ func (s *Signatures) FilterByNames(names []string) {
 	s.Plugins = filteredPlugins
 }
 
-//Match analyses the HTTP Request
-// a match means that one of the criteria has been met
-func (check *Check) Match(resp *internal.HTTPResponse) bool {
-	// status code must match
-	if check.StatusCode != nil {
-		if int32(resp.StatusCode) != *check.StatusCode {
-			return false
-		}
-	}
+// FilterByTags only keeps the plugins having at least one of the tags
+func (s *Signatures) FilterByTags(tags []string) {
+	s.filterPlugins(func(plugin *Plugin) bool {
+		return plugin.HasTag(tags)
+	})
+}
 
-	// all element must be found
-	for _, match := range check.MustMatchAll {
-		if !strings.Contains(resp.Body, match) {
-			return false
-		}
-	}
+// FilterByExcludedTags removes the plugins having any of the tags
+func (s *Signatures) FilterByExcludedTags(tags []string) {
+	s.filterPlugins(func(plugin *Plugin) bool {
+		return !plugin.HasTag(tags)
+	})
+}
 
-	// one element must be found
-	if len(check.MustMatchOne) > 0 {
-		found := false
-		for _, match := range check.MustMatchOne {
-			if strings.Contains(resp.Body, match) {
-				found = true
-			}
-		}
-		if !found {
-			return false
+func (s *Signatures) filterPlugins(keep func(plugin *Plugin) bool) {
+	filteredPlugins := s.Plugins[:0]
+	for _, plugin := range s.Plugins {
+		if keep(plugin) {
+			filteredPlugins = append(filteredPlugins, plugin)
 		}
 	}
+	s.Plugins = filteredPlugins
+}
 
-	// no element should match
-	if len(check.MustNotMatch) > 0 {
-		for _, match := range check.MustNotMatch {
-			if strings.Contains(resp.Body, match) {
-				return false
+// HasTag tells whether the plugin has one of the tags, ignoring the case
+func (p *Plugin) HasTag(tags []string) bool {
+	for _, tag := range tags {
+		for _, t := range p.Tags {
+			if strings.EqualFold(strings.TrimSpace(tag), t) {
+				return true
 			}
 		}
 	}
+	return false
+}
 
-	// must contain all these headers
-	for _, header := range check.Headers {
-		pHeaders := strings.Split(header, ":")
-		pHeadersKey := pHeaders[0]
-		pHeadersValue := pHeaders[1]
-		if respHeaderValues, kFound := resp.Header[pHeadersKey]; kFound {
-			vFound := false
-			for _, respHeaderValue := range respHeaderValues {
-				if strings.Contains(respHeaderValue, pHeadersValue) {
-					vFound = true
-					break
-				}
-			}
-			if !vFound {
-				return false
-			}
-		} else {
-			return false
-		}
+// LoadMatchFile reads the reference file of the check so it is only read once.
+// A relative path is resolved from dir, usually the directory of the signature file.
+func (check *Check) LoadMatchFile(dir string) error {
+	if check.MatchFile == "" {
+		return nil
 	}
-
-	// must not contain these headers
-	for _, header := range check.NoHeaders {
-		pNoHeaders := strings.Split(header, ":")
-		pNoHeadersKey := pNoHeaders[0]
-		if respHeaderValues, kFound := resp.Header[pNoHeadersKey]; kFound {
-			if len(pNoHeaders) > 1 {
-				pHeadersValue := pNoHeaders[1]
-				vFound := false
-				for _, respHeaderValue := range respHeaderValues {
-					if strings.Contains(respHeaderValue, pHeadersValue) {
-						vFound = true
-						break
-					}
-				}
-				if vFound {
-					return false
-				}
-			}
-		}
+	path := check.MatchFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
 	}
-	return true
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read match_file of %s check: %v", check.Name, err)
+	}
+	if len(content) == 0 {
+		return fmt.Errorf("match_file %s of %s check is empty", path, check.Name)
+	}
+	check.MatchFileContent = string(content)
+	return nil
 }
 
 func (self *Signatures) Equals(signatures *Signatures) bool {
@@ -186,9 +278,44 @@ func (self *Plugin) Equals(plugin *Plugin) bool {
 	if self.QueryString != plugin.QueryString {
 		return false
 	}
+	if !SliceStringEqual(self.QueryStrings, plugin.QueryStrings) {
+		return false
+	}
 	if self.FollowRedirects != plugin.FollowRedirects {
 		return false
 	}
+	if self.Category != plugin.Category {
+		return false
+	}
+	if !SliceStringEqual(self.Tags, plugin.Tags) {
+		return false
+	}
+	if self.Method != plugin.Method || self.Body != plugin.Body {
+		return false
+	}
+	if self.Timeout != plugin.Timeout {
+		return false
+	}
+	if !SliceStringEqual(self.RequestHeaders, plugin.RequestHeaders) {
+		return false
+	}
+	if (self.DefaultCredentials == nil) != (plugin.DefaultCredentials == nil) {
+		return false
+	}
+	if self.DefaultCredentials != nil && (self.DefaultCredentials.File != plugin.DefaultCredentials.File || self.DefaultCredentials.MaxAttempts != plugin.DefaultCredentials.MaxAttempts) {
+		return false
+	}
+	if !self.Request.Equals(plugin.Request) {
+		return false
+	}
+	if len(self.Steps) != len(plugin.Steps) {
+		return false
+	}
+	for i, step := range self.Steps {
+		if !step.Equals(plugin.Steps[i]) {
+			return false
+		}
+	}
 	for _, check := range self.Checks {
 		found := false
 		for _, pcheck := range plugin.Checks {
@@ -219,6 +346,12 @@ func (self *Check) Equals(check *Check) bool {
 			return false
 		}
 	}
+	if !int32PtrEqual(self.StatusCodeNot, check.StatusCodeNot) || !SliceStringEqual(self.StatusCodeIn, check.StatusCodeIn) {
+		return false
+	}
+	if !int32PtrEqual(self.InitialStatusCode, check.InitialStatusCode) {
+		return false
+	}
 	if self.Name != check.Name {
 		return false
 	}
@@ -237,6 +370,66 @@ func (self *Check) Equals(check *Check) bool {
 	if !SliceStringEqual(self.NoHeaders, check.NoHeaders) {
 		return false
 	}
+	if self.MatchFile != check.MatchFile {
+		return false
+	}
+	if self.EmptyBody != check.EmptyBody || self.NonEmptyBody != check.NonEmptyBody {
+		return false
+	}
+	if !stringPtrEqual(self.BodyEquals, check.BodyEquals) || self.TrimBody != check.TrimBody {
+		return false
+	}
+	if !intPtrEqual(self.MinBodySize, check.MinBodySize) || !intPtrEqual(self.MaxBodySize, check.MaxBodySize) {
+		return false
+	}
+	if !self.Cookie.Equals(check.Cookie) {
+		return false
+	}
+	if !self.WWWAuthenticate.Equals(check.WWWAuthenticate) {
+		return false
+	}
+	if self.Repeat != check.Repeat || self.RequireHits != check.RequireHits {
+		return false
+	}
+	if self.Advisory != check.Advisory || self.Confidence != check.Confidence {
+		return false
+	}
+	if !boolPtrEqual(self.Enabled, check.Enabled) {
+		return false
+	}
+	if self.MixedContent != check.MixedContent {
+		return false
+	}
+	if !SliceStringEqual(self.References, check.References) {
+		return false
+	}
+	if !self.ServerVersion.Equals(check.ServerVersion) {
+		return false
+	}
+	if !self.JSONMatch.Equals(check.JSONMatch) {
+		return false
+	}
+	if !self.TLS.Equals(check.TLS) {
+		return false
+	}
+	if self.ContentType != check.ContentType || self.NotContentType != check.NotContentType {
+		return false
+	}
+	if self.CaseInsensitive != check.CaseInsensitive {
+		return false
+	}
+	if self.SizeRatio != check.SizeRatio {
+		return false
+	}
+	if !SliceStringEqual(self.MatchRegex, check.MatchRegex) || !SliceStringEqual(self.AllMatchRegex, check.AllMatchRegex) || !SliceStringEqual(self.NoMatchRegex, check.NoMatchRegex) {
+		return false
+	}
+	if !SliceStringEqual(self.HeadersRegex, check.HeadersRegex) || !SliceStringEqual(self.NoHeadersRegex, check.NoHeadersRegex) {
+		return false
+	}
+	if !self.Conditions.Equals(check.Conditions) {
+		return false
+	}
 	return true
 }
 
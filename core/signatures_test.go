@@ -3,6 +3,8 @@ package core_test
 import (
 	"gochopchop/core"
 	"gochopchop/mock"
+	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -29,6 +31,39 @@ func TestFilterBySeverity(t *testing.T) {
 	}
 }
 
+func TestFilterByMinSeverity(t *testing.T) {
+	var tests = map[string]struct {
+		severity string
+		want     []string
+	}{
+		"Keep more critical": {severity: "Medium", want: []string{"Critical", "High", "Medium"}},
+		"Keep all":           {severity: "Informational", want: []string{"Critical", "High", "Medium", "Low", "Informational"}},
+		"Keep most critical": {severity: "Critical", want: []string{"Critical"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			signatures := &core.Signatures{Plugins: []*core.Plugin{
+				{Endpoint: "/a", Checks: []*core.Check{{Name: "Critical", Severity: "Critical"}, {Name: "Low", Severity: "Low"}}},
+				{Endpoint: "/b", Checks: []*core.Check{{Name: "High", Severity: "High"}, {Name: "Medium", Severity: "Medium"}}},
+				{Endpoint: "/c", Checks: []*core.Check{{Name: "Informational", Severity: "Informational"}}},
+			}}
+			signatures.FilterByMinSeverity(tc.severity)
+			var have []string
+			for _, plugin := range signatures.Plugins {
+				for _, check := range plugin.Checks {
+					have = append(have, check.Name)
+				}
+			}
+			sort.Strings(have)
+			sort.Strings(tc.want)
+			if !core.SliceStringEqual(have, tc.want) {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
 func TestFilterByNames(t *testing.T) {
 	var tests = map[string]struct {
 		have  *core.Signatures
@@ -57,6 +92,62 @@ func TestFilterByNames(t *testing.T) {
 	}
 }
 
+func TestFilterByTags(t *testing.T) {
+	cms := &core.Plugin{Endpoint: "/wp-login.php", Tags: []string{"cms", "wordpress"}}
+	exposure := &core.Plugin{Endpoint: "/.git/config", Tags: []string{"Exposure"}}
+	untagged := &core.Plugin{Endpoint: "/"}
+	var tests = map[string]struct {
+		tags        []string
+		excludeTags []string
+		want        []*core.Plugin
+	}{
+		"one tag":            {tags: []string{"cms"}, want: []*core.Plugin{cms}},
+		"several tags":       {tags: []string{"cms", "exposure"}, want: []*core.Plugin{cms, exposure}},
+		"unknown tag":        {tags: []string{"iot"}, want: []*core.Plugin{}},
+		"excluded tag":       {excludeTags: []string{"WordPress"}, want: []*core.Plugin{exposure, untagged}},
+		"included, excluded": {tags: []string{"cms", "exposure"}, excludeTags: []string{"wordpress"}, want: []*core.Plugin{exposure}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			signatures := &core.Signatures{Plugins: []*core.Plugin{cms, exposure, untagged}}
+			if len(tc.tags) > 0 {
+				signatures.FilterByTags(tc.tags)
+			}
+			if len(tc.excludeTags) > 0 {
+				signatures.FilterByExcludedTags(tc.excludeTags)
+			}
+			if !reflect.DeepEqual(signatures.Plugins, tc.want) {
+				t.Errorf("expected: %v, got: %v", tc.want, signatures.Plugins)
+			}
+		})
+	}
+}
+
+func TestFilterDisabled(t *testing.T) {
+	enabled := &core.Check{Name: "Enabled", Enabled: createBool(true)}
+	implicit := &core.Check{Name: "Implicit"}
+	disabled := &core.Check{Name: "Disabled", Enabled: createBool(false)}
+	var tests = map[string]struct {
+		checks []*core.Check
+		want   []*core.Plugin
+	}{
+		"enabled by default": {checks: []*core.Check{enabled, implicit}, want: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{enabled, implicit}}}},
+		"disabled check":     {checks: []*core.Check{disabled, implicit}, want: []*core.Plugin{{Endpoint: "/", Checks: []*core.Check{implicit}}}},
+		"all disabled":       {checks: []*core.Check{disabled}, want: []*core.Plugin{}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			signatures := &core.Signatures{Plugins: []*core.Plugin{{Endpoint: "/", Checks: tc.checks}}}
+			signatures.FilterDisabled()
+			if !reflect.DeepEqual(signatures.Plugins, tc.want) {
+				t.Errorf("expected: %v, got: %v", tc.want, signatures.Plugins)
+			}
+		})
+	}
+}
+
 func TestPluginEquals(t *testing.T) {
 	var tests = map[string]struct {
 		plugin1 *core.Plugin
@@ -72,6 +163,17 @@ func TestPluginEquals(t *testing.T) {
 			},
 			want: false,
 		},
+		"Different Tags": {
+			plugin1: &core.Plugin{
+				Endpoint: "/endpoint1",
+				Tags:     []string{"cms"},
+			},
+			plugin2: &core.Plugin{
+				Endpoint: "/endpoint1",
+				Tags:     []string{"exposure"},
+			},
+			want: false,
+		},
 		"Different Query String": {
 			plugin1: &core.Plugin{
 				Endpoint:    "/endpoint1",
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValidateStatusCodes checks the status_code_in list of the check, each entry being a status code (eg. 200) or a class (eg. 2xx)
+func (check *Check) ValidateStatusCodes() error {
+	for _, entry := range check.StatusCodeIn {
+		if _, ok := parseStatusCode(entry); !ok {
+			return fmt.Errorf("Invalid status code : %s. Please use a code (eg. 200) or a class (eg. 2xx) in %s plugin checks. Stopping execution", entry, check.Name)
+		}
+	}
+	return nil
+}
+
+// statusCodeIn tells whether the status code is one of the codes, or in one of the classes, of the list
+func statusCodeIn(code int, list []string) bool {
+	for _, entry := range list {
+		if match, ok := parseStatusCode(entry); ok && match(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStatusCode returns the matcher of a status code or of a class of status codes, the class being case-insensitive
+func parseStatusCode(entry string) (func(code int) bool, bool) {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	if len(entry) == 3 && strings.HasSuffix(entry, "xx") && entry[0] >= '1' && entry[0] <= '5' {
+		class := int(entry[0] - '0')
+		return func(code int) bool { return code/100 == class }, true
+	}
+	expected, err := strconv.Atoi(entry)
+	if err != nil || expected < 100 || expected > 599 {
+		return nil, false
+	}
+	return func(code int) bool { return code == expected }, true
+}
@@ -0,0 +1,60 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"testing"
+)
+
+func TestCheckMatchStatusCode(t *testing.T) {
+	var tests = map[string]struct {
+		statusCode        int
+		initialStatusCode int
+		check             *core.Check
+		want              bool
+	}{
+		"anything but 404":        {statusCode: 200, check: &core.Check{StatusCodeNot: createInt32(404)}, want: true},
+		"excluded 404":            {statusCode: 404, check: &core.Check{StatusCodeNot: createInt32(404)}, want: false},
+		"code in list":            {statusCode: 301, check: &core.Check{StatusCodeIn: []string{"200", "301"}}, want: true},
+		"code not in list":        {statusCode: 302, check: &core.Check{StatusCodeIn: []string{"200", "301"}}, want: false},
+		"2xx class":               {statusCode: 204, check: &core.Check{StatusCodeIn: []string{"2xx", "3xx"}}, want: true},
+		"uppercase class":         {statusCode: 302, check: &core.Check{StatusCodeIn: []string{"3XX"}}, want: true},
+		"out of the classes":      {statusCode: 403, check: &core.Check{StatusCodeIn: []string{"2xx", "3xx"}}, want: false},
+		"class with an exclusion": {statusCode: 204, check: &core.Check{StatusCodeIn: []string{"2xx"}, StatusCodeNot: createInt32(204)}, want: false},
+		"single status code":      {statusCode: 200, check: &core.Check{StatusCode: createInt32(200), StatusCodeIn: []string{"2xx"}}, want: true},
+		"redirect to a file":      {statusCode: 200, initialStatusCode: 301, check: &core.Check{InitialStatusCode: createInt32(301), StatusCode: createInt32(200)}, want: true},
+		"file served directly":    {statusCode: 200, check: &core.Check{InitialStatusCode: createInt32(301), StatusCode: createInt32(200)}, want: false},
+		"direct 200 as initial":   {statusCode: 200, check: &core.Check{InitialStatusCode: createInt32(200)}, want: true},
+		"other first redirect":    {statusCode: 200, initialStatusCode: 302, check: &core.Check{InitialStatusCode: createInt32(301)}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := tc.check.Match(&internal.HTTPResponse{StatusCode: tc.statusCode, InitialStatusCode: tc.initialStatusCode})
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestCheckValidateStatusCodes(t *testing.T) {
+	var tests = map[string]struct {
+		statusCodeIn []string
+		nilErr       bool
+	}{
+		"codes and classes": {statusCodeIn: []string{"200", "3xx", "5XX"}, nilErr: true},
+		"unknown class":     {statusCodeIn: []string{"6xx"}, nilErr: false},
+		"out of range code": {statusCodeIn: []string{"99"}, nilErr: false},
+		"not a code":        {statusCodeIn: []string{"ok"}, nilErr: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := (&core.Check{Name: "Check", StatusCodeIn: tc.statusCodeIn}).ValidateStatusCodes()
+			if tc.nilErr != (err == nil) {
+				t.Errorf("expected a nil error: %v, got: %v", tc.nilErr, err)
+			}
+		})
+	}
+}
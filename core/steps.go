@@ -0,0 +1,250 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"gochopchop/internal"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxSteps caps the number of requests sent for a single plugin
+const MaxSteps = 5
+
+// placeholderRegex matches the {{name}} placeholders replaced by the extracted values
+var placeholderRegex = regexp.MustCompile(`{{\s*([A-Za-z0-9_]+)\s*}}`)
+
+var extractorNameRegex = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// Step is one request of a multi-step plugin (eg. fetch a CSRF token then submit a form).
+// Values extracted from its response can be injected with {{name}} in the endpoint, headers and body of the next steps.
+// The checks of the plugin run against the response of the last step.
+type Step struct {
+	Endpoint string       `yaml:"endpoint"`
+	Method   string       `yaml:"method"`
+	Headers  []string     `yaml:"headers"`
+	Body     string       `yaml:"body"`
+	Extract  []*Extractor `yaml:"extract"`
+}
+
+// Extractor reads a value from a response, from exactly one of a regex (first group, or the whole match),
+// a response header, or a dotted JSON path (eg. data.tokens.0.value)
+type Extractor struct {
+	Name   string `yaml:"name"`
+	Regex  string `yaml:"regex"`
+	Header string `yaml:"header"`
+	JSON   string `yaml:"json"`
+	regex  *regexp.Regexp
+}
+
+// Validate compiles the regex of the extractor and ensures a single source is set
+func (e *Extractor) Validate() error {
+	if !extractorNameRegex.MatchString(e.Name) {
+		return fmt.Errorf("Invalid extract name : %q. Only letters, digits and _ are allowed", e.Name)
+	}
+	sources := 0
+	for _, source := range []string{e.Regex, e.Header, e.JSON} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("extract %s must set exactly one of regex, header or json", e.Name)
+	}
+	if e.Regex != "" {
+		regex, err := regexp.Compile(e.Regex)
+		if err != nil {
+			return fmt.Errorf("Invalid regex of extract %s : %v", e.Name, err)
+		}
+		e.regex = regex
+	}
+	return nil
+}
+
+// Extract reads the value from the response
+func (e *Extractor) Extract(resp *internal.HTTPResponse) (string, error) {
+	switch {
+	case e.Header != "":
+		value := resp.Header.Get(e.Header)
+		if value == "" {
+			return "", fmt.Errorf("header %s not found for extract %s", e.Header, e.Name)
+		}
+		return value, nil
+	case e.JSON != "":
+		return extractJSON(resp.Body, e.JSON)
+	default:
+		regex := e.regex
+		if regex == nil {
+			var err error
+			if regex, err = regexp.Compile(e.Regex); err != nil {
+				return "", err
+			}
+		}
+		match := regex.FindStringSubmatch(resp.Body)
+		if match == nil {
+			return "", fmt.Errorf("regex of extract %s did not match", e.Name)
+		}
+		if len(match) > 1 {
+			return match[1], nil
+		}
+		return match[0], nil
+	}
+}
+
+// extractJSON walks the dotted path through the JSON body, numbers being array indexes
+func extractJSON(body string, path string) (string, error) {
+	return jsonValue(body, strings.Split(path, "."), path)
+}
+
+// jsonValue walks the keys through the JSON body, numbers being array indexes.
+// The path is only used in the errors.
+func jsonValue(body string, keys []string, path string) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return "", fmt.Errorf("could not decode JSON body: %v", err)
+	}
+	for _, key := range keys {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			v, ok := node[key]
+			if !ok {
+				return "", fmt.Errorf("JSON path %s not found", path)
+			}
+			value = v
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return "", fmt.Errorf("JSON path %s not found", path)
+			}
+			value = node[i]
+		default:
+			return "", fmt.Errorf("JSON path %s not found", path)
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case nil, map[string]interface{}, []interface{}:
+		return "", fmt.Errorf("JSON path %s is not a value", path)
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// Inject replaces the {{name}} placeholders with the extracted values
+func Inject(s string, values map[string]string) string {
+	return placeholderRegex.ReplaceAllStringFunc(s, func(placeholder string) string {
+		name := placeholderRegex.FindStringSubmatch(placeholder)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// addSessionCookies adds the cookies of the jar matching the url of the request to its Cookie header,
+// after the cookies already set by the signature
+func addSessionCookies(jar http.CookieJar, req *internal.HTTPRequest) {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return
+	}
+	var cookies []string
+	if header := req.Header.Get("Cookie"); header != "" {
+		cookies = append(cookies, header)
+	}
+	for _, cookie := range jar.Cookies(u) {
+		cookies = append(cookies, cookie.String())
+	}
+	if len(cookies) > 0 {
+		req.Header.Set("Cookie", strings.Join(cookies, "; "))
+	}
+}
+
+// saveSessionCookies stores the cookies set by the response in the jar, for the url of the response after the redirects
+func saveSessionCookies(jar http.CookieJar, req *internal.HTTPRequest, resp *internal.HTTPResponse) {
+	responseURL := resp.URL
+	if responseURL == "" {
+		responseURL = req.URL
+	}
+	u, err := url.Parse(responseURL)
+	if err != nil {
+		return
+	}
+	jar.SetCookies(u, (&http.Response{Header: resp.Header}).Cookies())
+}
+
+// Request builds the request of the step, with the endpoint expanded for the url and the extracted values injected
+func (s *Step) Request(url string, basePath string, values map[string]string) *internal.HTTPRequest {
+	req := &internal.HTTPRequest{
+		URL:    url + JoinBasePath(basePath, Inject(ExpandEndpoint(s.Endpoint, url), values)),
+		Method: s.Method,
+		Header: make(http.Header),
+		Body:   Inject(s.Body, values),
+	}
+	for _, header := range s.Headers {
+		parts := strings.SplitN(Inject(header, values), ":", 2)
+		req.Header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	return req
+}
+
+// ValidateSteps ensures the steps are well formed, and that each placeholder is extracted by a previous step
+func (p *Plugin) ValidateSteps() error {
+	if len(p.Steps) == 0 {
+		return nil
+	}
+	if len(p.Steps) > MaxSteps {
+		return fmt.Errorf("A plugin can't have more than %d steps", MaxSteps)
+	}
+	if p.Endpoint != "" || len(p.Endpoints) > 0 {
+		return fmt.Errorf("endpoint and steps can't be set at the same time in plugin checks. Stopping execution")
+	}
+	if p.DefaultCredentials != nil {
+		return fmt.Errorf("default_credentials and steps can't be set at the same time in plugin checks. Stopping execution")
+	}
+	extracted := make(map[string]bool)
+	for i, step := range p.Steps {
+		if step.Endpoint == "" {
+			return fmt.Errorf("Missing endpoint in step %d of plugin checks. Stopping execution", i+1)
+		}
+		for _, header := range step.Headers {
+			if !strings.Contains(header, ":") {
+				return fmt.Errorf("Invalid header format : %s. Format should be KEY:VALUE", header)
+			}
+		}
+		for _, field := range append([]string{step.Endpoint, step.Body}, step.Headers...) {
+			for _, match := range placeholderRegex.FindAllStringSubmatch(field, -1) {
+				if !extracted[match[1]] {
+					return fmt.Errorf("%s is not extracted by a previous step (step %d)", match[1], i+1)
+				}
+			}
+		}
+		for _, extractor := range step.Extract {
+			if err := extractor.Validate(); err != nil {
+				return err
+			}
+			extracted[extractor.Name] = true
+		}
+	}
+	return nil
+}
+
+func (s *Step) Equals(step *Step) bool {
+	if s.Endpoint != step.Endpoint || s.Method != step.Method || s.Body != step.Body {
+		return false
+	}
+	if !SliceStringEqual(s.Headers, step.Headers) || len(s.Extract) != len(step.Extract) {
+		return false
+	}
+	for i, extractor := range s.Extract {
+		o := step.Extract[i]
+		if extractor.Name != o.Name || extractor.Regex != o.Regex || extractor.Header != o.Header || extractor.JSON != o.JSON {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,192 @@
+package core_test
+
+import (
+	"context"
+	"gochopchop/core"
+	"gochopchop/internal"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// csrfFetcher serves a login form with a CSRF token, and only accepts posts carrying that token
+type csrfFetcher struct {
+	requests []*internal.HTTPRequest
+}
+
+func (f *csrfFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	f.requests = append(f.requests, req)
+	switch {
+	case req.URL == "http://app/login" && req.Method == "":
+		return &internal.HTTPResponse{
+			StatusCode: 200,
+			Body:       `<form><input type="hidden" name="csrf" value="t0k3n"></form>`,
+			Header:     http.Header{"X-Request-Id": []string{"42"}},
+		}, nil
+	case req.URL == "http://app/login" && req.Method == "POST" && req.Body == "user=admin&password=admin&csrf=t0k3n" && req.Header.Get("X-Request-Id") == "42":
+		return &internal.HTTPResponse{StatusCode: 200, Body: `{"session": {"user": "admin", "roles": ["admin"]}}`}, nil
+	case req.URL == "http://app/users/admin" && req.Header.Get("X-Role") == "admin":
+		return &internal.HTTPResponse{StatusCode: 200, Body: "Administration"}, nil
+	}
+	return &internal.HTTPResponse{StatusCode: 403, Body: "Forbidden"}, nil
+}
+
+func TestScanSteps(t *testing.T) {
+	login := []*core.Step{
+		{
+			Endpoint: "/login",
+			Extract: []*core.Extractor{
+				{Name: "csrf", Regex: `name="csrf" value="([^"]+)"`},
+				{Name: "request_id", Header: "X-Request-Id"},
+			},
+		},
+		{
+			Endpoint: "/login",
+			Method:   "POST",
+			Headers:  []string{"X-Request-Id: {{request_id}}"},
+			Body:     "user=admin&password=admin&csrf={{ csrf }}",
+			Extract: []*core.Extractor{
+				{Name: "user", JSON: "session.user"},
+				{Name: "role", JSON: "session.roles.0"},
+			},
+		},
+		{
+			Endpoint: "/users/{{user}}",
+			Headers:  []string{"X-Role: {{role}}"},
+		},
+	}
+	var tests = map[string]struct {
+		steps        []*core.Step
+		wantFindings int
+		wantRequests int
+		wantEndpoint string
+	}{
+		"CSRF handshake": {steps: login, wantFindings: 1, wantRequests: 3, wantEndpoint: "/users/admin"},
+		"Extraction fails": {steps: []*core.Step{
+			{Endpoint: "/login", Extract: []*core.Extractor{{Name: "csrf", Regex: `name="token" value="([^"]+)"`}}},
+			{Endpoint: "/login", Method: "POST", Body: "csrf={{csrf}}"},
+		}, wantFindings: 0, wantRequests: 1},
+		"Missing token": {steps: login[1:2], wantFindings: 0, wantRequests: 1},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := &csrfFetcher{}
+			check := &core.Check{Name: "Admin reachable", Severity: "High", StatusCode: createInt32(200)}
+			plugin := &core.Plugin{Steps: tc.steps, Checks: []*core.Check{check}}
+			scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: []*core.Plugin{plugin}}, 1)
+			output, _ := scanner.Scan(context.Background(), []string{"http://app"})
+			if len(output) != tc.wantFindings {
+				t.Fatalf("expected: %v findings, got: %v", tc.wantFindings, len(output))
+			}
+			if len(fetcher.requests) != tc.wantRequests {
+				t.Errorf("expected: %v requests, got: %v", tc.wantRequests, len(fetcher.requests))
+			}
+			if tc.wantFindings > 0 && output[0].Endpoint != tc.wantEndpoint {
+				t.Errorf("expected: %v, got: %v", tc.wantEndpoint, output[0].Endpoint)
+			}
+		})
+	}
+}
+
+// sessionFetcher sets a session cookie on /login, and only serves /admin to that session
+type sessionFetcher struct {
+	cookies []string
+}
+
+func (f *sessionFetcher) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	f.cookies = append(f.cookies, req.Header.Get("Cookie"))
+	switch req.URL {
+	case "http://app/login":
+		return &internal.HTTPResponse{
+			URL:        "http://app/home",
+			StatusCode: 200,
+			Header: http.Header{"Set-Cookie": []string{
+				"session=s3ss10n; Path=/; HttpOnly",
+				"scoped=1; Path=/other",
+			}},
+		}, nil
+	case "http://app/admin":
+		if strings.Contains(req.Header.Get("Cookie"), "session=s3ss10n") {
+			return &internal.HTTPResponse{StatusCode: 200, Body: "Administration"}, nil
+		}
+	}
+	return &internal.HTTPResponse{StatusCode: 403, Body: "Forbidden"}, nil
+}
+
+func TestScanStepsCookies(t *testing.T) {
+	var tests = map[string]struct {
+		steps        []*core.Step
+		wantFindings int
+		wantCookie   string
+	}{
+		"Session of the previous step": {
+			steps:        []*core.Step{{Endpoint: "/login"}, {Endpoint: "/admin"}},
+			wantFindings: 1,
+			wantCookie:   "session=s3ss10n",
+		},
+		"Cookie of the signature kept": {
+			steps:        []*core.Step{{Endpoint: "/login"}, {Endpoint: "/admin", Headers: []string{"Cookie: lang=en"}}},
+			wantFindings: 1,
+			wantCookie:   "lang=en; session=s3ss10n",
+		},
+		"No session": {
+			steps:        []*core.Step{{Endpoint: "/admin"}},
+			wantFindings: 0,
+			wantCookie:   "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := &sessionFetcher{}
+			check := &core.Check{Name: "Admin reachable", Severity: "High", StatusCode: createInt32(200)}
+			plugin := &core.Plugin{Steps: tc.steps, Checks: []*core.Check{check}}
+			scanner := core.NewScanner(fetcher, fetcher, &core.Signatures{Plugins: []*core.Plugin{plugin}}, 1)
+			output, _ := scanner.Scan(context.Background(), []string{"http://app"})
+			if len(output) != tc.wantFindings {
+				t.Fatalf("expected: %v findings, got: %v", tc.wantFindings, len(output))
+			}
+			if last := fetcher.cookies[len(fetcher.cookies)-1]; last != tc.wantCookie {
+				t.Errorf("expected: %v, got: %v", tc.wantCookie, last)
+			}
+		})
+	}
+}
+
+func TestPluginValidateSteps(t *testing.T) {
+	tooMany := make([]*core.Step, core.MaxSteps+1)
+	for i := range tooMany {
+		tooMany[i] = &core.Step{Endpoint: "/"}
+	}
+	var tests = map[string]struct {
+		plugin  *core.Plugin
+		wantErr bool
+	}{
+		"No steps": {plugin: &core.Plugin{Endpoint: "/"}, wantErr: false},
+		"Valid steps": {plugin: &core.Plugin{Steps: []*core.Step{
+			{Endpoint: "/login", Extract: []*core.Extractor{{Name: "csrf", Regex: `csrf=(\w+)`}}},
+			{Endpoint: "/login", Body: "csrf={{csrf}}"},
+		}}, wantErr: false},
+		"Too many steps":        {plugin: &core.Plugin{Steps: tooMany}, wantErr: true},
+		"Endpoint and steps":    {plugin: &core.Plugin{Endpoint: "/", Steps: []*core.Step{{Endpoint: "/"}}}, wantErr: true},
+		"Missing step endpoint": {plugin: &core.Plugin{Steps: []*core.Step{{Body: "a=b"}}}, wantErr: true},
+		"Unknown placeholder":   {plugin: &core.Plugin{Steps: []*core.Step{{Endpoint: "/", Headers: []string{"X-Csrf: {{csrf}}"}}}}, wantErr: true},
+		"Placeholder extracted by a later step": {plugin: &core.Plugin{Steps: []*core.Step{
+			{Endpoint: "/{{csrf}}"},
+			{Endpoint: "/", Extract: []*core.Extractor{{Name: "csrf", Header: "X-Csrf"}}},
+		}}, wantErr: true},
+		"Two sources":   {plugin: &core.Plugin{Steps: []*core.Step{{Endpoint: "/", Extract: []*core.Extractor{{Name: "csrf", Header: "X-Csrf", JSON: "csrf"}}}}}, wantErr: true},
+		"Invalid regex": {plugin: &core.Plugin{Steps: []*core.Step{{Endpoint: "/", Extract: []*core.Extractor{{Name: "csrf", Regex: "("}}}}}, wantErr: true},
+		"Invalid name":  {plugin: &core.Plugin{Steps: []*core.Step{{Endpoint: "/", Extract: []*core.Extractor{{Name: "csrf-token", Header: "X-Csrf"}}}}}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.plugin.ValidateSteps()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
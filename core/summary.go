@@ -0,0 +1,67 @@
+package core
+
+import "sync"
+
+// Summary aggregates the counts of the findings, so a scan can be reported without keeping its findings in memory.
+// It is a FindingWriter safe for concurrent use.
+type Summary struct {
+	mux        sync.Mutex
+	Total      int
+	BySeverity map[string]int
+	// Blocking counts the findings that are not advisory, by severity
+	Blocking map[string]int
+	// Requests is the number of HTTP requests sent by the scan, set once it is over
+	Requests int
+}
+
+func NewSummary() *Summary {
+	return &Summary{BySeverity: make(map[string]int), Blocking: make(map[string]int)}
+}
+
+// Summarize counts the findings of a scan
+func Summarize(outputs []Output) *Summary {
+	summary := NewSummary()
+	for _, output := range outputs {
+		summary.Write(output)
+	}
+	return summary
+}
+
+// Write counts a finding, the resolved findings of a previous run are not counted
+func (s *Summary) Write(output Output) error {
+	if output.Status == StatusResolved {
+		return nil
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.Total++
+	s.BySeverity[output.Severity]++
+	if !output.Advisory {
+		s.Blocking[output.Severity]++
+	}
+	return nil
+}
+
+// Reached counts the findings with a severity equal or over the threshold
+func (s *Summary) Reached(threshold string) int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return countReached(s.BySeverity, threshold)
+}
+
+// BlockingReached counts the findings that are not advisory with a severity equal or over the threshold
+func (s *Summary) BlockingReached(threshold string) int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return countReached(s.Blocking, threshold)
+}
+
+func countReached(counts map[string]int, threshold string) int {
+	total := 0
+	for severity, count := range counts {
+		if SeverityReached(threshold, severity) {
+			total += count
+		}
+	}
+	return total
+}
@@ -0,0 +1,38 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	summary := core.Summarize([]core.Output{
+		{Severity: "High"},
+		{Severity: "High", Advisory: true},
+		{Severity: "Medium"},
+		{Severity: "Informational"},
+	})
+	if summary.Total != 4 {
+		t.Errorf("expected: %v, got: %v", 4, summary.Total)
+	}
+	var tests = map[string]struct {
+		threshold    string
+		wantReached  int
+		wantBlocking int
+	}{
+		"High":          {threshold: "High", wantReached: 2, wantBlocking: 1},
+		"Medium":        {threshold: "Medium", wantReached: 3, wantBlocking: 2},
+		"Informational": {threshold: "Informational", wantReached: 4, wantBlocking: 3},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if have := summary.Reached(tc.threshold); have != tc.wantReached {
+				t.Errorf("expected: %v, got: %v", tc.wantReached, have)
+			}
+			if have := summary.BlockingReached(tc.threshold); have != tc.wantBlocking {
+				t.Errorf("expected: %v, got: %v", tc.wantBlocking, have)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// insecurePrefix marks a target whose TLS certificate is not verified, eg. a known self-signed internal host
+const insecurePrefix = "insecure://"
+
+// ParseInsecureTarget replaces the insecure:// prefix of a target by https://, and tells whether it was set
+func ParseInsecureTarget(entry string) (string, bool) {
+	if len(entry) >= len(insecurePrefix) && strings.EqualFold(entry[:len(insecurePrefix)], insecurePrefix) {
+		return "https://" + entry[len(insecurePrefix):], true
+	}
+	return entry, false
+}
+
+// ExpandHosts expands an IP address or a CIDR range, eg. 10.0.0.0/24 or 2001:db8::/120, into the urls of its hosts.
+// The entry may start with its scheme, defaultScheme is used otherwise. The network and broadcast addresses of
+// the IPv4 ranges are left out. A range of more than limit hosts is rejected.
+// ok is false when the entry is neither an address nor a range.
+func ExpandHosts(entry string, defaultScheme string, limit int) ([]string, bool, error) {
+	scheme := defaultScheme
+	if i := strings.Index(entry, "://"); i >= 0 {
+		scheme, entry = entry[:i], entry[i+3:]
+	}
+
+	if ip := net.ParseIP(entry); ip != nil {
+		return []string{hostURL(scheme, ip)}, true, nil
+	}
+	ip, network, err := net.ParseCIDR(entry)
+	if err != nil {
+		return nil, false, nil
+	}
+	if ip.To4() != nil {
+		ip = ip.To4()
+		network.IP = network.IP.To4()
+	}
+	ones, bits := network.Mask.Size()
+	hostBits := bits - ones
+	skipEnds := len(network.IP) == net.IPv4len && hostBits >= 2
+	// the size is only computed when it fits, the larger ranges are over any limit
+	if hostBits >= 31 || rangeSize(hostBits, skipEnds) > limit {
+		return nil, true, fmt.Errorf("The range %s has more than %d hosts. Please use a smaller range or raise --max-cidr-hosts", entry, limit)
+	}
+
+	var urls []string
+	for ip := network.IP; network.Contains(ip); ip = nextIP(ip) {
+		urls = append(urls, hostURL(scheme, ip))
+	}
+	if skipEnds {
+		urls = urls[1 : len(urls)-1]
+	}
+	return urls, true, nil
+}
+
+func rangeSize(hostBits int, skipEnds bool) int {
+	size := 1 << uint(hostBits)
+	if skipEnds {
+		size -= 2
+	}
+	return size
+}
+
+// nextIP returns the address following ip, the last address wrapping around to the first one
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+// hostURL builds the url of the host, the IPv6 addresses being bracketed
+func hostURL(scheme string, ip net.IP) string {
+	if ip.To4() == nil {
+		return fmt.Sprintf("%s://[%s]", scheme, ip)
+	}
+	return fmt.Sprintf("%s://%s", scheme, ip)
+}
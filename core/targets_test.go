@@ -0,0 +1,74 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"reflect"
+	"testing"
+)
+
+func TestExpandHosts(t *testing.T) {
+	var tests = map[string]struct {
+		entry  string
+		limit  int
+		urls   []string
+		count  int
+		ok     bool
+		nilErr bool
+	}{
+		"IPv4 range":             {entry: "10.0.0.0/30", limit: 16, urls: []string{"http://10.0.0.1", "http://10.0.0.2"}, ok: true, nilErr: true},
+		"IPv4 range with scheme": {entry: "https://192.168.1.8/31", limit: 16, urls: []string{"https://192.168.1.8", "https://192.168.1.9"}, ok: true, nilErr: true},
+		"IPv4 address of range":  {entry: "10.0.0.5/30", limit: 16, urls: []string{"http://10.0.0.5", "http://10.0.0.6"}, ok: true, nilErr: true},
+		"IPv4 address":           {entry: "10.0.0.1", limit: 16, urls: []string{"http://10.0.0.1"}, ok: true, nilErr: true},
+		"IPv6 range":             {entry: "2001:db8::/127", limit: 16, urls: []string{"http://[2001:db8::]", "http://[2001:db8::1]"}, ok: true, nilErr: true},
+		"IPv6 address":           {entry: "https://2001:db8::1", limit: 16, urls: []string{"https://[2001:db8::1]"}, ok: true, nilErr: true},
+		"range over the limit":   {entry: "10.0.0.0/24", limit: 253, ok: true, nilErr: false},
+		"range at the limit":     {entry: "10.0.0.0/24", limit: 254, count: 254, ok: true, nilErr: true},
+		"huge IPv6 range":        {entry: "2001:db8::/64", limit: 1024, ok: true, nilErr: false},
+		"url":                    {entry: "https://10.0.0.1/admin", limit: 16, ok: false, nilErr: true},
+		"bracketed IPv6 url":     {entry: "http://[2001:db8::1]:8080", limit: 16, ok: false, nilErr: true},
+		"hostname":               {entry: "foobar.com", limit: 16, ok: false, nilErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			urls, ok, err := core.ExpandHosts(tc.entry, "http", tc.limit)
+			if tc.nilErr != (err == nil) {
+				t.Fatalf("expected a nil error: %v, got: %v", tc.nilErr, err)
+			}
+			if ok != tc.ok {
+				t.Errorf("expected: %v, got: %v", tc.ok, ok)
+			}
+			if tc.urls != nil && !reflect.DeepEqual(urls, tc.urls) {
+				t.Errorf("expected: %v, got: %v", tc.urls, urls)
+			}
+			if tc.count != 0 && len(urls) != tc.count {
+				t.Errorf("expected: %v, got: %v", tc.count, len(urls))
+			}
+		})
+	}
+}
+
+func TestParseInsecureTarget(t *testing.T) {
+	var tests = map[string]struct {
+		entry    string
+		url      string
+		insecure bool
+	}{
+		"insecure url":      {entry: "insecure://intranet.local/app", url: "https://intranet.local/app", insecure: true},
+		"insecure range":    {entry: "INSECURE://10.0.0.0/30", url: "https://10.0.0.0/30", insecure: true},
+		"https url":         {entry: "https://foobar.com", url: "https://foobar.com", insecure: false},
+		"insecure hostname": {entry: "insecure.com", url: "insecure.com", insecure: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			url, insecure := core.ParseInsecureTarget(tc.entry)
+			if url != tc.url {
+				t.Errorf("expected: %v, got: %v", tc.url, url)
+			}
+			if insecure != tc.insecure {
+				t.Errorf("expected: %v, got: %v", tc.insecure, insecure)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// tlsVersions are the protocol versions a min_tls_version can name
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSCheck flags the weak TLS posture of an HTTPS response, all of its conditions must be met.
+// The certificate is inspected even when it can't be verified, the requests of its plugin skip the verification.
+type TLSCheck struct {
+	// CertExpired flags the certificates past their expiration date
+	CertExpired bool `yaml:"cert_expired"`
+	// SelfSigned flags the certificates signed by their own key
+	SelfSigned bool `yaml:"self_signed"`
+	// MinTLSVersion flags the connections negotiated with an older protocol, eg. "1.2"
+	MinTLSVersion string `yaml:"min_tls_version"`
+	// IssuerContains flags the certificates whose issuer contains this string
+	IssuerContains string `yaml:"issuer_contains"`
+}
+
+// Validate ensures the check has a condition and a known protocol version
+func (t *TLSCheck) Validate() error {
+	if !t.CertExpired && !t.SelfSigned && t.MinTLSVersion == "" && t.IssuerContains == "" {
+		return fmt.Errorf("Empty tls field")
+	}
+	if _, ok := tlsVersions[t.MinTLSVersion]; t.MinTLSVersion != "" && !ok {
+		return fmt.Errorf("Invalid min_tls_version : %s. Please use : 1.0, 1.1, 1.2, 1.3", t.MinTLSVersion)
+	}
+	return nil
+}
+
+// Match reports whether the connection meets all the conditions of the check.
+// The returned string describes the protocol and the certificate of the connection.
+func (t *TLSCheck) Match(state *tls.ConnectionState) (bool, string) {
+	if state == nil {
+		return false, "no TLS connection"
+	}
+	detail := tlsVersionName(state.Version)
+	if len(state.PeerCertificates) == 0 {
+		return false, detail + ", no certificate"
+	}
+	cert := state.PeerCertificates[0]
+	detail = fmt.Sprintf("%s, certificate of %s issued by %s expiring on %s", detail, cert.Subject.CommonName, cert.Issuer.String(), cert.NotAfter.Format("2006-01-02"))
+
+	if t.CertExpired && !time.Now().After(cert.NotAfter) {
+		return false, detail
+	}
+	if t.SelfSigned && !selfSigned(cert) {
+		return false, detail
+	}
+	if t.MinTLSVersion != "" && state.Version >= tlsVersions[t.MinTLSVersion] {
+		return false, detail
+	}
+	if t.IssuerContains != "" && !strings.Contains(cert.Issuer.String(), t.IssuerContains) {
+		return false, detail
+	}
+	return true, detail
+}
+
+func (t *TLSCheck) Equals(check *TLSCheck) bool {
+	if t == nil || check == nil {
+		return t == check
+	}
+	return *t == *check
+}
+
+// selfSigned tells whether the certificate is signed by its own key.
+// CheckSignatureFrom is not used as it rejects the self-signed leaf certificates that are not CAs.
+func selfSigned(cert *x509.Certificate) bool {
+	return cert.Issuer.String() == cert.Subject.String() && cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+func tlsVersionName(version uint16) string {
+	for name, v := range tlsVersions {
+		if v == version {
+			return "TLS " + name
+		}
+	}
+	return fmt.Sprintf("TLS version 0x%04x", version)
+}
+
+// hasTLSChecks tells whether a check of the plugin inspects the TLS connection
+func (p *Plugin) hasTLSChecks() bool {
+	for _, check := range p.Checks {
+		if check.TLS != nil {
+			return true
+		}
+	}
+	return false
+}
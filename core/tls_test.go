@@ -0,0 +1,105 @@
+package core_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"gochopchop/core"
+	"gochopchop/internal"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// newCertificate returns a certificate of foobar.com valid until notAfter, self-signed or signed by a test CA
+func newCertificate(t *testing.T, notAfter time.Time, selfSigned bool) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "foobar.com"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	parent, signer := template, key
+	if !selfSigned {
+		caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parent = &x509.Certificate{SerialNumber: big.NewInt(2), Subject: pkix.Name{CommonName: "Test CA", Organization: []string{"Let's Test"}}}
+		signer = caKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestTLSCheckMatch(t *testing.T) {
+	expired := newCertificate(t, time.Now().Add(-24*time.Hour), false)
+	valid := newCertificate(t, time.Now().Add(24*time.Hour), false)
+	selfSigned := newCertificate(t, time.Now().Add(24*time.Hour), true)
+	state := func(version uint16, cert *x509.Certificate) *tls.ConnectionState {
+		return &tls.ConnectionState{Version: version, PeerCertificates: []*x509.Certificate{cert}}
+	}
+
+	var tests = map[string]struct {
+		check *core.TLSCheck
+		state *tls.ConnectionState
+		want  bool
+	}{
+		"expired certificate":             {check: &core.TLSCheck{CertExpired: true}, state: state(tls.VersionTLS13, expired), want: true},
+		"valid certificate":               {check: &core.TLSCheck{CertExpired: true}, state: state(tls.VersionTLS13, valid), want: false},
+		"self-signed certificate":         {check: &core.TLSCheck{SelfSigned: true}, state: state(tls.VersionTLS13, selfSigned), want: true},
+		"certificate signed by a CA":      {check: &core.TLSCheck{SelfSigned: true}, state: state(tls.VersionTLS13, valid), want: false},
+		"old protocol":                    {check: &core.TLSCheck{MinTLSVersion: "1.2"}, state: state(tls.VersionTLS11, valid), want: true},
+		"recent protocol":                 {check: &core.TLSCheck{MinTLSVersion: "1.2"}, state: state(tls.VersionTLS12, valid), want: false},
+		"issuer found":                    {check: &core.TLSCheck{IssuerContains: "Let's Test"}, state: state(tls.VersionTLS13, valid), want: true},
+		"issuer not found":                {check: &core.TLSCheck{IssuerContains: "DigiCert"}, state: state(tls.VersionTLS13, valid), want: false},
+		"all conditions met":              {check: &core.TLSCheck{CertExpired: true, MinTLSVersion: "1.2"}, state: state(tls.VersionTLS10, expired), want: true},
+		"one condition not met":           {check: &core.TLSCheck{CertExpired: true, MinTLSVersion: "1.2"}, state: state(tls.VersionTLS13, expired), want: false},
+		"plain http":                      {check: &core.TLSCheck{CertExpired: true}, state: nil, want: false},
+		"connection without certificates": {check: &core.TLSCheck{MinTLSVersion: "1.3"}, state: &tls.ConnectionState{Version: tls.VersionTLS12}, want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			check := &core.Check{TLS: tc.check}
+			got := check.Match(&internal.HTTPResponse{StatusCode: 200, TLS: tc.state})
+			if got != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTLSCheckValidate(t *testing.T) {
+	var tests = map[string]struct {
+		check  *core.TLSCheck
+		nilErr bool
+	}{
+		"valid check":         {check: &core.TLSCheck{CertExpired: true, MinTLSVersion: "1.2"}, nilErr: true},
+		"empty check":         {check: &core.TLSCheck{}, nilErr: false},
+		"unknown tls version": {check: &core.TLSCheck{MinTLSVersion: "TLSv1.2"}, nilErr: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.check.Validate()
+			if tc.nilErr != (err == nil) {
+				t.Errorf("expected a nil error: %v, got: %v", tc.nilErr, err)
+			}
+		})
+	}
+}
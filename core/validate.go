@@ -0,0 +1,152 @@
+package core
+
+import "fmt"
+
+// Validate validates the plugins and checks of the signatures, and loads the files they reference.
+// Their paths are relative to the Dir of each plugin, or to dir for the plugins built without a signature file.
+// It returns the first problem, the plugins and checks can't be run before being validated.
+func (s *Signatures) Validate(dir string, strictCategories bool) error {
+	for _, plugin := range s.Plugins {
+		pluginDir := plugin.Dir
+		if pluginDir == "" {
+			pluginDir = dir
+		}
+		if errs := plugin.Validate(pluginDir, strictCategories); len(errs) > 0 {
+			return errs[0]
+		}
+		for _, check := range plugin.Checks {
+			if errs := check.Validate(pluginDir); len(errs) > 0 {
+				return errs[0]
+			}
+		}
+	}
+	return nil
+}
+
+// Validate validates the request of the plugin and loads its default credentials, relative to dir.
+// Every problem is returned, so the lint command can report them all at once.
+func (p *Plugin) Validate(dir string, strictCategories bool) []error {
+	var errs []error
+	if strictCategories && p.Category != "" && !ValidCategory(p.Category) {
+		errs = append(errs, fmt.Errorf("Invalid category : %s. Please use : %s", p.Category, CategoriesAsString()))
+	}
+	if p.DefaultCredentials != nil {
+		if p.DefaultCredentials.File == "" {
+			errs = append(errs, fmt.Errorf("Missing file field in default_credentials of plugin checks. Stopping execution"))
+		} else if err := p.DefaultCredentials.Load(dir); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := p.ValidateMethod(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := p.ParseRequestHeaders(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := p.Request.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := p.ValidateSteps(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := p.ValidateEndpoints(); err != nil {
+		errs = append(errs, err)
+	}
+	if p.QueryString != "" && len(p.QueryStrings) > 0 {
+		errs = append(errs, fmt.Errorf("query_string and query_strings can't be set at the same time in plugin checks. Stopping execution"))
+	}
+	if p.Endpoint == "" {
+		if len(p.Endpoints) > 0 {
+			errs = append(errs, fmt.Errorf("URI and URIs can't be set at the same time in plugin checks. Stopping execution"))
+		}
+	}
+	return errs
+}
+
+// Validate validates the conditions of the check, compiles its regexes and loads its match file, relative to dir.
+// Every problem is returned, so the lint command can report them all at once.
+func (check *Check) Validate(dir string) []error {
+	var errs []error
+	if check.Name == "" {
+		errs = append(errs, fmt.Errorf("Missing or empty name field in plugin checks. Stopping execution"))
+	}
+	if check.Description == "" {
+		errs = append(errs, fmt.Errorf("Missing or empty description field in %s plugin checks. Stopping execution", check.Name))
+	}
+	if check.Remediation == "" {
+		errs = append(errs, fmt.Errorf("Missing or empty remediation field in %s plugin checks. Stopping execution", check.Name))
+	}
+	if check.Severity == "" {
+		errs = append(errs, fmt.Errorf("Missing severity field in %s plugin checks. Stopping execution", check.Name))
+	} else if !ValidSeverity(check.Severity) {
+		errs = append(errs, fmt.Errorf("Invalid severity : %s. Please use : %s", check.Severity, SeveritiesAsString()))
+	}
+	if check.Confidence != "" && !ValidConfidence(check.Confidence) {
+		errs = append(errs, fmt.Errorf("Invalid confidence : %s. Please use : %s", check.Confidence, ConfidencesAsString()))
+	}
+	for _, header := range append(append([]string{}, check.Headers...), check.NoHeaders...) {
+		if key, _, _ := ParseHeaderCondition(header); key == "" {
+			errs = append(errs, fmt.Errorf("Invalid header format : %s. Format should be KEY, KEY:* or KEY:VALUE", header))
+		}
+	}
+	if err := check.LoadMatchFile(dir); err != nil {
+		errs = append(errs, err)
+	}
+	if err := check.CompileRegexes(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := check.ValidateStatusCodes(); err != nil {
+		errs = append(errs, err)
+	}
+	if check.Conditions != nil {
+		if err := check.Conditions.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%v in %s plugin checks. Stopping execution", err, check.Name))
+		}
+	}
+	if check.EmptyBody && check.NonEmptyBody {
+		errs = append(errs, fmt.Errorf("empty_body and non_empty_body can't be set at the same time in %s plugin checks. Stopping execution", check.Name))
+	}
+	if check.BodyEquals != nil && (check.EmptyBody || check.NonEmptyBody) {
+		errs = append(errs, fmt.Errorf("body_equals can't be set along with empty_body or non_empty_body in %s plugin checks. Stopping execution", check.Name))
+	}
+	if check.TrimBody && check.BodyEquals == nil && !check.EmptyBody && !check.NonEmptyBody {
+		errs = append(errs, fmt.Errorf("trim_body needs body_equals, empty_body or non_empty_body in %s plugin checks. Stopping execution", check.Name))
+	}
+	if (check.MinBodySize != nil && *check.MinBodySize < 0) || (check.MaxBodySize != nil && *check.MaxBodySize < 0) {
+		errs = append(errs, fmt.Errorf("min_body_size and max_body_size must be positive in %s plugin checks. Stopping execution", check.Name))
+	}
+	if check.MinBodySize != nil && check.MaxBodySize != nil && *check.MinBodySize > *check.MaxBodySize {
+		errs = append(errs, fmt.Errorf("min_body_size can't be greater than max_body_size in %s plugin checks. Stopping execution", check.Name))
+	}
+	if check.Repeat < 0 || check.RequireHits < 0 {
+		errs = append(errs, fmt.Errorf("repeat and require_hits must be positive in %s plugin checks. Stopping execution", check.Name))
+	}
+	if check.RequireHits > check.Repeat && check.RequireHits > 1 {
+		errs = append(errs, fmt.Errorf("require_hits can't be greater than repeat in %s plugin checks. Stopping execution", check.Name))
+	}
+	if check.Cookie != nil && check.Cookie.Name == "" {
+		errs = append(errs, fmt.Errorf("Missing cookie name in %s plugin checks. Stopping execution", check.Name))
+	}
+	if check.WWWAuthenticate != nil && check.WWWAuthenticate.Scheme == "" && check.WWWAuthenticate.Realm == "" {
+		errs = append(errs, fmt.Errorf("Empty www_authenticate field in %s plugin checks. Stopping execution", check.Name))
+	}
+	if check.ServerVersion != nil {
+		if err := check.ServerVersion.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%v in %s plugin checks. Stopping execution", err, check.Name))
+		}
+	}
+	if check.JSONMatch != nil {
+		if err := check.JSONMatch.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%v in %s plugin checks. Stopping execution", err, check.Name))
+		}
+	}
+	if check.TLS != nil {
+		if err := check.TLS.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%v in %s plugin checks. Stopping execution", err, check.Name))
+		}
+	}
+	if check.SizeRatio != 0 && check.SizeRatio <= 1 {
+		errs = append(errs, fmt.Errorf("size_ratio must be greater than 1 in %s plugin checks. Stopping execution", check.Name))
+	}
+	return errs
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionConstraintRegexp matches constraints such as "< 2.4.50" or ">=1.18"
+var versionConstraintRegexp = regexp.MustCompile(`^\s*(<=|>=|!=|==|=|<|>)\s*(\d+(?:\.\d+)*)\s*$`)
+
+// serverProductRegexp matches the product/version tokens of a Server header, eg. "Apache/2.4.49" in "Apache/2.4.49 (Unix)"
+var serverProductRegexp = regexp.MustCompile(`([^\s/()]+)/(\d+(?:\.\d+)*[^\s()]*)`)
+
+// ServerVersionCheck flags the responses whose Server header announces a version matching the constraint,
+// eg. {product: Apache, version: "< 2.4.50"}. Without product, the first product of the header is used.
+type ServerVersionCheck struct {
+	Product string `yaml:"product"`
+	Version string `yaml:"version"`
+}
+
+// Validate ensures the version constraint is well formed
+func (s *ServerVersionCheck) Validate() error {
+	if !versionConstraintRegexp.MatchString(s.Version) {
+		return fmt.Errorf("Invalid version constraint : %q. Format should be OPERATOR VERSION, eg. \"< 2.4.50\"", s.Version)
+	}
+	return nil
+}
+
+// Match reports whether the version of the product in the Server header satisfies the constraint.
+// The returned string names the detected version and the constraint.
+func (s *ServerVersionCheck) Match(header http.Header) (bool, string) {
+	m := versionConstraintRegexp.FindStringSubmatch(s.Version)
+	if m == nil {
+		return false, fmt.Sprintf("invalid version constraint %q", s.Version)
+	}
+	operator, required := m[1], m[2]
+
+	product, version := s.detect(header.Values("Server"))
+	if version == "" {
+		return false, "no version found in the Server header"
+	}
+	cmp := CompareVersions(version, required)
+	var matched bool
+	switch operator {
+	case "<":
+		matched = cmp < 0
+	case "<=":
+		matched = cmp <= 0
+	case ">":
+		matched = cmp > 0
+	case ">=":
+		matched = cmp >= 0
+	case "!=":
+		matched = cmp != 0
+	default:
+		matched = cmp == 0
+	}
+	return matched, fmt.Sprintf("detected %s %s, flagged versions %s %s", product, version, operator, required)
+}
+
+// detect returns the first product of the Server headers with its version, filtered by the expected product
+func (s *ServerVersionCheck) detect(values []string) (string, string) {
+	for _, value := range values {
+		for _, m := range serverProductRegexp.FindAllStringSubmatch(value, -1) {
+			if s.Product == "" || strings.EqualFold(m[1], s.Product) {
+				return m[1], m[2]
+			}
+		}
+	}
+	return "", ""
+}
+
+// CompareVersions compares the numeric components of two versions, like strings.Compare.
+// Vendor suffixes (eg. "1.0.2k", "2.4.41-ubuntu") are ignored and the missing components count as 0.
+func CompareVersions(a string, b string) int {
+	va, vb := versionComponents(a), versionComponents(b)
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var ca, cb int
+		if i < len(va) {
+			ca = va[i]
+		}
+		if i < len(vb) {
+			cb = vb[i]
+		}
+		if ca < cb {
+			return -1
+		}
+		if ca > cb {
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionComponents reads the leading dot-separated numbers of a version
+func versionComponents(version string) []int {
+	var components []int
+	for _, part := range strings.Split(version, ".") {
+		digits := part
+		for i, r := range part {
+			if r < '0' || r > '9' {
+				digits = part[:i]
+				break
+			}
+		}
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			break
+		}
+		components = append(components, n)
+		if len(digits) != len(part) {
+			// a suffix ends the version
+			break
+		}
+	}
+	return components
+}
+
+func (s *ServerVersionCheck) Equals(version *ServerVersionCheck) bool {
+	if s == nil || version == nil {
+		return s == version
+	}
+	return s.Product == version.Product && s.Version == version.Version
+}
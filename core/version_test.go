@@ -0,0 +1,73 @@
+package core_test
+
+import (
+	"gochopchop/core"
+	"net/http"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	var tests = map[string]struct {
+		a, b string
+		want int
+	}{
+		"equal":               {a: "2.4.50", b: "2.4.50", want: 0},
+		"lower patch":         {a: "2.4.49", b: "2.4.50", want: -1},
+		"numeric not lexical": {a: "2.4.100", b: "2.4.50", want: 1},
+		"missing component":   {a: "10.0", b: "10.0.0", want: 0},
+		"vendor suffix":       {a: "2.4.41-ubuntu", b: "2.4.41", want: 0},
+		"letter suffix":       {a: "1.0.2k", b: "1.0.3", want: -1},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have := core.CompareVersions(tc.a, tc.b)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestServerVersionCheckMatch(t *testing.T) {
+	header := http.Header{"Server": []string{"Apache/2.4.49 (Unix) OpenSSL/1.0.2k"}}
+	var tests = map[string]struct {
+		check  *core.ServerVersionCheck
+		header http.Header
+		want   bool
+	}{
+		"Outdated":             {check: &core.ServerVersionCheck{Product: "Apache", Version: "< 2.4.50"}, header: header, want: true},
+		"Up to date":           {check: &core.ServerVersionCheck{Product: "Apache", Version: "<2.4.49"}, header: header, want: false},
+		"Second product":       {check: &core.ServerVersionCheck{Product: "openssl", Version: "<= 1.0.2"}, header: header, want: true},
+		"First product":        {check: &core.ServerVersionCheck{Version: ">= 2.4"}, header: header, want: true},
+		"Other product":        {check: &core.ServerVersionCheck{Product: "nginx", Version: "< 1.20"}, header: header, want: false},
+		"No version announced": {check: &core.ServerVersionCheck{Product: "nginx", Version: "< 1.20"}, header: http.Header{"Server": []string{"nginx"}}, want: false},
+		"No Server header":     {check: &core.ServerVersionCheck{Version: "< 1.20"}, header: http.Header{}, want: false},
+		"Not equal":            {check: &core.ServerVersionCheck{Product: "Microsoft-IIS", Version: "!= 10.0"}, header: http.Header{"Server": []string{"Microsoft-IIS/8.5"}}, want: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			have, _ := tc.check.Match(tc.header)
+			if have != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, have)
+			}
+		})
+	}
+
+	_, detail := (&core.ServerVersionCheck{Product: "Apache", Version: "< 2.4.50"}).Match(header)
+	if detail != "detected Apache 2.4.49, flagged versions < 2.4.50" {
+		t.Errorf("expected the detected and flagged versions, got: %v", detail)
+	}
+}
+
+func TestServerVersionCheckValidate(t *testing.T) {
+	for _, constraint := range []string{"2.4.50", "< 2.4.x", "~ 2.4", ""} {
+		if err := (&core.ServerVersionCheck{Version: constraint}).Validate(); err == nil {
+			t.Errorf("expected an error for constraint %q", constraint)
+		}
+	}
+	if err := (&core.ServerVersionCheck{Version: ">= 1.18"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"gochopchop/internal"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// blockingStatusCodes are the answers of a WAF blocking everything, rather than of a server not finding a path
+var blockingStatusCodes = map[int]bool{403: true, 406: true, 429: true}
+
+// blockers caches whether each scanned host seems to block all the requests, eg. behind a WAF.
+// A host answering a random nonexistent path with a 403 likely answers the same to every plugin,
+// so its body checks fail and the missing findings don't mean the host is clean.
+type blockers struct {
+	mux   sync.Mutex
+	hosts map[string]*blocker
+}
+
+type blocker struct {
+	once    sync.Once
+	blocked bool
+}
+
+// probeBlocking requests a random nonexistent path of the host once, and tells whether the host seems to block all the requests
+func (s Scanner) probeBlocking(ctx context.Context, host string) bool {
+	s.blockers.mux.Lock()
+	b, ok := s.blockers.hosts[host]
+	if !ok {
+		b = &blocker{}
+		s.blockers.hosts[host] = b
+	}
+	s.blockers.mux.Unlock()
+
+	b.once.Do(func() {
+		resp, err := s.fetchHostPath(ctx, host, "/"+randomPath())
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error("Could not detect whether ", host, " blocks the requests: ", err)
+			}
+			return
+		}
+		if blockingStatusCodes[resp.StatusCode] {
+			b.blocked = true
+			log.WithFields(log.Fields{"host": host, "status": resp.StatusCode}).Warn("Host possibly blocked: a nonexistent path is refused too, the findings of the host may be missing")
+		}
+	})
+	return b.blocked
+}
+
+// possiblyBlocked tells whether the host was found to block all the requests, without probing it
+func (s Scanner) possiblyBlocked(host string) bool {
+	s.blockers.mux.Lock()
+	defer s.blockers.mux.Unlock()
+	b, ok := s.blockers.hosts[host]
+	return ok && b.blocked
+}
+
+// BlockedHosts returns the sorted scanned hosts that seem to block all the requests, with DetectBlocking
+func (s Scanner) BlockedHosts() []string {
+	s.blockers.mux.Lock()
+	defer s.blockers.mux.Unlock()
+	hosts := make([]string, 0)
+	for host, b := range s.blockers.hosts {
+		if b.blocked {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// fetchHostPath requests a path of the host outside of the plugins, with the limits of the scan
+func (s Scanner) fetchHostPath(ctx context.Context, host string, path string) (*internal.HTTPResponse, error) {
+	req := &internal.HTTPRequest{URL: host + JoinBasePath(s.BasePath, path), Method: "GET"}
+	s.applyTargetOptions(req, host)
+	if err := s.HostLimiter.Wait(ctx, req.URL); err != nil {
+		return nil, err
+	}
+	if err := s.HostSemaphore.Acquire(ctx, req.URL); err != nil {
+		return nil, err
+	}
+	defer s.HostSemaphore.Release(req.URL)
+	s.requestedURLs.Add(req.URL)
+	atomic.AddInt64(s.sent, 1)
+	return s.Fetcher.Fetch(req)
+}
+
+// randomPath returns a path that no server should have
+func randomPath() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "chopchop-" + hex.EncodeToString(b)
+}
@@ -12,7 +12,10 @@ require (
 	github.com/sirupsen/logrus v1.7.0
 	github.com/spf13/afero v1.1.2
 	github.com/spf13/cobra v1.1.1
+	github.com/spf13/pflag v1.0.5
 	go.mongodb.org/mongo-driver v1.4.3 // indirect
+	golang.org/x/net v0.0.0-20201110031124-69a78807bb2b
 	golang.org/x/sys v0.0.0-20201110211018-35f3e6cf4a65 // indirect
+	golang.org/x/time v0.0.0-20201208040808-7e3f01d25324
 	gopkg.in/yaml.v2 v2.3.0
 )
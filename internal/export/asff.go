@@ -0,0 +1,153 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gochopchop/core"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const asffSchemaVersion = "2018-10-08"
+
+// asffFinding is a finding in the AWS Security Finding Format, as imported by AWS Security Hub
+type asffFinding struct {
+	SchemaVersion string          `json:"SchemaVersion"`
+	Id            string          `json:"Id"`
+	ProductArn    string          `json:"ProductArn"`
+	GeneratorId   string          `json:"GeneratorId"`
+	AwsAccountId  string          `json:"AwsAccountId"`
+	Types         []string        `json:"Types"`
+	CreatedAt     string          `json:"CreatedAt"`
+	UpdatedAt     string          `json:"UpdatedAt"`
+	Severity      asffSeverity    `json:"Severity"`
+	Confidence    int             `json:"Confidence,omitempty"`
+	Title         string          `json:"Title"`
+	Description   string          `json:"Description"`
+	Remediation   asffRemediation `json:"Remediation"`
+	Resources     []asffResource  `json:"Resources"`
+}
+
+type asffSeverity struct {
+	Label string `json:"Label"`
+}
+
+type asffRemediation struct {
+	Recommendation asffRecommendation `json:"Recommendation"`
+}
+
+type asffRecommendation struct {
+	Text string `json:"Text"`
+	Url  string `json:"Url,omitempty"`
+}
+
+type asffResource struct {
+	Type string `json:"Type"`
+	Id   string `json:"Id"`
+}
+
+// ExportASFF exports the output in the AWS Security Finding Format, to be imported in AWS Security Hub
+// with `aws securityhub batch-import-findings --findings file://<export-filename>.asff.json`
+func ExportASFF(filename string, out []core.Output, config core.ASFFConfig) error {
+	exportFilename := fmt.Sprintf("%s.asff.json", filename)
+
+	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = exportASFF(f, out, config, time.Now())
+	if err != nil {
+		return err
+	}
+	log.Info("Results were exported for AWS Security Hub in: ", exportFilename)
+	return nil
+}
+
+func exportASFF(file IFile, out []core.Output, config core.ASFFConfig, date time.Time) error {
+	productArn := config.ProductArn
+	if productArn == "" {
+		productArn = fmt.Sprintf("arn:aws:securityhub:%s:%s:product/%s/default", config.Region, config.AccountID, config.AccountID)
+	}
+	timestamp := date.UTC().Format(time.RFC3339)
+
+	findings := make([]asffFinding, 0, len(out))
+	for _, output := range out {
+		description := output.Description
+		if description == "" {
+			description = output.Name
+		}
+		if output.Details != "" {
+			description = fmt.Sprintf("%s\n\n%s", description, output.Details)
+		}
+		recommendation := asffRecommendation{Text: truncate(output.Remediation, 512)}
+		if len(output.References) > 0 {
+			recommendation.Url = output.References[0]
+		}
+		findings = append(findings, asffFinding{
+			SchemaVersion: asffSchemaVersion,
+			Id:            asffID(output),
+			ProductArn:    productArn,
+			GeneratorId:   fmt.Sprintf("chopchop/%s", output.Name),
+			AwsAccountId:  config.AccountID,
+			Types:         []string{"Software and Configuration Checks/Vulnerabilities"},
+			CreatedAt:     timestamp,
+			UpdatedAt:     timestamp,
+			Severity:      asffSeverity{Label: asffSeverityLabel(output.Severity)},
+			Confidence:    asffConfidence(output.Confidence),
+			Title:         truncate(output.Name, 256),
+			Description:   truncate(description, 1024),
+			Remediation:   asffRemediation{Recommendation: recommendation},
+			Resources:     []asffResource{{Type: "Other", Id: output.URL}},
+		})
+	}
+
+	jsonbytes, err := json.Marshal(findings)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(string(jsonbytes)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// asffID identifies the finding by its url and check, so a new scan updates the findings instead of duplicating them
+func asffID(output core.Output) string {
+	sum := sha256.Sum256([]byte(output.URL + "\n" + output.Name))
+	return fmt.Sprintf("chopchop/%s", hex.EncodeToString(sum[:16]))
+}
+
+// asffSeverityLabel maps a severity to the ASFF labels (CRITICAL, HIGH, MEDIUM, LOW, INFORMATIONAL)
+func asffSeverityLabel(severity string) string {
+	return strings.ToUpper(severity)
+}
+
+// asffConfidence maps a confidence to the 0-100 ASFF scale, 0 leaving the field out when the check has none
+func asffConfidence(confidence string) int {
+	switch confidence {
+	case "High":
+		return 90
+	case "Medium":
+		return 60
+	case "Low":
+		return 30
+	default:
+		return 0
+	}
+}
+
+// truncate cuts the string to the maximum length of an ASFF field
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
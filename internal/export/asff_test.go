@@ -0,0 +1,66 @@
+package export
+
+import (
+	"encoding/json"
+	"gochopchop/core"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestExportASFF(t *testing.T) {
+	appfs := afero.Afero{Fs: afero.NewMemMapFs()}
+	filename := "formatasff"
+	date := time.Date(2020, 12, 1, 10, 0, 0, 0, time.UTC)
+	config := core.ASFFConfig{AccountID: "123456789012", Region: "eu-west-1"}
+
+	outputs := []core.Output{
+		{URL: "http://problems/.git/config", Endpoint: "/.git/config", Name: "Git exposed", Severity: "High", Remediation: "Do not deploy .git folders", References: []string{"https://owasp.org"}, Confidence: "High"},
+		{URL: "http://problems/", Endpoint: "/", Name: "Server header", Severity: "Informational", Description: strings.Repeat("a", 2000)},
+	}
+	var tests = map[string]struct {
+		config      core.ASFFConfig
+		wantProduct string
+	}{
+		"default product": {config: config, wantProduct: "arn:aws:securityhub:eu-west-1:123456789012:product/123456789012/default"},
+		"custom product":  {config: core.ASFFConfig{AccountID: "123456789012", ProductArn: "arn:custom"}, wantProduct: "arn:custom"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, _ := appfs.Create(filename)
+			_ = exportASFF(f, outputs, tc.config, date)
+			contents, _ := appfs.ReadFile(filename)
+			var findings []asffFinding
+			if err := json.Unmarshal(contents, &findings); err != nil {
+				t.Fatalf("expected: valid JSON, got: %v", err)
+			}
+			if len(findings) != 2 {
+				t.Fatalf("expected: %v, got: %v", 2, len(findings))
+			}
+			git := findings[0]
+			if git.ProductArn != tc.wantProduct {
+				t.Errorf("expected: %v, got: %v", tc.wantProduct, git.ProductArn)
+			}
+			if git.SchemaVersion != "2018-10-08" || git.AwsAccountId != "123456789012" || git.CreatedAt != "2020-12-01T10:00:00Z" {
+				t.Errorf("unexpected finding: %+v", git)
+			}
+			if git.Severity.Label != "HIGH" || findings[1].Severity.Label != "INFORMATIONAL" {
+				t.Errorf("expected: HIGH and INFORMATIONAL, got: %v and %v", git.Severity.Label, findings[1].Severity.Label)
+			}
+			if git.Confidence != 90 || findings[1].Confidence != 0 {
+				t.Errorf("expected: 90 and 0, got: %v and %v", git.Confidence, findings[1].Confidence)
+			}
+			if git.Resources[0].Id != "http://problems/.git/config" || git.Remediation.Recommendation.Url != "https://owasp.org" {
+				t.Errorf("unexpected finding: %+v", git)
+			}
+			if git.Id != asffID(outputs[0]) || git.Id == findings[1].Id {
+				t.Errorf("expected: a stable id per url and check, got: %v and %v", git.Id, findings[1].Id)
+			}
+			if len(findings[1].Description) != 1024 {
+				t.Errorf("expected: %v, got: %v", 1024, len(findings[1].Description))
+			}
+		})
+	}
+}
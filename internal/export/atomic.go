@@ -0,0 +1,41 @@
+package export
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// atomicFile is written next to its destination and only renamed to it once committed,
+// so the destination is either absent or complete, even if the scan is killed meanwhile
+type atomicFile struct {
+	*os.File
+	filename string
+}
+
+func createAtomic(filename string) (*atomicFile, error) {
+	f, err := ioutil.TempFile(filepath.Dir(filename), fmt.Sprintf(".%s.*", filepath.Base(filename)))
+	if err != nil {
+		return nil, err
+	}
+	return &atomicFile{File: f, filename: filename}, nil
+}
+
+// Commit closes the file and moves it to its destination
+func (a *atomicFile) Commit() error {
+	defer os.Remove(a.Name())
+	if err := a.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(a.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(a.Name(), a.filename)
+}
+
+// Abort closes and removes the file, leaving the destination untouched
+func (a *atomicFile) Abort() {
+	a.Close()
+	os.Remove(a.Name())
+}
@@ -0,0 +1,93 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gochopchop/core"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CheckEntry describes a check of the signatures, to inventory the catalog
+type CheckEntry struct {
+	Endpoints   []string `json:"endpoints"`
+	Category    string   `json:"category,omitempty"`
+	Name        string   `json:"name"`
+	Severity    string   `json:"severity"`
+	Description string   `json:"description"`
+	Remediation string   `json:"remediation"`
+	Disabled    bool     `json:"disabled,omitempty"`
+}
+
+// NewCheckEntry describes the check of the plugin, its endpoints followed by the query string of the plugin
+func NewCheckEntry(plugin *core.Plugin, check *core.Check) CheckEntry {
+	endpoints := make([]string, 0)
+	for _, target := range plugin.Targets("", "") {
+		endpoints = append(endpoints, target.Endpoint)
+	}
+	return CheckEntry{
+		Endpoints:   endpoints,
+		Category:    plugin.Category,
+		Name:        check.Name,
+		Severity:    check.Severity,
+		Description: check.Description,
+		Remediation: check.Remediation,
+		Disabled:    !check.IsEnabled(),
+	}
+}
+
+// ExportChecks exports the checks in the format (json or csv), on stdout when the filename is empty
+func ExportChecks(filename string, format string, checks []CheckEntry) error {
+	if filename == "" {
+		return exportChecks(os.Stdout, format, checks)
+	}
+	exportFilename := fmt.Sprintf("%s.%s", filename, format)
+
+	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = exportChecks(f, format, checks)
+	if err != nil {
+		return err
+	}
+	log.Info("Checks were exported as ", format, " in: ", exportFilename)
+	return nil
+}
+
+func exportChecks(file IFile, format string, checks []CheckEntry) error {
+	var b strings.Builder
+	switch format {
+	case "json":
+		jsonbytes, err := json.Marshal(checks)
+		if err != nil {
+			return err
+		}
+		b.Write(jsonbytes)
+		b.WriteString("\n")
+	case "csv":
+		// the descriptions often contain commas, the fields are quoted when needed
+		w := csv.NewWriter(&b)
+		if err := w.Write([]string{"endpoints", "category", "name", "severity", "description", "remediation"}); err != nil {
+			return err
+		}
+		for _, check := range checks {
+			if err := w.Write([]string{strings.Join(check.Endpoints, " "), check.Category, check.Name, check.Severity, check.Description, check.Remediation}); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown export format of the checks: %s", format)
+	}
+	_, err := file.WriteString(b.String())
+	return err
+}
@@ -0,0 +1,52 @@
+package export
+
+import (
+	"gochopchop/core"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestExportChecks(t *testing.T) {
+	appfs := afero.Afero{Fs: afero.NewMemMapFs()}
+	filename := "formatchecks"
+
+	plugins := []*core.Plugin{
+		{Endpoint: "/.git/config", Category: "Information Disclosure", Checks: []*core.Check{{Name: "Git exposed", Severity: "High", Description: "Git folder, exposed", Remediation: "Do not deploy .git folders"}}},
+		{Endpoints: []string{"/a", "/b"}, QueryString: "id=1", Checks: []*core.Check{{Name: "Ids", Severity: "Low", Remediation: "hide it"}}},
+	}
+	var checks []CheckEntry
+	for _, plugin := range plugins {
+		for _, check := range plugin.Checks {
+			checks = append(checks, NewCheckEntry(plugin, check))
+		}
+	}
+	var tests = map[string]struct {
+		format  string
+		checks  []CheckEntry
+		want    string
+		wantErr bool
+	}{
+		"json": {format: "json", checks: checks, want: `[{"endpoints":["/.git/config"],"category":"Information Disclosure","name":"Git exposed","severity":"High","description":"Git folder, exposed","remediation":"Do not deploy .git folders"},` +
+			`{"endpoints":["/a?id=1","/b?id=1"],"name":"Ids","severity":"Low","description":"","remediation":"hide it"}]` + "\n"},
+		"csv": {format: "csv", checks: checks, want: "endpoints,category,name,severity,description,remediation\n" +
+			"/.git/config,Information Disclosure,Git exposed,High,\"Git folder, exposed\",Do not deploy .git folders\n" +
+			"/a?id=1 /b?id=1,,Ids,Low,,hide it\n"},
+		"no checks":      {format: "json", checks: []CheckEntry{}, want: "[]\n"},
+		"unknown format": {format: "xml", checks: checks, want: "", wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, _ := appfs.Create(filename)
+			err := exportChecks(f, tc.format, tc.checks)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+			contents, _ := appfs.ReadFile(filename)
+			got := string(contents)
+			if got != tc.want {
+				t.Errorf("want : %q, got : %q", tc.want, got)
+			}
+		})
+	}
+}
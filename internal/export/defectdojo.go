@@ -0,0 +1,112 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"gochopchop/core"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defectDojoFindings is DefectDojo's generic findings import format
+type defectDojoFindings struct {
+	Findings []defectDojoFinding `json:"findings"`
+}
+
+type defectDojoFinding struct {
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Severity    string               `json:"severity"`
+	Mitigation  string               `json:"mitigation"`
+	Date        string               `json:"date"`
+	Active      bool                 `json:"active"`
+	Verified    bool                 `json:"verified"`
+	Endpoints   []defectDojoEndpoint `json:"endpoints"`
+}
+
+type defectDojoEndpoint struct {
+	Protocol string `json:"protocol,omitempty"`
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Query    string `json:"query,omitempty"`
+}
+
+// ExportDefectDojo exports the output in DefectDojo's generic findings JSON format
+func ExportDefectDojo(filename string, out []core.Output) error {
+	exportFilename := fmt.Sprintf("%s.defectdojo.json", filename)
+
+	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = exportDefectDojo(f, out, time.Now())
+	if err != nil {
+		return err
+	}
+	log.Info("Results were exported for DefectDojo in: ", exportFilename)
+	return nil
+}
+
+func exportDefectDojo(file IFile, out []core.Output, date time.Time) error {
+	findings := defectDojoFindings{Findings: make([]defectDojoFinding, 0, len(out))}
+	for _, output := range out {
+		description := output.Description
+		if description == "" {
+			description = output.Name
+		}
+		if output.Details != "" {
+			description = fmt.Sprintf("%s\n\n%s", description, output.Details)
+		}
+		findings.Findings = append(findings.Findings, defectDojoFinding{
+			Title:       output.Name,
+			Description: description,
+			Severity:    defectDojoSeverity(output.Severity),
+			Mitigation:  output.Remediation,
+			Date:        date.Format("2006-01-02"),
+			Active:      true,
+			Verified:    false,
+			Endpoints:   []defectDojoEndpoint{defectDojoEndpointOf(output.URL)},
+		})
+	}
+
+	jsonbytes, err := json.Marshal(findings)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(string(jsonbytes)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// defectDojoSeverity maps a severity to DefectDojo's severities (Critical, High, Medium, Low, Info)
+func defectDojoSeverity(severity string) string {
+	if severity == "Informational" {
+		return "Info"
+	}
+	return severity
+}
+
+func defectDojoEndpointOf(rawURL string) defectDojoEndpoint {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return defectDojoEndpoint{Host: rawURL}
+	}
+	endpoint := defectDojoEndpoint{
+		Protocol: u.Scheme,
+		Host:     u.Hostname(),
+		Path:     u.Path,
+		Query:    u.RawQuery,
+	}
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		endpoint.Port = port
+	}
+	return endpoint
+}
@@ -0,0 +1,41 @@
+package export
+
+import (
+	"gochopchop/core"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func TestExportDefectDojo(t *testing.T) {
+	appfs := afero.Afero{Fs: afero.NewMemMapFs()}
+	filename := "formatdefectdojo"
+	date := time.Date(2020, 11, 16, 0, 0, 0, 0, time.UTC)
+
+	var tests = map[string]struct {
+		output []core.Output
+		want   string
+	}{
+		"correct formatting": {
+			output: []core.Output{
+				{URL: "https://foobar.com:8443/.git/config", Name: "Git exposed", Severity: "Informational", Remediation: "uninstall"},
+			},
+			want: `{"findings":[{"title":"Git exposed","description":"Git exposed","severity":"Info","mitigation":"uninstall","date":"2020-11-16","active":true,"verified":false,"endpoints":[{"protocol":"https","host":"foobar.com","port":8443,"path":"/.git/config"}]}]}`,
+		},
+		"no findings": {output: []core.Output{}, want: `{"findings":[]}`},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, _ := appfs.Create(filename)
+			if err := exportDefectDojo(f, tc.output, date); err != nil {
+				t.Fatalf("expected a nil error, got : %v", err)
+			}
+			contents, _ := appfs.ReadFile(filename)
+			got := string(contents)
+			if got != tc.want {
+				t.Errorf("want : %q, got : %q", tc.want, got)
+			}
+		})
+	}
+}
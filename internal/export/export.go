@@ -1,10 +1,14 @@
 package export
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"gochopchop/core"
+	"io"
 	"os"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -13,8 +17,8 @@ type IFile interface {
 	WriteString(input string) (n int, err error)
 }
 
-// ExportCSV exports the output in a CSV file
-func ExportCSV(filename string, out []core.Output) error {
+// ExportCSV exports the output in a CSV file, with the selected columns if any
+func ExportCSV(filename string, out []core.Output, columns []string) error {
 	exportFilename := fmt.Sprintf("%s.csv", filename)
 
 	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY, 0755)
@@ -23,7 +27,7 @@ func ExportCSV(filename string, out []core.Output) error {
 		return err
 	}
 
-	err = exportCSV(f, out)
+	err = exportCSV(f, out, columns)
 	if err != nil {
 		return err
 	}
@@ -31,24 +35,53 @@ func ExportCSV(filename string, out []core.Output) error {
 	return nil
 }
 
-func exportCSV(file IFile, out []core.Output) error {
-	_, err := file.WriteString("url,endpoint,severity,checkName,remediation\n")
-	if err != nil {
+func exportCSV(file IFile, out []core.Output, columns []string) error {
+	if _, err := file.WriteString(csvHeader(columns)); err != nil {
 		return err
 	}
 	for _, output := range out {
-		line := fmt.Sprintf("%s,%s,%s,%s,%s\n", output.URL, output.Endpoint, output.Severity, output.Name, output.Remediation)
-		_, err := file.WriteString(line)
-		if err != nil {
+		if _, err := file.WriteString(csvLine(output, columns)); err != nil {
 			return err
 		}
 	}
-
 	return nil
 }
 
+func csvHeader(columns []string) string {
+	if len(columns) == 0 {
+		return csvRecord([]string{"url", "endpoint", "severity", "checkName", "remediation", "category", "duration"})
+	}
+	return csvRecord(columns)
+}
+
+func csvLine(output core.Output, columns []string) string {
+	if len(columns) == 0 {
+		return csvRecord([]string{output.URL, output.Endpoint, output.Severity, output.Name, output.Remediation, output.Category, output.Column("duration")})
+	}
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = output.Column(column)
+	}
+	return csvRecord(values)
+}
+
+// csvRecord formats a line of the CSV export, the values with a comma, a quote or a line break being quoted
+func csvRecord(values []string) string {
+	var line strings.Builder
+	w := csv.NewWriter(&line)
+	w.Write(values)
+	w.Flush()
+	return line.String()
+}
+
+// jsonReport is the json export, the findings along with the metadata of the run
+type jsonReport struct {
+	Findings []core.Output `json:"findings"`
+	Metadata core.Metadata `json:"metadata"`
+}
+
 // ExportJSON will save the output to a JSON file
-func ExportJSON(filename string, output []core.Output) error {
+func ExportJSON(filename string, output []core.Output, metadata core.Metadata) error {
 	exportFilename := fmt.Sprintf("%s.json", filename)
 
 	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY, 0755)
@@ -57,7 +90,7 @@ func ExportJSON(filename string, output []core.Output) error {
 		return err
 	}
 
-	err = exportJSON(f, output)
+	err = exportJSON(f, output, metadata)
 	if err != nil {
 		return err
 	}
@@ -65,8 +98,44 @@ func ExportJSON(filename string, output []core.Output) error {
 	return nil
 }
 
-func exportJSON(file IFile, output []core.Output) error {
-	jsonbytes, err := json.Marshal(output)
+// ReadJSON reads the findings of a json export
+func ReadJSON(filename string) ([]core.Output, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readJSON(f)
+}
+
+// readJSON reads the findings of the report, or of the array of findings exported by the previous versions
+func readJSON(r io.Reader) ([]core.Output, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid json export: %v", err)
+	}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		var outputs []core.Output
+		if err := json.Unmarshal(raw, &outputs); err != nil {
+			return nil, fmt.Errorf("invalid json export: %v", err)
+		}
+		return outputs, nil
+	}
+	var report jsonReport
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return nil, fmt.Errorf("invalid json export: %v", err)
+	}
+	if report.Findings == nil {
+		return nil, fmt.Errorf("invalid json export: no findings")
+	}
+	return report.Findings, nil
+}
+
+func exportJSON(file IFile, output []core.Output, metadata core.Metadata) error {
+	if output == nil {
+		output = []core.Output{}
+	}
+	jsonbytes, err := json.Marshal(jsonReport{Findings: output, Metadata: metadata})
 	if err != nil {
 		return err
 	}
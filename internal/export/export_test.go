@@ -3,6 +3,8 @@ package export
 import (
 	"gochopchop/core"
 	"gochopchop/mock"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -13,15 +15,26 @@ func TestExportCSV(t *testing.T) {
 	filename := "formatcsv"
 
 	var tests = map[string]struct {
-		output []core.Output
-		want   string
+		output  []core.Output
+		columns []string
+		want    string
 	}{
 		"correct formatting": {output: mock.FakeOutput, want: mock.FakeOutputAsCSV},
+		"selected columns":   {output: mock.FakeOutput[:2], columns: []string{"domain", "plugin", "severity"}, want: "domain,plugin,severity\nproblems,StatusCode200,Medium\nproblems,Headers,High\n"},
+		"quoted values": {
+			output:  []core.Output{{Name: "Config", Description: "Leaks the user, password and \"token\"", Remediation: "Remove it\nthen restart"}},
+			columns: []string{"plugin", "description", "remediation"},
+			want:    "plugin,description,remediation\nConfig,\"Leaks the user, password and \"\"token\"\"\",\"Remove it\nthen restart\"\n",
+		},
+		"quoted default columns": {
+			output: []core.Output{{URL: "http://problems", Endpoint: "/a,b", Severity: "Low", Name: "Comma", Remediation: "a, b"}},
+			want:   "url,endpoint,severity,checkName,remediation,category,duration\nhttp://problems,\"/a,b\",Low,Comma,\"a, b\",,0ms\n",
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
 			f, _ := appfs.Create(filename)
-			_ = exportCSV(f, tc.output)
+			_ = exportCSV(f, tc.output, tc.columns)
 			contents, _ := appfs.ReadFile(filename)
 			got := string(contents)
 			if got != tc.want {
@@ -34,16 +47,26 @@ func TestExportJSON(t *testing.T) {
 	appfs := afero.Afero{Fs: afero.NewMemMapFs()}
 	filename := "formatjson"
 
+	withRisks := mock.FakeMetadata
+	withRisks.RiskScores = []core.HostRisk{{Host: "http://problems", Score: 25, Findings: 6}}
+
 	var tests = map[string]struct {
-		output []core.Output
-		want   string
+		output   []core.Output
+		metadata core.Metadata
+		want     string
 	}{
-		"correct formatting": {output: mock.FakeOutput, want: mock.FakeOutputAsJSON},
+		"correct formatting": {output: mock.FakeOutput, metadata: mock.FakeMetadata, want: mock.FakeReportAsJSON},
+		"no findings":        {output: nil, metadata: mock.FakeMetadata, want: `{"findings":[],"metadata":` + mock.FakeMetadataAsJSON + `}`},
+		"risk scores": {
+			output:   nil,
+			metadata: withRisks,
+			want:     `{"findings":[],"metadata":` + strings.TrimSuffix(mock.FakeMetadataAsJSON, "}") + `,"riskScores":[{"host":"http://problems","score":25,"findings":6}]}}`,
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
 			f, _ := appfs.Create(filename)
-			_ = exportJSON(f, tc.output)
+			_ = exportJSON(f, tc.output, tc.metadata)
 			contents, _ := appfs.ReadFile(filename)
 			got := string(contents)
 			if got != tc.want {
@@ -52,3 +75,29 @@ func TestExportJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestReadJSON(t *testing.T) {
+	var tests = map[string]struct {
+		input  string
+		output []core.Output
+		nilErr bool
+	}{
+		"json export":                {input: mock.FakeReportAsJSON, output: mock.FakeOutput, nilErr: true},
+		"empty export":               {input: `{"findings":[],"metadata":{}}`, output: []core.Output{}, nilErr: true},
+		"array export":               {input: mock.FakeOutputAsJSON, output: mock.FakeOutput, nilErr: true},
+		"empty array export":         {input: " []", output: []core.Output{}, nilErr: true},
+		"object other than a report": {input: `{"url":"http://problems"}`, nilErr: false},
+		"invalid json":               {input: "url,endpoint", nilErr: false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			output, err := readJSON(strings.NewReader(tc.input))
+			if tc.nilErr != (err == nil) {
+				t.Fatalf("expected a nil error: %v, got : %v", tc.nilErr, err)
+			}
+			if tc.nilErr && !reflect.DeepEqual(output, tc.output) {
+				t.Errorf("expected: %v, got: %v", tc.output, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package export
+
+import (
+	"fmt"
+	"gochopchop/core"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// htmlReport is a self-contained page, the strings of the findings are escaped by html/template
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>ChopChop report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292e; }
+h2 { border-bottom: 1px solid #e1e4e8; padding-bottom: .3em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { border: 1px solid #e1e4e8; padding: .4em .6em; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+.badge { display: inline-block; padding: .1em .6em; border-radius: 1em; color: #fff; font-size: .85em; font-weight: bold; }
+.Critical { background: #8b008b; }
+.High { background: #d73a49; }
+.Medium { background: #e36209; }
+.Low { background: #28a745; }
+.Informational { background: #0366d6; }
+.remediation { color: #586069; }
+.evidence { background: #f6f8fa; padding: .4em; white-space: pre-wrap; word-break: break-all; font-size: .85em; }
+</style>
+</head>
+<body>
+<h1>ChopChop report</h1>
+<p>{{.Total}} finding(s) on {{len .Domains}} domain(s)</p>
+{{- range .Domains}}
+<h2>{{.Name}}</h2>
+{{- range .Severities}}
+<h3><span class="badge {{.Severity}}">{{.Severity}}</span> {{len .Findings}} finding(s)</h3>
+<table>
+<tr><th>Check</th><th>URL</th><th>Category</th><th>Details</th><th>Remediation</th></tr>
+{{- range .Findings}}
+<tr>
+<td>{{.Name}}{{if .Confidence}} <small>({{.Confidence}} confidence)</small>{{end}}{{if .Description}}<br><small>{{.Description}}</small>{{end}}</td>
+<td>{{.URL}}</td>
+<td>{{.Category}}</td>
+<td>{{.Details}}{{with .Evidence}}<pre class="evidence">{{.Snippet}}</pre>{{end}}</td>
+<td class="remediation">{{.Remediation}}{{range .References}}<br><a href="{{.}}">{{.}}</a>{{end}}</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+{{- else}}
+<p>No vulnerabilities found.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+type htmlDomain struct {
+	Name       string
+	Severities []htmlSeverity
+}
+
+type htmlSeverity struct {
+	Severity string
+	Findings []core.Output
+}
+
+// ExportHTML exports the output as a self-contained HTML report, readable without any tool
+func ExportHTML(filename string, out []core.Output) error {
+	exportFilename := fmt.Sprintf("%s.html", filename)
+
+	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = exportHTML(f, out)
+	if err != nil {
+		return err
+	}
+	log.Info("Results were exported as html in: ", exportFilename)
+	return nil
+}
+
+// exportHTML writes the findings grouped by domain, then by severity from the most to the least critical
+func exportHTML(file IFile, out []core.Output) error {
+	findings := make(map[string][]core.Output)
+	for _, output := range out {
+		domain := output.Column("domain")
+		findings[domain] = append(findings[domain], output)
+	}
+	names := make([]string, 0, len(findings))
+	for name := range findings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	domains := make([]htmlDomain, 0, len(names))
+	for _, name := range names {
+		domain := htmlDomain{Name: name}
+		for _, severity := range core.Severities() {
+			var s []core.Output
+			for _, output := range findings[name] {
+				if output.Severity == severity {
+					s = append(s, output)
+				}
+			}
+			if len(s) > 0 {
+				domain.Severities = append(domain.Severities, htmlSeverity{Severity: severity, Findings: s})
+			}
+		}
+		domains = append(domains, domain)
+	}
+
+	var b strings.Builder
+	err := htmlReport.Execute(&b, struct {
+		Total   int
+		Domains []htmlDomain
+	}{Total: len(out), Domains: domains})
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(b.String())
+	return err
+}
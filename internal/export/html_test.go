@@ -0,0 +1,56 @@
+package export
+
+import (
+	"gochopchop/core"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestExportHTML(t *testing.T) {
+	appfs := afero.Afero{Fs: afero.NewMemMapFs()}
+	filename := "formathtml"
+
+	outputs := []core.Output{
+		{URL: "http://problems/", Endpoint: "/", Name: "Server_Header", Severity: "Low", Remediation: "Remove the <b>Server</b> header"},
+		{URL: "http://problems/.git/config", Endpoint: "/.git/config", Name: "Git exposed", Severity: "High", Remediation: "Do not deploy .git folders", References: []string{"javascript:alert(1)"}},
+		{URL: "http://other/.git/config", Endpoint: "/.git/config", Name: "Git exposed", Severity: "High", Details: "<script>alert(1)</script>"},
+	}
+	var tests = map[string]struct {
+		output  []core.Output
+		want    []string
+		notWant []string
+	}{
+		"grouped by domain and severity": {output: outputs, want: []string{
+			"3 finding(s) on 2 domain(s)",
+			"<h2>other</h2>\n<h3><span class=\"badge High\">High</span> 1 finding(s)</h3>",
+			"<h2>problems</h2>\n<h3><span class=\"badge High\">High</span> 1 finding(s)</h3>",
+			"<h3><span class=\"badge Low\">Low</span> 1 finding(s)</h3>",
+		}},
+		"escaped strings": {output: outputs, want: []string{
+			"Remove the &lt;b&gt;Server&lt;/b&gt; header",
+			"&lt;script&gt;alert(1)&lt;/script&gt;",
+			"href=\"#ZgotmplZ\"",
+		}, notWant: []string{"<script>", "<b>Server"}},
+		"no findings": {output: nil, want: []string{"No vulnerabilities found."}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, _ := appfs.Create(filename)
+			_ = exportHTML(f, tc.output)
+			contents, _ := appfs.ReadFile(filename)
+			got := string(contents)
+			for _, want := range tc.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("want : %q in %q", want, got)
+				}
+			}
+			for _, notWant := range tc.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("did not want : %q in %q", notWant, got)
+				}
+			}
+		})
+	}
+}
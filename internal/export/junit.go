@@ -0,0 +1,93 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"gochopchop/core"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// junitTestSuites is the JUnit XML report read by the CI test panels (Jenkins, GitLab, ...)
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string       `xml:"name,attr"`
+	ClassName string       `xml:"classname,attr"`
+	Failure   junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// ExportJUnit exports the output as a JUnit XML report, each finding being a failed test case
+func ExportJUnit(filename string, out []core.Output) error {
+	exportFilename := fmt.Sprintf("%s.junit.xml", filename)
+
+	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = exportJUnit(f, out)
+	if err != nil {
+		return err
+	}
+	log.Info("Results were exported as JUnit in: ", exportFilename)
+	return nil
+}
+
+// exportJUnit writes a single test suite with a failed test case per finding, named after its check and classed by domain.
+// A scan without findings gives an empty suite without failures.
+func exportJUnit(file IFile, out []core.Output) error {
+	suite := junitTestSuite{Name: "ChopChop", Tests: len(out), Failures: len(out), TestCases: []junitTestCase{}}
+	for _, output := range out {
+		var text strings.Builder
+		fmt.Fprintf(&text, "URL: %s\nSeverity: %s\nRemediation: %s", output.URL, output.Severity, output.Remediation)
+		if output.Details != "" {
+			fmt.Fprintf(&text, "\nDetails: %s", output.Details)
+		}
+		if output.Evidence != nil {
+			fmt.Fprintf(&text, "\nEvidence: %s", output.Evidence)
+		}
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      output.Name,
+			ClassName: output.Column("domain"),
+			Failure: junitFailure{
+				Message: fmt.Sprintf("[%s] %s", output.Severity, output.Remediation),
+				Type:    output.Severity,
+				Text:    text.String(),
+			},
+		})
+	}
+
+	report := junitTestSuites{Name: "ChopChop", Tests: suite.Tests, Failures: suite.Failures, Suites: []junitTestSuite{suite}}
+	xmlbytes, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(xml.Header + string(xmlbytes) + "\n"); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"gochopchop/core"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestExportJUnit(t *testing.T) {
+	appfs := afero.Afero{Fs: afero.NewMemMapFs()}
+	filename := "formatjunit"
+
+	var tests = map[string]struct {
+		output []core.Output
+		want   string
+	}{
+		"failure per finding": {
+			output: []core.Output{
+				{URL: "https://foobar.com/.git/config", Name: "Git exposed", Severity: "High", Remediation: "Do not deploy <.git>"},
+				{URL: "https://other.com:8443/", Name: "Server header", Severity: "Low", Remediation: "hide it", Details: "Server: Apache/2.4.1"},
+			},
+			want: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<testsuites name="ChopChop" tests="2" failures="2">` + "\n" +
+				`  <testsuite name="ChopChop" tests="2" failures="2" errors="0">` + "\n" +
+				`    <testcase name="Git exposed" classname="foobar.com">` + "\n" +
+				`      <failure message="[High] Do not deploy &lt;.git&gt;" type="High">URL: https://foobar.com/.git/config&#xA;Severity: High&#xA;Remediation: Do not deploy &lt;.git&gt;</failure>` + "\n" +
+				`    </testcase>` + "\n" +
+				`    <testcase name="Server header" classname="other.com">` + "\n" +
+				`      <failure message="[Low] hide it" type="Low">URL: https://other.com:8443/&#xA;Severity: Low&#xA;Remediation: hide it&#xA;Details: Server: Apache/2.4.1</failure>` + "\n" +
+				`    </testcase>` + "\n" +
+				`  </testsuite>` + "\n" +
+				`</testsuites>` + "\n",
+		},
+		"no findings": {
+			output: []core.Output{},
+			want: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<testsuites name="ChopChop" tests="0" failures="0">` + "\n" +
+				`  <testsuite name="ChopChop" tests="0" failures="0" errors="0"></testsuite>` + "\n" +
+				`</testsuites>` + "\n",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, _ := appfs.Create(filename)
+			_ = exportJUnit(f, tc.output)
+			contents, _ := appfs.ReadFile(filename)
+			got := string(contents)
+			if got != tc.want {
+				t.Errorf("want : %q, got : %q", tc.want, got)
+			}
+		})
+	}
+}
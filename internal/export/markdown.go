@@ -0,0 +1,85 @@
+package export
+
+import (
+	"fmt"
+	"gochopchop/core"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// markdownEscaper escapes the characters having a meaning in Markdown, the line breaks would end the table row
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\", "`", "\\`", "*", "\\*", "_", "\\_", "{", "\\{", "}", "\\}", "[", "\\[", "]", "\\]",
+	"<", "&lt;", ">", "&gt;", "(", "\\(", ")", "\\)", "#", "\\#", "+", "\\+", "!", "\\!", "|", "\\|",
+	"\r\n", " ", "\n", " ",
+)
+
+// ExportMarkdown exports the output as a Markdown report, to be attached to an issue or a pull request
+func ExportMarkdown(filename string, out []core.Output) error {
+	exportFilename := fmt.Sprintf("%s.md", filename)
+
+	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = exportMarkdown(f, out)
+	if err != nil {
+		return err
+	}
+	log.Info("Results were exported as markdown in: ", exportFilename)
+	return nil
+}
+
+// exportMarkdown writes a table of the findings for each severity, followed by the remediation of each check
+func exportMarkdown(file IFile, out []core.Output) error {
+	var b strings.Builder
+	b.WriteString("# ChopChop report\n")
+	for _, severity := range core.Severities() {
+		var findings []core.Output
+		for _, output := range out {
+			if output.Severity == severity {
+				findings = append(findings, output)
+			}
+		}
+		if len(findings) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n## %s (%d)\n\n", severity, len(findings))
+		b.WriteString("| URL | Endpoint | Check | Category |\n|---|---|---|---|\n")
+		for _, output := range findings {
+			name := output.Name
+			if output.Confidence != "" {
+				name = fmt.Sprintf("%s (%s confidence)", name, output.Confidence)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", escapeMarkdown(output.URL), escapeMarkdown(output.Endpoint), escapeMarkdown(name), escapeMarkdown(output.Category))
+		}
+
+		b.WriteString("\n### Remediation\n\n")
+		seen := make(map[string]bool)
+		for _, output := range findings {
+			if seen[output.Name] {
+				continue
+			}
+			seen[output.Name] = true
+			fmt.Fprintf(&b, "- **%s**: %s\n", escapeMarkdown(output.Name), escapeMarkdown(output.Remediation))
+			for _, reference := range output.References {
+				fmt.Fprintf(&b, "  - [%s](<%s>)\n", escapeMarkdown(reference), strings.NewReplacer("<", "%3C", ">", "%3E", "\n", "").Replace(reference))
+			}
+		}
+	}
+	if len(out) == 0 {
+		b.WriteString("\nNo vulnerabilities found.\n")
+	}
+
+	_, err := file.WriteString(b.String())
+	return err
+}
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
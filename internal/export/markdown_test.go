@@ -0,0 +1,44 @@
+package export
+
+import (
+	"gochopchop/core"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestExportMarkdown(t *testing.T) {
+	appfs := afero.Afero{Fs: afero.NewMemMapFs()}
+	filename := "formatmarkdown"
+
+	outputs := []core.Output{
+		{URL: "http://problems/", Endpoint: "/", Name: "Server_Header", Severity: "Low", Remediation: "Remove the *Server* header"},
+		{URL: "http://problems/.git/config", Endpoint: "/.git/config", Name: "Git exposed", Severity: "High", Remediation: "Do not deploy .git | folders", Category: "Information Disclosure", References: []string{"https://owasp.org/a_b"}},
+		{URL: "http://other/.git/config", Endpoint: "/.git/config", Name: "Git exposed", Severity: "High", Remediation: "Do not deploy .git | folders", Category: "Information Disclosure", References: []string{"https://owasp.org/a_b"}},
+	}
+	var tests = map[string]struct {
+		output []core.Output
+		want   string
+	}{
+		"grouped by severity": {output: outputs, want: "# ChopChop report\n" +
+			"\n## High (2)\n\n| URL | Endpoint | Check | Category |\n|---|---|---|---|\n" +
+			"| http://problems/.git/config | /.git/config | Git exposed | Information Disclosure |\n" +
+			"| http://other/.git/config | /.git/config | Git exposed | Information Disclosure |\n" +
+			"\n### Remediation\n\n- **Git exposed**: Do not deploy .git \\| folders\n  - [https://owasp.org/a\\_b](<https://owasp.org/a_b>)\n" +
+			"\n## Low (1)\n\n| URL | Endpoint | Check | Category |\n|---|---|---|---|\n" +
+			"| http://problems/ | / | Server\\_Header |  |\n" +
+			"\n### Remediation\n\n- **Server\\_Header**: Remove the \\*Server\\* header\n"},
+		"no findings": {output: nil, want: "# ChopChop report\n\nNo vulnerabilities found.\n"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, _ := appfs.Create(filename)
+			_ = exportMarkdown(f, tc.output)
+			contents, _ := appfs.ReadFile(filename)
+			got := string(contents)
+			if got != tc.want {
+				t.Errorf("want : %q, got : %q", tc.want, got)
+			}
+		})
+	}
+}
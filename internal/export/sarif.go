@@ -0,0 +1,199 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"gochopchop/core"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// sarifLog is the Static Analysis Results Interchange Format 2.1.0, as uploaded to GitHub code scanning
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string              `json:"id"`
+	Name             string              `json:"name"`
+	ShortDescription sarifMessage        `json:"shortDescription"`
+	FullDescription  sarifMessage        `json:"fullDescription"`
+	Help             sarifMessage        `json:"help"`
+	HelpURI          string              `json:"helpUri,omitempty"`
+	Properties       sarifRuleProperties `json:"properties"`
+}
+
+type sarifRuleProperties struct {
+	Tags []string `json:"tags,omitempty"`
+	// SecuritySeverity is the score GitHub derives the security severity of the alerts from
+	SecuritySeverity string `json:"security-severity"`
+	// Precision is how often the rule is right, from the confidence of the check
+	Precision string `json:"precision,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+// sarifRegion locates the evidence of the finding in the response body
+type sarifRegion struct {
+	ByteOffset int          `json:"byteOffset"`
+	Snippet    sarifMessage `json:"snippet"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// ExportSARIF exports the output in the SARIF format, to be uploaded to GitHub code scanning
+func ExportSARIF(filename string, out []core.Output) error {
+	exportFilename := fmt.Sprintf("%s.sarif", filename)
+
+	f, err := os.OpenFile(exportFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = exportSARIF(f, out)
+	if err != nil {
+		return err
+	}
+	log.Info("Results were exported as SARIF in: ", exportFilename)
+	return nil
+}
+
+// exportSARIF writes a rule per plugin, in the order they are first found, and a result per finding
+func exportSARIF(file IFile, out []core.Output) error {
+	driver := sarifDriver{Name: "ChopChop", InformationURI: "https://github.com/michelin/ChopChop", Rules: []sarifRule{}}
+	results := make([]sarifResult, 0, len(out))
+	ruleIndexes := make(map[string]int)
+	for _, output := range out {
+		index, ok := ruleIndexes[output.Name]
+		if !ok {
+			index = len(driver.Rules)
+			ruleIndexes[output.Name] = index
+			driver.Rules = append(driver.Rules, sarifRuleOf(output))
+		}
+		message := fmt.Sprintf("[%s] %s found on %s (%s)", output.Severity, output.Name, output.Column("domain"), output.URL)
+		if output.Details != "" {
+			message = fmt.Sprintf("%s: %s", message, output.Details)
+		}
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: output.URL}}
+		if output.Evidence != nil {
+			location.Region = &sarifRegion{ByteOffset: output.Evidence.Offset, Snippet: sarifMessage{Text: output.Evidence.Snippet}}
+		}
+		results = append(results, sarifResult{
+			RuleID:    output.Name,
+			RuleIndex: index,
+			Level:     sarifLevel(output.Severity),
+			Message:   sarifMessage{Text: message},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	report := sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	jsonbytes, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(string(jsonbytes)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func sarifRuleOf(output core.Output) sarifRule {
+	description := output.Description
+	if description == "" {
+		description = output.Name
+	}
+	rule := sarifRule{
+		ID:               output.Name,
+		Name:             output.Name,
+		ShortDescription: sarifMessage{Text: output.Name},
+		FullDescription:  sarifMessage{Text: description},
+		Help:             sarifMessage{Text: output.Remediation},
+		Properties:       sarifRuleProperties{SecuritySeverity: sarifSecuritySeverity(output.Severity)},
+	}
+	if len(output.References) > 0 {
+		rule.HelpURI = output.References[0]
+	}
+	if output.Confidence != "" {
+		rule.Properties.Precision = strings.ToLower(output.Confidence)
+	}
+	if output.Category != "" {
+		rule.Properties.Tags = []string{output.Category}
+	}
+	return rule
+}
+
+// sarifLevel maps a severity to the SARIF levels (error, warning, note)
+func sarifLevel(severity string) string {
+	switch severity {
+	case "Critical", "High":
+		return "error"
+	case "Medium":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity maps a severity to the CVSS-like score GitHub reads to rank the alerts
+func sarifSecuritySeverity(severity string) string {
+	switch severity {
+	case "Critical":
+		return "9.5"
+	case "High":
+		return "8.0"
+	case "Medium":
+		return "5.5"
+	case "Low":
+		return "2.0"
+	default:
+		return "0.0"
+	}
+}
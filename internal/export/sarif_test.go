@@ -0,0 +1,63 @@
+package export
+
+import (
+	"gochopchop/core"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestExportSARIF(t *testing.T) {
+	appfs := afero.Afero{Fs: afero.NewMemMapFs()}
+	filename := "formatsarif"
+
+	var tests = map[string]struct {
+		output []core.Output
+		want   string
+	}{
+		"rule per plugin": {
+			output: []core.Output{
+				{URL: "https://foobar.com/.git/config", Name: "Git exposed", Severity: "High", Remediation: "uninstall", Category: "Information Disclosure"},
+				{URL: "https://other.com/.git/config", Name: "Git exposed", Severity: "High", Remediation: "uninstall", Category: "Information Disclosure"},
+				{URL: "https://foobar.com/", Name: "Server header", Severity: "Low", Remediation: "hide it", Description: "Server header is set", References: []string{"https://owasp.org"}},
+			},
+			want: `{"$schema":"https://json.schemastore.org/sarif-2.1.0.json","version":"2.1.0","runs":[{"tool":{"driver":{"name":"ChopChop","informationUri":"https://github.com/michelin/ChopChop","rules":[` +
+				`{"id":"Git exposed","name":"Git exposed","shortDescription":{"text":"Git exposed"},"fullDescription":{"text":"Git exposed"},"help":{"text":"uninstall"},"properties":{"tags":["Information Disclosure"],"security-severity":"8.0"}},` +
+				`{"id":"Server header","name":"Server header","shortDescription":{"text":"Server header"},"fullDescription":{"text":"Server header is set"},"help":{"text":"hide it"},"helpUri":"https://owasp.org","properties":{"security-severity":"2.0"}}]}},"results":[` +
+				`{"ruleId":"Git exposed","ruleIndex":0,"level":"error","message":{"text":"[High] Git exposed found on foobar.com (https://foobar.com/.git/config)"},"locations":[{"physicalLocation":{"artifactLocation":{"uri":"https://foobar.com/.git/config"}}}]},` +
+				`{"ruleId":"Git exposed","ruleIndex":0,"level":"error","message":{"text":"[High] Git exposed found on other.com (https://other.com/.git/config)"},"locations":[{"physicalLocation":{"artifactLocation":{"uri":"https://other.com/.git/config"}}}]},` +
+				`{"ruleId":"Server header","ruleIndex":1,"level":"note","message":{"text":"[Low] Server header found on foobar.com (https://foobar.com/)"},"locations":[{"physicalLocation":{"artifactLocation":{"uri":"https://foobar.com/"}}}]}]}]}`,
+		},
+		"confidence as precision": {
+			output: []core.Output{
+				{URL: "https://foobar.com/", Name: "Server header", Severity: "Low", Remediation: "hide it", Confidence: "Medium"},
+			},
+			want: `{"$schema":"https://json.schemastore.org/sarif-2.1.0.json","version":"2.1.0","runs":[{"tool":{"driver":{"name":"ChopChop","informationUri":"https://github.com/michelin/ChopChop","rules":[` +
+				`{"id":"Server header","name":"Server header","shortDescription":{"text":"Server header"},"fullDescription":{"text":"Server header"},"help":{"text":"hide it"},"properties":{"security-severity":"2.0","precision":"medium"}}]}},"results":[` +
+				`{"ruleId":"Server header","ruleIndex":0,"level":"note","message":{"text":"[Low] Server header found on foobar.com (https://foobar.com/)"},"locations":[{"physicalLocation":{"artifactLocation":{"uri":"https://foobar.com/"}}}]}]}]}`,
+		},
+		"evidence as region": {
+			output: []core.Output{
+				{URL: "https://foobar.com/.git/config", Name: "Git exposed", Severity: "High", Remediation: "uninstall", Evidence: &core.Evidence{Match: "[core]", Offset: 3, Snippet: "\n\n\n[core]"}},
+			},
+			want: `{"$schema":"https://json.schemastore.org/sarif-2.1.0.json","version":"2.1.0","runs":[{"tool":{"driver":{"name":"ChopChop","informationUri":"https://github.com/michelin/ChopChop","rules":[` +
+				`{"id":"Git exposed","name":"Git exposed","shortDescription":{"text":"Git exposed"},"fullDescription":{"text":"Git exposed"},"help":{"text":"uninstall"},"properties":{"security-severity":"8.0"}}]}},"results":[` +
+				`{"ruleId":"Git exposed","ruleIndex":0,"level":"error","message":{"text":"[High] Git exposed found on foobar.com (https://foobar.com/.git/config)"},"locations":[{"physicalLocation":{"artifactLocation":{"uri":"https://foobar.com/.git/config"},"region":{"byteOffset":3,"snippet":{"text":"\n\n\n[core]"}}}}]}]}]}`,
+		},
+		"no findings": {
+			output: []core.Output{},
+			want:   `{"$schema":"https://json.schemastore.org/sarif-2.1.0.json","version":"2.1.0","runs":[{"tool":{"driver":{"name":"ChopChop","informationUri":"https://github.com/michelin/ChopChop","rules":[]}},"results":[]}]}`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, _ := appfs.Create(filename)
+			_ = exportSARIF(f, tc.output)
+			contents, _ := appfs.ReadFile(filename)
+			got := string(contents)
+			if got != tc.want {
+				t.Errorf("want : %q, got : %q", tc.want, got)
+			}
+		})
+	}
+}
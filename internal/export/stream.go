@@ -0,0 +1,202 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gochopchop/core"
+	"io"
+	"os"
+	"sync"
+)
+
+// errClosed is returned by the writes to a closed export file
+var errClosed = errors.New("the export file is closed")
+
+// NDJSONWriter streams the findings as newline-delimited JSON, one object per line.
+// It is safe for concurrent use: each finding is written in a single call.
+type NDJSONWriter struct {
+	mux sync.Mutex
+	w   io.Writer
+}
+
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{w: w}
+}
+
+func (n *NDJSONWriter) Write(output core.Output) error {
+	jsonbytes, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	_, err = n.w.Write(append(jsonbytes, '\n'))
+	return err
+}
+
+// MultiWriter forwards each finding to several writers
+type MultiWriter []core.FindingWriter
+
+func (m MultiWriter) Write(output core.Output) error {
+	var firstErr error
+	for _, w := range m {
+		if err := w.Write(output); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// FileWriter streams the findings to an export file during the scan.
+// The file only appears, complete, once the writer is closed.
+type FileWriter interface {
+	core.FindingWriter
+	Close() error
+	Filename() string
+}
+
+// CSVFileWriter streams the findings to a CSV file
+type CSVFileWriter struct {
+	mux     sync.Mutex
+	file    *atomicFile
+	columns []string
+	closed  bool
+}
+
+// NewCSVFileWriter creates the <filename>.csv export, with the selected columns if any
+func NewCSVFileWriter(filename string, columns []string) (*CSVFileWriter, error) {
+	f, err := createAtomic(fmt.Sprintf("%s.csv", filename))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteString(csvHeader(columns)); err != nil {
+		f.Abort()
+		return nil, err
+	}
+	return &CSVFileWriter{file: f, columns: columns}, nil
+}
+
+func (c *CSVFileWriter) Write(output core.Output) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.closed {
+		return errClosed
+	}
+	_, err := c.file.WriteString(csvLine(output, c.columns))
+	return err
+}
+
+// Close writes the file, it can be called several times
+func (c *CSVFileWriter) Close() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.file.Commit()
+}
+
+func (c *CSVFileWriter) Filename() string {
+	return c.file.filename
+}
+
+// NDJSONFileWriter writes the findings to a newline-delimited JSON file as they are found.
+// Unlike the other file writers, the file is written in place, so it can be followed during the scan (eg. tail -f).
+type NDJSONFileWriter struct {
+	*NDJSONWriter
+	file   *os.File
+	closed bool
+}
+
+// NewNDJSONFileWriter creates the <filename>.ndjson export
+func NewNDJSONFileWriter(filename string) (*NDJSONFileWriter, error) {
+	f, err := os.OpenFile(fmt.Sprintf("%s.ndjson", filename), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &NDJSONFileWriter{NDJSONWriter: NewNDJSONWriter(f), file: f}, nil
+}
+
+// Close closes the file, it can be called several times
+func (n *NDJSONFileWriter) Close() error {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	if n.closed {
+		return nil
+	}
+	n.closed = true
+	return n.file.Close()
+}
+
+func (n *NDJSONFileWriter) Filename() string {
+	return n.file.Name()
+}
+
+// JSONFileWriter streams the findings to a JSON file, as the report written by ExportJSON
+type JSONFileWriter struct {
+	mux    sync.Mutex
+	file   *atomicFile
+	count  int
+	closed bool
+	// metadata of the run, only known once it is over
+	metadata func() core.Metadata
+}
+
+// NewJSONFileWriter creates the <filename>.json export, the metadata is written when the writer is closed
+func NewJSONFileWriter(filename string, metadata func() core.Metadata) (*JSONFileWriter, error) {
+	f, err := createAtomic(fmt.Sprintf("%s.json", filename))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteString(`{"findings":[`); err != nil {
+		f.Abort()
+		return nil, err
+	}
+	return &JSONFileWriter{file: f, metadata: metadata}, nil
+}
+
+func (j *JSONFileWriter) Write(output core.Output) error {
+	jsonbytes, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	if j.closed {
+		return errClosed
+	}
+	if j.count > 0 {
+		jsonbytes = append([]byte(","), jsonbytes...)
+	}
+	if _, err := j.file.Write(jsonbytes); err != nil {
+		return err
+	}
+	j.count++
+	return nil
+}
+
+// Close writes the metadata and the file, it can be called several times
+func (j *JSONFileWriter) Close() error {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	if j.closed {
+		return nil
+	}
+	j.closed = true
+	jsonbytes, err := json.Marshal(j.metadata())
+	if err != nil {
+		j.file.Abort()
+		return err
+	}
+	if _, err := j.file.WriteString(`],"metadata":` + string(jsonbytes) + "}"); err != nil {
+		j.file.Abort()
+		return err
+	}
+	return j.file.Commit()
+}
+
+func (j *JSONFileWriter) Filename() string {
+	return j.file.filename
+}
@@ -0,0 +1,158 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"gochopchop/core"
+	"gochopchop/mock"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNDJSONWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	other := new(bytes.Buffer)
+	writer := MultiWriter{NewNDJSONWriter(buf), NewNDJSONWriter(other)}
+
+	wg := new(sync.WaitGroup)
+	for _, output := range mock.FakeOutput {
+		wg.Add(1)
+		go func(output core.Output) {
+			defer wg.Done()
+			if err := writer.Write(output); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(output)
+	}
+	wg.Wait()
+
+	if buf.Len() != other.Len() {
+		t.Errorf("expected the same findings in every sink")
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(mock.FakeOutput) {
+		t.Fatalf("expected: %d lines, got: %d", len(mock.FakeOutput), len(lines))
+	}
+	for _, line := range lines {
+		var output core.Output
+		if err := json.Unmarshal([]byte(line), &output); err != nil {
+			t.Errorf("invalid line %q: %v", line, err)
+		}
+	}
+}
+
+func TestFileWriters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chopchop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "streamed")
+
+	jsonWriter, err := NewJSONFileWriter(filename, func() core.Metadata { return mock.FakeMetadata })
+	if err != nil {
+		t.Fatal(err)
+	}
+	csvWriter, err := NewCSVFileWriter(filename, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := MultiWriter{jsonWriter, csvWriter}
+	for _, output := range mock.FakeOutput {
+		if err := writer.Write(output); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := os.Stat(filename + ".json"); !os.IsNotExist(err) {
+		t.Errorf("expected the export to only appear once closed")
+	}
+	if err := jsonWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := csvWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// an interrupted scan may close the writers again, the files are left as they are
+	if err := writer.Write(mock.FakeOutput[0]); err == nil {
+		t.Errorf("expected an error writing to a closed export file")
+	}
+	if err := jsonWriter.Close(); err != nil {
+		t.Errorf("expected: no error closing again, got: %v", err)
+	}
+	if err := csvWriter.Close(); err != nil {
+		t.Errorf("expected: no error closing again, got: %v", err)
+	}
+
+	var tests = map[string]struct {
+		filename string
+		want     string
+	}{
+		"json": {filename: jsonWriter.Filename(), want: mock.FakeReportAsJSON},
+		"csv":  {filename: csvWriter.Filename(), want: mock.FakeOutputAsCSV},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			contents, _ := ioutil.ReadFile(tc.filename)
+			got := string(contents)
+			if got != tc.want {
+				t.Errorf("want : %q, got : %q", tc.want, got)
+			}
+		})
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 2 {
+		t.Errorf("expected: 2 files, got: %v", len(files))
+	}
+}
+
+func TestNDJSONFileWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chopchop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writer, err := NewNDJSONFileWriter(filepath.Join(dir, "streamed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 50; i++ {
+		for _, output := range mock.FakeOutput {
+			wg.Add(1)
+			go func(output core.Output) {
+				defer wg.Done()
+				if err := writer.Write(output); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}(output)
+		}
+	}
+	wg.Wait()
+
+	// the findings are in the file before it is closed, so it can be followed during the scan
+	contents, err := ioutil.ReadFile(writer.Filename())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+	if len(lines) != 50*len(mock.FakeOutput) {
+		t.Fatalf("expected: %d lines, got: %d", 50*len(mock.FakeOutput), len(lines))
+	}
+	for _, line := range lines {
+		var output core.Output
+		if err := json.Unmarshal([]byte(line), &output); err != nil {
+			t.Errorf("invalid line %q: %v", line, err)
+		}
+	}
+	if filepath.Ext(writer.Filename()) != ".ndjson" {
+		t.Errorf("expected: %v, got: %v", ".ndjson", filepath.Ext(writer.Filename()))
+	}
+}
@@ -0,0 +1,30 @@
+package export
+
+import log "github.com/sirupsen/logrus"
+
+// ExportRequestedURLs writes the requested urls to the file, one per line.
+// The file is written atomically: it is either absent or complete, even if the scan is killed meanwhile.
+func ExportRequestedURLs(filename string, urls []string) error {
+	f, err := createAtomic(filename)
+	if err != nil {
+		return err
+	}
+	if err := exportURLs(f, urls); err != nil {
+		f.Abort()
+		return err
+	}
+	if err := f.Commit(); err != nil {
+		return err
+	}
+	log.Info("Requested urls were exported in: ", filename)
+	return nil
+}
+
+func exportURLs(file IFile, urls []string) error {
+	for _, url := range urls {
+		if _, err := file.WriteString(url + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package export
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportRequestedURLs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chopchop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "requested.txt")
+
+	urls := []string{"http://problems/", "http://problems/.git/config"}
+	if err := ExportRequestedURLs(filename, urls); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	contents, _ := ioutil.ReadFile(filename)
+	got := string(contents)
+	want := "http://problems/\nhttp://problems/.git/config\n"
+	if got != want {
+		t.Errorf("want : %q, got : %q", want, got)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("expected: 1 file, got: %v", len(files))
+	}
+}
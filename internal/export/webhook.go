@@ -0,0 +1,98 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gochopchop/core"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WebhookTimeout bounds the notification, so a slow endpoint never holds the end of the scan
+const WebhookTimeout = 10 * time.Second
+
+// webhookPayload is the summary posted to the webhook.
+// Text is the message displayed by Slack incoming webhooks, the other fields are for generic pipelines.
+type webhookPayload struct {
+	Text       string         `json:"text"`
+	Findings   int            `json:"findings"`
+	BySeverity map[string]int `json:"bySeverity"`
+	Hits       []webhookHit   `json:"hits"`
+}
+
+type webhookHit struct {
+	URL      string `json:"url"`
+	Name     string `json:"checkName"`
+	Severity string `json:"severity"`
+	Status   string `json:"status,omitempty"`
+}
+
+// PostWebhook posts a JSON summary of the findings (count by severity and hits) to the webhook url.
+// The hits are left empty when the findings were not kept in memory.
+// The url is never part of the errors, as webhook urls usually embed their secret.
+func PostWebhook(ctx context.Context, webhookURL string, summary *core.Summary, out []core.Output) error {
+	payload := webhookPayload{
+		Text:       webhookText(summary),
+		Findings:   summary.Total,
+		BySeverity: make(map[string]int),
+		Hits:       make([]webhookHit, 0, len(out)),
+	}
+	for _, severity := range core.Severities() {
+		payload.BySeverity[severity] = summary.BySeverity[severity]
+	}
+	for _, output := range out {
+		payload.Hits = append(payload.Hits, webhookHit{URL: output.URL, Name: output.Name, Severity: output.Severity, Status: output.Status})
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, WebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook request")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", core.DefaultUserAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook notification failed: %v", unwrapURLError(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook notification failed with status %s", resp.Status)
+	}
+	return nil
+}
+
+// webhookText is a one line summary, eg. "ChopChop found 3 finding(s): 1 High, 2 Low"
+func webhookText(summary *core.Summary) string {
+	if summary.Total == 0 {
+		return "ChopChop found no vulnerabilities"
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "ChopChop found %d finding(s):", summary.Total)
+	sep := " "
+	for _, severity := range core.Severities() {
+		if count := summary.BySeverity[severity]; count > 0 {
+			fmt.Fprintf(&b, "%s%d %s", sep, count, severity)
+			sep = ", "
+		}
+	}
+	return b.String()
+}
+
+// unwrapURLError drops the url of the errors of the client
+func unwrapURLError(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"gochopchop/core"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPostWebhook(t *testing.T) {
+	var tests = map[string]struct {
+		status int
+		output []core.Output
+		text   string
+		nilErr bool
+	}{
+		"findings posted": {
+			status: http.StatusOK,
+			output: []core.Output{
+				{URL: "https://foobar.com/.git/config", Name: "Git exposed", Severity: "High"},
+				{URL: "https://foobar.com/", Name: "Server header", Severity: "Low"},
+				{URL: "https://other.com/", Name: "Server header", Severity: "Low"},
+			},
+			text:   "ChopChop found 3 finding(s): 1 High, 2 Low",
+			nilErr: true,
+		},
+		"no findings posted": {status: http.StatusNoContent, output: []core.Output{}, text: "ChopChop found no vulnerabilities", nilErr: true},
+		"webhook error":      {status: http.StatusForbidden, output: []core.Output{}, text: "ChopChop found no vulnerabilities", nilErr: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var payload webhookPayload
+			var contentType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+				body, _ := ioutil.ReadAll(r.Body)
+				json.Unmarshal(body, &payload)
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			err := PostWebhook(context.Background(), server.URL+"/T000/B000/secret", core.Summarize(tc.output), tc.output)
+			if tc.nilErr && err != nil {
+				t.Fatalf("expected a nil error, got : %v", err)
+			}
+			if !tc.nilErr && (err == nil || strings.Contains(err.Error(), "secret")) {
+				t.Errorf("expected: an error without the webhook url, got: %v", err)
+			}
+			if contentType != "application/json" {
+				t.Errorf("expected: %v, got: %v", "application/json", contentType)
+			}
+			if payload.Text != tc.text {
+				t.Errorf("expected: %v, got: %v", tc.text, payload.Text)
+			}
+			if payload.Findings != len(tc.output) || len(payload.Hits) != len(tc.output) {
+				t.Errorf("expected: %v findings, got: %v", len(tc.output), payload)
+			}
+			for _, severity := range core.Severities() {
+				if _, ok := payload.BySeverity[severity]; !ok {
+					t.Errorf("expected: a count for %v, got: %v", severity, payload.BySeverity)
+				}
+			}
+		})
+	}
+}
+
+func TestPostWebhookUnreachable(t *testing.T) {
+	err := PostWebhook(context.Background(), "http://127.0.0.1:1/secret", core.NewSummary(), nil)
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected: an error without the webhook url, got: %v", err)
+	}
+}
@@ -1,44 +1,169 @@
 package formatting
 
 import (
+	"bufio"
 	"fmt"
 	"gochopchop/core"
 	"io"
+	"sort"
 
 	"github.com/jedib0t/go-pretty/table"
 )
 
-// PrintTable will render the data as a nice table
-func PrintTable(outputs []core.Output, mirror io.Writer) {
+// PrintTable will render the data as a nice table, with the selected columns if any
+// Findings are grouped by category (when set) then sorted by severity.
+// Their status is shown when they were compared to a previous run, and a warning when their host seems to block every request.
+func PrintTable(outputs []core.Output, mirror io.Writer, columns []string) {
+	sorted := sortOutputs(outputs)
+	if len(columns) > 0 {
+		printTableColumns(sorted, mirror, columns)
+		return
+	}
+	withCategory, withStatus, withBlocked := false, false, false
+	for _, output := range sorted {
+		if output.Category != "" {
+			withCategory = true
+		}
+		if output.Status != "" {
+			withStatus = true
+		}
+		if output.PossiblyBlocked {
+			withBlocked = true
+		}
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(mirror)
+	header := table.Row{"URL", "Endpoint", "Severity", "Plugin", "Remediation"}
+	if withCategory {
+		header = append(table.Row{"Category"}, header...)
+	}
+	if withStatus {
+		header = append(header, "Status")
+	}
+	if withBlocked {
+		header = append(header, "Warning")
+	}
+	t.AppendHeader(header)
+	for _, output := range sorted {
+		row := table.Row{
+			output.URL,
+			output.Endpoint,
+			colorSeverity(output.Severity),
+			output.Name,
+			output.Remediation,
+		}
+		if withCategory {
+			row = append(table.Row{output.Category}, row...)
+		}
+		if withStatus {
+			row = append(row, output.Status)
+		}
+		if withBlocked {
+			warning := ""
+			if output.PossiblyBlocked {
+				warning = "possibly blocked"
+			}
+			row = append(row, warning)
+		}
+		t.AppendRow(row)
+	}
+	t.Render()
+}
+
+func printTableColumns(outputs []core.Output, mirror io.Writer, columns []string) {
+	t := table.NewWriter()
+	t.SetOutputMirror(mirror)
+	header := table.Row{}
+	for _, column := range columns {
+		header = append(header, column)
+	}
+	t.AppendHeader(header)
+	for _, output := range outputs {
+		row := table.Row{}
+		for _, column := range columns {
+			if column == "severity" {
+				row = append(row, colorSeverity(output.Severity))
+			} else {
+				row = append(row, output.Column(column))
+			}
+		}
+		t.AppendRow(row)
+	}
+	t.Render()
+}
+
+func colorSeverity(severity string) string {
 	colorReset := "\033[0m"
+	colorMagenta := "\033[35m"
 	colorRed := "\033[31m"
 	colorGreen := "\033[32m"
 	colorYellow := "\033[33m"
 	colorCyan := "\033[36m"
-	t := table.NewWriter()
-	t.SetOutputMirror(mirror)
-	t.AppendHeader(table.Row{"URL", "Endpoint", "Severity", "Plugin", "Remediation"})
-	for _, output := range outputs {
-		severity := ""
-		if output.Severity == "High" {
-			severity = fmt.Sprint(string(colorRed), "High", string(colorReset))
-		} else if output.Severity == "Medium" {
-			severity = fmt.Sprint(string(colorYellow), "Medium", string(colorReset))
-		} else if output.Severity == "Low" {
-			severity = fmt.Sprint(string(colorGreen), "Low", string(colorReset))
+
+	if severity == "Critical" {
+		return fmt.Sprint(string(colorMagenta), "Critical", string(colorReset))
+	} else if severity == "High" {
+		return fmt.Sprint(string(colorRed), "High", string(colorReset))
+	} else if severity == "Medium" {
+		return fmt.Sprint(string(colorYellow), "Medium", string(colorReset))
+	} else if severity == "Low" {
+		return fmt.Sprint(string(colorGreen), "Low", string(colorReset))
+	}
+	return fmt.Sprint(string(colorCyan), "Informational", string(colorReset))
+}
+
+// PrintLines will render the data as one compact line per finding.
+// It is used instead of the table when there are too many findings to render a table.
+func PrintLines(outputs []core.Output, mirror io.Writer) {
+	w := bufio.NewWriter(mirror)
+	defer w.Flush()
+	for _, output := range sortOutputs(outputs) {
+		if output.Status != "" {
+			fmt.Fprintf(w, "[%s] %s - %s (%s)\n", output.Severity, output.URL, output.Name, output.Status)
 		} else {
-			severity = fmt.Sprint(string(colorCyan), "Informational", string(colorReset))
+			fmt.Fprintf(w, "[%s] %s - %s\n", output.Severity, output.URL, output.Name)
 		}
-		t.AppendRow([]interface{}{
-			output.URL,
-			output.Endpoint,
-			severity,
-			output.Name,
-			output.Remediation,
-		})
 	}
-	t.SortBy([]table.SortBy{
-		{Name: "Severity", Mode: table.Asc},
+}
+
+// sortOutputs returns a copy of the outputs grouped by category then sorted by severity
+func sortOutputs(outputs []core.Output) []core.Output {
+	sorted := make([]core.Output, len(outputs))
+	copy(sorted, outputs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Category != sorted[j].Category {
+			return sorted[i].Category < sorted[j].Category
+		}
+		return core.SeverityRank(sorted[i].Severity) < core.SeverityRank(sorted[j].Severity)
 	})
+	return sorted
+}
+
+// PrintRiskTable will render the risk score of each host, from the riskiest to the safest
+func PrintRiskTable(risks []core.HostRisk, mirror io.Writer) {
+	t := table.NewWriter()
+	t.SetOutputMirror(mirror)
+	t.AppendHeader(table.Row{"Host", "Findings", "Risk Score"})
+	for _, risk := range risks {
+		t.AppendRow(table.Row{risk.Host, risk.Findings, risk.Score})
+	}
+	t.Render()
+}
+
+// PrintSummary will render the number of findings of each severity, and the number of requests sent when it is known
+func PrintSummary(summary *core.Summary, mirror io.Writer) {
+	t := table.NewWriter()
+	t.SetOutputMirror(mirror)
+	t.AppendHeader(table.Row{"Severity", "Findings"})
+	for _, severity := range core.Severities() {
+		if count := summary.BySeverity[severity]; count > 0 {
+			t.AppendRow(table.Row{colorSeverity(severity), count})
+		}
+	}
+	t.AppendFooter(table.Row{"Total", summary.Total})
+	if summary.Requests > 0 {
+		t.AppendFooter(table.Row{"Requests sent", summary.Requests})
+	}
 	t.Render()
 }
@@ -2,18 +2,53 @@ package formatting_test
 
 import (
 	"bytes"
+	"gochopchop/core"
 	"gochopchop/internal/formatting"
 	"gochopchop/mock"
+	"strings"
 	"testing"
 )
 
 func TestFormatOutputTable(t *testing.T) {
 	mirror := new(bytes.Buffer)
 	output := mock.FakeOutput
-	formatting.PrintTable(output, mirror)
+	formatting.PrintTable(output, mirror, nil)
 	got := mirror.String()
 	want := mock.FakeOutputAsTable
 	if got != want {
 		t.Errorf("want : %q, got : %q", want, got)
 	}
 }
+
+func TestFormatOutputLines(t *testing.T) {
+	mirror := new(bytes.Buffer)
+	formatting.PrintLines(mock.FakeOutput, mirror)
+	got := mirror.String()
+	want := mock.FakeOutputAsLines
+	if got != want {
+		t.Errorf("want : %q, got : %q", want, got)
+	}
+}
+
+func TestFormatOutputTableColumns(t *testing.T) {
+	mirror := new(bytes.Buffer)
+	formatting.PrintTable(mock.FakeOutput[:1], mirror, []string{"plugin", "domain"})
+	got := mirror.String()
+	want := "+---------------+----------+\n| PLUGIN        | DOMAIN   |\n+---------------+----------+\n| StatusCode200 | problems |\n+---------------+----------+\n"
+	if got != want {
+		t.Errorf("want : %q, got : %q", want, got)
+	}
+}
+
+func TestFormatSummary(t *testing.T) {
+	mirror := new(bytes.Buffer)
+	summary := core.Summarize(mock.FakeOutput)
+	summary.Requests = 12
+	formatting.PrintSummary(summary, mirror)
+	got := mirror.String()
+	for _, want := range []string{"TOTAL", "REQUESTS SENT", "12"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want : %q in %q", want, got)
+		}
+	}
+}
@@ -1,9 +1,40 @@
 package internal
 
-import "net/http"
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// HTTPRequest describes the request sent for a plugin
+type HTTPRequest struct {
+	URL    string
+	Method string
+	Header http.Header
+	Body   string
+	// Timeout overrides the timeout of the fetcher when set
+	Timeout time.Duration
+	// Insecure overrides the TLS verification of the fetcher when set
+	Insecure *bool
+	// MaxRedirects overrides the redirects followed by the fetcher when set
+	MaxRedirects int
+}
 
 type HTTPResponse struct {
+	// URL of the response, after the redirects
+	URL        string
 	StatusCode int
 	Body       string
 	Header     http.Header
+	// Duration is the time the request took, from sending it to reading the whole body
+	Duration time.Duration
+	// BaselineSize is the body size of the baseline response of the host, nil when it is not computed
+	BaselineSize *int
+	// TLS is the state of the HTTPS connection, nil over plain http
+	TLS *tls.ConnectionState
+	// Proto is the protocol of the response, eg. HTTP/1.1 or HTTP/2.0
+	Proto string
+	// InitialStatusCode is the status code of the first response when the redirects were followed, eg. 301.
+	// It is 0 when there was no redirect, the status code being both the initial and the final one.
+	InitialStatusCode int
 }
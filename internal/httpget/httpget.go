@@ -1,15 +1,27 @@
 package httpget
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"crypto/tls"
+	"fmt"
+	"gochopchop/core"
 	"gochopchop/internal"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
+
+	log "github.com/sirupsen/logrus"
 )
 
 type IHTTPClient interface {
-	Get(url string) (*http.Response, error)
+	Do(req *http.Request) (*http.Response, error)
 }
 
 type HTTPClient struct {
@@ -19,58 +31,263 @@ type HTTPClient struct {
 
 type Fetcher struct {
 	Netclient IHTTPClient
+	// Authorization is sent with the requests not setting their own Authorization header
+	Authorization string
+	// UserAgent is sent with the requests not setting their own User-Agent header
+	UserAgent string
+	// HTTPVersion 2 makes the responses in another protocol an error
+	HTTPVersion string
+	// overrides are the clients of the requests overriding the timeout or the TLS verification
+	overrides *clientCache
 }
 
-func NewFetcher(insecure bool, timeout int) *Fetcher {
-	tr := &http.Transport{}
-	if insecure {
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-	var netClient = &http.Client{
-		Transport: tr,
-		Timeout:   time.Second * time.Duration(timeout),
-	}
+type clientKey struct {
+	timeout      time.Duration
+	insecure     bool
+	maxRedirects int
+}
+
+type clientCache struct {
+	mux     sync.Mutex
+	config  core.HTTPConfig
+	clients map[clientKey]IHTTPClient
+	build   func(config core.HTTPConfig) *http.Client
+}
+
+func NewFetcher(config core.HTTPConfig) *Fetcher {
+	return newFetcher(config, newClient)
+}
+
+func NewNoRedirectFetcher(config core.HTTPConfig) *Fetcher {
+	return newFetcher(config, newNoRedirectClient)
+}
+
+func newFetcher(config core.HTTPConfig, build func(config core.HTTPConfig) *http.Client) *Fetcher {
 	return &Fetcher{
-		Netclient: netClient,
+		Netclient:     build(config),
+		Authorization: config.Authorization,
+		UserAgent:     config.UserAgent,
+		HTTPVersion:   config.HTTPVersion,
+		overrides:     &clientCache{config: config, clients: make(map[clientKey]IHTTPClient), build: build},
 	}
 }
 
-func NewNoRedirectFetcher(insecure bool, timeout int) *Fetcher {
-	tr := &http.Transport{}
-	if insecure {
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+// DefaultMaxRedirects is the number of redirects followed when the configuration doesn't set it, as net/http does
+const DefaultMaxRedirects = 10
+
+func newClient(config core.HTTPConfig) *http.Client {
+	maxRedirects := config.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
 	}
-	var netClient = &http.Client{
-		Transport: tr,
-		Timeout:   time.Second * time.Duration(timeout),
+	return &http.Client{
+		Transport: newTransport(config),
+		Timeout:   time.Second * time.Duration(config.Timeout),
+		// a redirect loop is an error rather than a hang
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
 		},
 	}
-	return &Fetcher{
-		Netclient: netClient,
+}
+
+func newNoRedirectClient(config core.HTTPConfig) *http.Client {
+	client := newClient(config)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return client
+}
+
+// client returns the client of the request, built once per timeout and TLS verification overrides
+func (s Fetcher) client(request *internal.HTTPRequest) IHTTPClient {
+	if s.overrides == nil || (request.Timeout == 0 && request.Insecure == nil && request.MaxRedirects == 0) {
+		return s.Netclient
+	}
+	config := s.overrides.config
+	if request.Insecure != nil {
+		config.Insecure = *request.Insecure
+	}
+	if request.MaxRedirects > 0 {
+		config.MaxRedirects = request.MaxRedirects
+	}
+	timeout := time.Second * time.Duration(config.Timeout)
+	if request.Timeout > 0 {
+		timeout = request.Timeout
+	}
+	key := clientKey{timeout: timeout, insecure: config.Insecure, maxRedirects: config.MaxRedirects}
+
+	s.overrides.mux.Lock()
+	defer s.overrides.mux.Unlock()
+	if client, ok := s.overrides.clients[key]; ok {
+		return client
+	}
+	client := s.overrides.build(config)
+	client.Timeout = timeout
+	s.overrides.clients[key] = client
+	return client
+}
+
+func newTransport(config core.HTTPConfig) *http.Transport {
+	// the old protocols are accepted, so the tls checks can flag the servers still offering them
+	tr := &http.Transport{
+		Proxy:           proxyFunc(config),
+		TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS10},
+		// the fetchers share the resolutions of the hosts, a scan sending many requests to each of them
+		DialContext: sharedDNSCache(config.Resolver, config.DNSCacheTTL).dialContext,
+	}
+	// the connections to the targets are opened by the SOCKS5 proxy, authenticating with the credentials of its url
+	if isSOCKS(config.Proxy) {
+		dial, err := socksDialContext(config, tr.DialContext)
+		if err != nil {
+			log.Error(err)
+		} else {
+			tr.Proxy = nil
+			tr.DialContext = dial
+		}
+	}
+	if config.Insecure {
+		tr.TLSClientConfig.InsecureSkipVerify = true
+	}
+	// HTTP/2 is only negotiated over TLS, the plain http requests stay in HTTP/1.1
+	switch config.HTTPVersion {
+	case "1.1":
+		tr.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	default:
+		tr.ForceAttemptHTTP2 = true
+	}
+	return tr
+}
+
+// proxyFunc returns the proxy of the configuration, or else the one from the environment (HTTP_PROXY, HTTPS_PROXY, NO_PROXY).
+// The proxy credentials are injected in the proxy url so the transport sends them in the
+// Proxy-Authorization header, without them ever being part of the command line.
+func proxyFunc(config core.HTTPConfig) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		proxyURL := config.Proxy
+		if proxyURL == nil {
+			var err error
+			proxyURL, err = http.ProxyFromEnvironment(req)
+			if err != nil || proxyURL == nil {
+				return proxyURL, err
+			}
+		}
+		if config.ProxyUser != "" {
+			u := *proxyURL
+			u.User = url.UserPassword(config.ProxyUser, config.ProxyPass)
+			proxyURL = &u
+		}
+		return proxyURL, nil
 	}
 }
 
-func (s Fetcher) Fetch(url string) (*internal.HTTPResponse, error) {
+func (s Fetcher) Fetch(request *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	method := request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	if request.Body != "" {
+		body = strings.NewReader(request.Body)
+	}
+	req, err := http.NewRequest(method, request.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range request.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if s.Authorization != "" && req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", s.Authorization)
+	}
+	if s.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", s.UserAgent)
+	}
+	// the transport only decodes gzip on its own, so the bodies are decoded below
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+	}
 
-	resp, err := s.Netclient.Get(url)
+	resp, err := s.client(request).Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if s.HTTPVersion == "2" && resp.ProtoMajor != 2 {
+		return nil, fmt.Errorf("HTTP/2 was not negotiated with %s, got %s", req.URL.Host, resp.Proto)
+	}
 
 	bodyBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
-	bodyString := string(bodyBytes)
+	bodyString := string(decodeBody(resp.Header.Get("Content-Encoding"), bodyBytes))
+
+	// the final url, after the redirects
+	finalURL := request.URL
+	if resp.Request != nil && resp.Request.URL != nil {
+		finalURL = resp.Request.URL.String()
+	}
 
 	var r = &internal.HTTPResponse{
-		Body:       bodyString,
-		StatusCode: resp.StatusCode,
-		Header:     resp.Header,
+		URL:               finalURL,
+		Body:              bodyString,
+		StatusCode:        resp.StatusCode,
+		Header:            resp.Header,
+		TLS:               resp.TLS,
+		Proto:             resp.Proto,
+		InitialStatusCode: initialStatusCode(resp),
 	}
 
 	return r, err
 }
+
+// initialStatusCode walks the redirects back to the first response of the chain, 0 when the response is the first one
+func initialStatusCode(resp *http.Response) int {
+	code := 0
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		code = req.Response.StatusCode
+	}
+	return code
+}
+
+// MaxDecodedBodySize is the maximum size of a decompressed body, so a small compression bomb can't exhaust the memory
+const MaxDecodedBodySize = 32 << 20
+
+// decodeBody decompresses the gzip and deflate bodies so the checks match their content.
+// The raw body is returned when the encoding is unknown or the decompression fails,
+// and the decompressed body is truncated to MaxDecodedBodySize.
+func decodeBody(encoding string, body []byte) []byte {
+	var reader io.Reader
+	var err error
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		// deflate is meant to be zlib wrapped, but some servers send raw deflate
+		reader, err = zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			reader, err = flate.NewReader(bytes.NewReader(body)), nil
+		}
+	default:
+		return body
+	}
+	if err != nil {
+		log.Debug("Could not decode the ", encoding, " body: ", err)
+		return body
+	}
+	decoded, err := ioutil.ReadAll(io.LimitReader(reader, MaxDecodedBodySize+1))
+	if err != nil {
+		log.Debug("Could not decode the ", encoding, " body: ", err)
+		return body
+	}
+	if len(decoded) > MaxDecodedBodySize {
+		log.Warn(fmt.Sprintf("The decompressed %s body is larger than %d bytes, it is truncated", encoding, MaxDecodedBodySize))
+		decoded = decoded[:MaxDecodedBodySize]
+	}
+	return decoded
+}
@@ -1,9 +1,22 @@
 package httpget_test
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/tls"
 	"fmt"
+	"gochopchop/core"
+	"gochopchop/internal"
+	"gochopchop/internal/httpget"
 	"gochopchop/mock"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestFetch(t *testing.T) {
@@ -17,7 +30,7 @@ func TestFetch(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			resp, err := mock.FakeFetcher.Fetch(tc.url)
+			resp, err := mock.FakeFetcher.Fetch(&internal.HTTPRequest{URL: tc.url})
 			fmt.Printf("%v - %v \n", resp, err)
 			if tc.nilErr && err != nil {
 				t.Errorf("expected a nil error, got : %v", err)
@@ -28,3 +41,339 @@ func TestFetch(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchOverrides(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer slow.Close()
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+	insecure := true
+
+	fetcher := httpget.NewFetcher(core.HTTPConfig{Timeout: 5})
+	var tests = map[string]struct {
+		request *internal.HTTPRequest
+		nilErr  bool
+	}{
+		"global timeout":            {request: &internal.HTTPRequest{URL: slow.URL}, nilErr: true},
+		"request timeout":           {request: &internal.HTTPRequest{URL: slow.URL, Timeout: 10 * time.Millisecond}, nilErr: false},
+		"self-signed certificate":   {request: &internal.HTTPRequest{URL: tlsServer.URL}, nilErr: false},
+		"insecure request override": {request: &internal.HTTPRequest{URL: tlsServer.URL, Insecure: &insecure}, nilErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := fetcher.Fetch(tc.request)
+			if tc.nilErr && err != nil {
+				t.Errorf("expected a nil error, got : %v", err)
+			}
+			if !tc.nilErr && err == nil {
+				t.Errorf("expected a non-nil error, got : %v", err)
+			}
+		})
+	}
+}
+
+func TestFetchTLS(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+	// a server still offering an old protocol
+	old := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	old.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11}
+	old.StartTLS()
+	defer old.Close()
+	insecure := true
+
+	fetcher := httpget.NewFetcher(core.HTTPConfig{Timeout: 5})
+	check := &core.Check{TLS: &core.TLSCheck{MinTLSVersion: "1.2"}}
+	var tests = map[string]struct {
+		url  string
+		want bool
+	}{
+		"plain http":   {url: plain.URL, want: false},
+		"old protocol": {url: old.URL, want: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := fetcher.Fetch(&internal.HTTPRequest{URL: tc.url, Insecure: &insecure})
+			if err != nil {
+				t.Fatalf("expected a nil error, got : %v", err)
+			}
+			if (resp.TLS != nil) != strings.HasPrefix(tc.url, "https://") {
+				t.Errorf("expected: the TLS state of %v, got: %v", tc.url, resp.TLS)
+			}
+			if got := check.Match(resp); got != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFetchHTTPVersion(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h2 := httptest.NewUnstartedServer(handler)
+	h2.EnableHTTP2 = true
+	h2.StartTLS()
+	defer h2.Close()
+	h1 := httptest.NewTLSServer(handler)
+	defer h1.Close()
+	insecure := true
+
+	var tests = map[string]struct {
+		version   string
+		url       string
+		wantProto string
+		wantErr   bool
+	}{
+		"default negotiates HTTP/2": {version: "", url: h2.URL, wantProto: "HTTP/2.0"},
+		"auto negotiates HTTP/2":    {version: "auto", url: h2.URL, wantProto: "HTTP/2.0"},
+		"auto falls back":           {version: "auto", url: h1.URL, wantProto: "HTTP/1.1"},
+		"forced HTTP/1.1":           {version: "1.1", url: h2.URL, wantProto: "HTTP/1.1"},
+		"forced HTTP/2":             {version: "2", url: h2.URL, wantProto: "HTTP/2.0"},
+		"HTTP/2 not negotiated":     {version: "2", url: h1.URL, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := httpget.NewFetcher(core.HTTPConfig{Timeout: 5, HTTPVersion: tc.version})
+			resp, err := fetcher.Fetch(&internal.HTTPRequest{URL: tc.url, Insecure: &insecure})
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got: %v", resp.Proto)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected a nil error, got : %v", err)
+			}
+			if resp.Proto != tc.wantProto {
+				t.Errorf("expected: %v, got: %v", tc.wantProto, resp.Proto)
+			}
+		})
+	}
+}
+
+func TestFetchMaxRedirects(t *testing.T) {
+	// /hop/N redirects to /hop/N-1 until /hop/0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var hops int
+		fmt.Sscanf(r.URL.Path, "/hop/%d", &hops)
+		if hops > 0 {
+			http.Redirect(w, r, fmt.Sprintf("/hop/%d", hops-1), http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := httpget.NewFetcher(core.HTTPConfig{Timeout: 5, MaxRedirects: 3})
+	var tests = map[string]struct {
+		request *internal.HTTPRequest
+		nilErr  bool
+	}{
+		"within the limit":       {request: &internal.HTTPRequest{URL: server.URL + "/hop/3"}, nilErr: true},
+		"over the limit":         {request: &internal.HTTPRequest{URL: server.URL + "/hop/4"}, nilErr: false},
+		"request limit override": {request: &internal.HTTPRequest{URL: server.URL + "/hop/4", MaxRedirects: 5}, nilErr: true},
+		"request limit lower":    {request: &internal.HTTPRequest{URL: server.URL + "/hop/2", MaxRedirects: 1}, nilErr: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := fetcher.Fetch(tc.request)
+			if tc.nilErr != (err == nil) {
+				t.Fatalf("expected a nil error: %v, got : %v", tc.nilErr, err)
+			}
+			if tc.nilErr && resp.URL != server.URL+"/hop/0" {
+				t.Errorf("expected: %v, got: %v", server.URL+"/hop/0", resp.URL)
+			}
+		})
+	}
+}
+
+func TestFetchInitialStatusCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/backup.zip":
+			http.Redirect(w, r, "/temporary", http.StatusMovedPermanently)
+		case "/temporary":
+			http.Redirect(w, r, "/login", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	var tests = map[string]struct {
+		fetcher core.IFetcher
+		url     string
+		status  int
+		initial int
+	}{
+		"redirects followed":  {fetcher: httpget.NewFetcher(core.HTTPConfig{Timeout: 5}), url: "/backup.zip", status: 200, initial: 301},
+		"no redirect":         {fetcher: httpget.NewFetcher(core.HTTPConfig{Timeout: 5}), url: "/login", status: 200, initial: 0},
+		"redirects not taken": {fetcher: httpget.NewNoRedirectFetcher(core.HTTPConfig{Timeout: 5}), url: "/backup.zip", status: 301, initial: 0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := tc.fetcher.Fetch(&internal.HTTPRequest{URL: server.URL + tc.url})
+			if err != nil {
+				t.Fatalf("expected a nil error, got : %v", err)
+			}
+			if resp.StatusCode != tc.status {
+				t.Errorf("expected: %v, got: %v", tc.status, resp.StatusCode)
+			}
+			if resp.InitialStatusCode != tc.initial {
+				t.Errorf("expected: %v, got: %v", tc.initial, resp.InitialStatusCode)
+			}
+		})
+	}
+}
+
+func TestFetchProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a proxy receives the absolute url of the target
+		fmt.Fprintf(w, "proxied %s", r.URL)
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	fetcher := httpget.NewFetcher(core.HTTPConfig{Timeout: 5, Proxy: proxyURL})
+	resp, err := fetcher.Fetch(&internal.HTTPRequest{URL: "http://target.invalid/admin"})
+	if err != nil {
+		t.Fatalf("expected a nil error, got : %v", err)
+	}
+	if want := "proxied http://target.invalid/admin"; resp.Body != want {
+		t.Errorf("expected: %v, got: %v", want, resp.Body)
+	}
+}
+
+func TestFetchAuthorization(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	var tests = map[string]struct {
+		authorization string
+		header        http.Header
+		want          string
+	}{
+		"no authorization":     {want: ""},
+		"bearer token":         {authorization: "Bearer foobar", want: "Bearer foobar"},
+		"plugin authorization": {authorization: "Bearer foobar", header: http.Header{"Authorization": {"Basic YWRtaW46YWRtaW4="}}, want: "Basic YWRtaW46YWRtaW4="},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := httpget.NewFetcher(core.HTTPConfig{Timeout: 5, Authorization: tc.authorization})
+			resp, err := fetcher.Fetch(&internal.HTTPRequest{URL: server.URL, Header: tc.header})
+			if err != nil {
+				t.Fatalf("expected a nil error, got : %v", err)
+			}
+			if resp.Body != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, resp.Body)
+			}
+		})
+	}
+}
+
+func TestFetchUserAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get("User-Agent"))
+	}))
+	defer server.Close()
+
+	var tests = map[string]struct {
+		userAgent string
+		header    http.Header
+		want      string
+	}{
+		"default user agent":    {userAgent: core.DefaultUserAgent(), want: "gochopchop/dev"},
+		"custom user agent":     {userAgent: "scanner", want: "scanner"},
+		"plugin user agent":     {userAgent: "scanner", header: http.Header{"User-Agent": {"Mozilla/5.0"}}, want: "Mozilla/5.0"},
+		"go default when unset": {want: "Go-http-client/1.1"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetcher := httpget.NewFetcher(core.HTTPConfig{Timeout: 5, UserAgent: tc.userAgent})
+			resp, err := fetcher.Fetch(&internal.HTTPRequest{URL: server.URL, Header: tc.header})
+			if err != nil {
+				t.Fatalf("expected a nil error, got : %v", err)
+			}
+			if resp.Body != tc.want {
+				t.Errorf("expected: %v, got: %v", tc.want, resp.Body)
+			}
+		})
+	}
+}
+
+func TestFetchCompressedBody(t *testing.T) {
+	const body = "[branch \"master\"]"
+	var gzipped, zlibbed, deflated bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write([]byte(body))
+	gw.Close()
+	zw := zlib.NewWriter(&zlibbed)
+	zw.Write([]byte(body))
+	zw.Close()
+	fw, _ := flate.NewWriter(&deflated, flate.DefaultCompression)
+	fw.Write([]byte(body))
+	fw.Close()
+	// a few kilobytes decompressed to more than the maximum size
+	var bomb bytes.Buffer
+	bw, _ := gzip.NewWriterLevel(&bomb, gzip.BestCompression)
+	bw.Write(make([]byte, httpget.MaxDecodedBodySize+1<<20))
+	bw.Close()
+
+	var tests = map[string]struct {
+		encoding       string
+		body           []byte
+		acceptEncoding string
+		// echo answers the Accept-Encoding header of the request
+		echo bool
+		want string
+	}{
+		"gzip":               {encoding: "gzip", body: gzipped.Bytes(), want: body},
+		"zlib deflate":       {encoding: "deflate", body: zlibbed.Bytes(), want: body},
+		"raw deflate":        {encoding: "deflate", body: deflated.Bytes(), want: body},
+		"not encoded":        {body: []byte(body), want: body},
+		"invalid gzip":       {encoding: "gzip", body: []byte("not gzip"), want: "not gzip"},
+		"compression bomb":   {encoding: "gzip", body: bomb.Bytes(), want: string(make([]byte, httpget.MaxDecodedBodySize))},
+		"accepted encodings": {echo: true, want: "gzip, deflate"},
+		"plugin encodings":   {echo: true, acceptEncoding: "identity", want: "identity"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.echo {
+					fmt.Fprint(w, r.Header.Get("Accept-Encoding"))
+					return
+				}
+				if tc.encoding != "" {
+					w.Header().Set("Content-Encoding", tc.encoding)
+				}
+				w.Write(tc.body)
+			}))
+			defer server.Close()
+
+			header := http.Header{}
+			if tc.acceptEncoding != "" {
+				header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			fetcher := httpget.NewFetcher(core.HTTPConfig{Timeout: 5})
+			resp, err := fetcher.Fetch(&internal.HTTPRequest{URL: server.URL, Header: header})
+			if err != nil {
+				t.Fatalf("expected a nil error, got : %v", err)
+			}
+			if resp.Body != tc.want {
+				t.Errorf("expected: %q, got: %q", truncate(tc.want), truncate(resp.Body))
+			}
+		})
+	}
+}
+
+// truncate shortens the bodies printed by the failed tests
+func truncate(body string) string {
+	if len(body) > 64 {
+		return fmt.Sprintf("%s... (%d bytes)", body[:64], len(body))
+	}
+	return body
+}
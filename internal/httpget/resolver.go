@@ -0,0 +1,124 @@
+package httpget
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultDNSCacheTTL is how long a resolution is reused when the configuration doesn't set it
+const DefaultDNSCacheTTL = 60 * time.Second
+
+// dnsCaches are shared by the fetchers of the same resolver and TTL, so the hosts are resolved once per scan
+var dnsCaches = struct {
+	mux    sync.Mutex
+	caches map[dnsCacheKey]*dnsCache
+}{caches: make(map[dnsCacheKey]*dnsCache)}
+
+type dnsCacheKey struct {
+	resolver string
+	ttl      time.Duration
+}
+
+// dnsCache resolves the hosts through the system resolver, or a custom one, and keeps the addresses for its TTL.
+// The failed resolutions are not kept, so a host is resolved again by the next request.
+type dnsCache struct {
+	mux      sync.Mutex
+	resolver *net.Resolver
+	dialer   *net.Dialer
+	ttl      time.Duration
+	entries  map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// sharedDNSCache returns the cache of the resolver ("host:port", the system one when empty) and the TTL
+func sharedDNSCache(resolver string, ttl time.Duration) *dnsCache {
+	if ttl <= 0 {
+		ttl = DefaultDNSCacheTTL
+	}
+	key := dnsCacheKey{resolver: resolver, ttl: ttl}
+	dnsCaches.mux.Lock()
+	defer dnsCaches.mux.Unlock()
+	if cache, ok := dnsCaches.caches[key]; ok {
+		return cache
+	}
+	cache := newDNSCache(resolver, ttl)
+	dnsCaches.caches[key] = cache
+	return cache
+}
+
+func newDNSCache(resolver string, ttl time.Duration) *dnsCache {
+	cache := &dnsCache{
+		resolver: net.DefaultResolver,
+		// the dialer of http.DefaultTransport
+		dialer:  &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		ttl:     ttl,
+		entries: make(map[string]dnsEntry),
+	}
+	if resolver != "" {
+		cache.resolver = &net.Resolver{
+			PreferGo: true,
+			// the queries go to the custom resolver whatever the server of the system configuration
+			Dial: func(ctx context.Context, network string, address string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, resolver)
+			},
+		}
+	}
+	return cache
+}
+
+// lookup returns the addresses of the host, from the cache while they are fresh.
+// The resolution is aborted when the context is done, eg. when the request times out.
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	c.mux.Lock()
+	entry, ok := c.entries[host]
+	c.mux.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.addrs, nil
+	}
+
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	c.entries[host] = dnsEntry{addrs: addrs, expires: time.Now().Add(c.ttl)}
+	c.mux.Unlock()
+	return addrs, nil
+}
+
+// dialContext dials the addresses of the host in turn until one answers, as the dialer of net/http does
+func (c *dnsCache) dialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := c.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no address found for %s", host)
+	}
+	for _, addr := range addrs {
+		var conn net.Conn
+		conn, err = c.dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+		if err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
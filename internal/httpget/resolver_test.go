@@ -0,0 +1,107 @@
+package httpget_test
+
+import (
+	"gochopchop/core"
+	"gochopchop/internal"
+	"gochopchop/internal/httpget"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// fakeResolver answers 127.0.0.1 to the A queries of any name, and counts them
+type fakeResolver struct {
+	conn    net.PacketConn
+	mux     sync.Mutex
+	queries int
+}
+
+func newFakeResolver(t *testing.T) *fakeResolver {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &fakeResolver{conn: conn}
+	go r.serve()
+	return r
+}
+
+func (r *fakeResolver) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var query dnsmessage.Message
+		if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) == 0 {
+			continue
+		}
+		question := query.Questions[0]
+		answer := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: query.Header.ID, Response: true, Authoritative: true},
+			Questions: []dnsmessage.Question{question},
+		}
+		if question.Type == dnsmessage.TypeA {
+			r.mux.Lock()
+			r.queries++
+			r.mux.Unlock()
+			answer.Answers = []dnsmessage.Resource{{
+				Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+				Body:   &dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}},
+			}}
+		}
+		packed, err := answer.Pack()
+		if err != nil {
+			continue
+		}
+		r.conn.WriteTo(packed, addr)
+	}
+}
+
+func (r *fakeResolver) count() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.queries
+}
+
+func TestFetchResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+
+	var tests = map[string]struct {
+		ttl      time.Duration
+		wait     time.Duration
+		expected int
+	}{
+		"resolved once":            {ttl: time.Minute, expected: 1},
+		"resolved again after ttl": {ttl: 50 * time.Millisecond, wait: 100 * time.Millisecond, expected: 2},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resolver := newFakeResolver(t)
+			defer resolver.conn.Close()
+
+			config := core.HTTPConfig{Timeout: 5, Resolver: resolver.conn.LocalAddr().String(), DNSCacheTTL: tc.ttl}
+			fetchers := []*httpget.Fetcher{httpget.NewFetcher(config), httpget.NewNoRedirectFetcher(config)}
+			for _, fetcher := range fetchers {
+				time.Sleep(tc.wait)
+				// the keep-alive connections would skip the resolution
+				request := &internal.HTTPRequest{URL: "http://chopchop.test:" + port + "/", Header: http.Header{"Connection": {"close"}}}
+				if _, err := fetcher.Fetch(request); err != nil {
+					t.Fatalf("expected a nil error, got : %v", err)
+				}
+			}
+			if got := resolver.count(); got != tc.expected {
+				t.Errorf("expected: %v, got: %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package httpget
+
+import (
+	"context"
+	"fmt"
+	"gochopchop/core"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// defaultSOCKSPort is the port of the SOCKS5 proxies whose url doesn't set one
+const defaultSOCKSPort = "1080"
+
+// contextDialer is the dialer of the connections to the proxy, through the DNS cache
+type contextDialer func(ctx context.Context, network string, address string) (net.Conn, error)
+
+func (d contextDialer) Dial(network string, address string) (net.Conn, error) {
+	return d(context.Background(), network, address)
+}
+
+func (d contextDialer) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	return d(ctx, network, address)
+}
+
+// isSOCKS tells whether the proxy is a SOCKS5 one, socks5h standing for the same as the hosts are always resolved by the proxy
+func isSOCKS(proxyURL *url.URL) bool {
+	return proxyURL != nil && (strings.EqualFold(proxyURL.Scheme, "socks5") || strings.EqualFold(proxyURL.Scheme, "socks5h"))
+}
+
+// socksAddress returns the host:port of the SOCKS5 proxy
+func socksAddress(proxyURL *url.URL) string {
+	port := proxyURL.Port()
+	if port == "" {
+		port = defaultSOCKSPort
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), port)
+}
+
+// socksAuth returns the credentials of the SOCKS5 proxy, the --proxy-user ones taking precedence over the ones of the url
+func socksAuth(config core.HTTPConfig) *proxy.Auth {
+	if config.ProxyUser != "" {
+		return &proxy.Auth{User: config.ProxyUser, Password: config.ProxyPass}
+	}
+	if user := config.Proxy.User; user != nil && user.Username() != "" {
+		password, _ := user.Password()
+		return &proxy.Auth{User: user.Username(), Password: password}
+	}
+	return nil
+}
+
+// socksDialContext returns the dialer of the connections through the SOCKS5 proxy of the configuration.
+// The hosts are resolved by the proxy, so the names of an isolated network segment can be scanned through a bastion.
+func socksDialContext(config core.HTTPConfig, forward contextDialer) (contextDialer, error) {
+	address := socksAddress(config.Proxy)
+	dialer, err := proxy.SOCKS5("tcp", address, socksAuth(config), forward)
+	if err != nil {
+		return nil, err
+	}
+	socks, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("the SOCKS5 dialer of %s can't be canceled", address)
+	}
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		conn, err := socks.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, socksError(address, err)
+		}
+		return conn, nil
+	}, nil
+}
+
+// socksError tells clearly when the proxy rejects the credentials, rather than as a connection error to the target
+func socksError(address string, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "authentication failed"):
+		return fmt.Errorf("The SOCKS5 proxy %s rejected the credentials: %v", address, err)
+	case strings.Contains(err.Error(), "no acceptable authentication methods"):
+		return fmt.Errorf("The SOCKS5 proxy %s accepts none of the offered authentication methods, please check its credentials in the proxy url or --proxy-user: %v", address, err)
+	}
+	return err
+}
+
+// VerifyProxy authenticates with the SOCKS5 proxy of the configuration, if any, so rejected credentials stop the execution
+// before scanning rather than failing every request. The other proxies are only reached by the requests.
+func VerifyProxy(ctx context.Context, config core.HTTPConfig) error {
+	if !isSOCKS(config.Proxy) {
+		return nil
+	}
+	address := socksAddress(config.Proxy)
+	timeout := time.Second * time.Duration(config.Timeout)
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("Can't reach the SOCKS5 proxy %s: %v", address, err)
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	if err := socksHandshake(conn, socksAuth(config)); err != nil {
+		if clear := socksError(address, err); clear != err {
+			return clear
+		}
+		return fmt.Errorf("Can't authenticate with the SOCKS5 proxy %s: %v", address, err)
+	}
+	return nil
+}
+
+// socksHandshake negotiates the authentication method of RFC 1928, then sends the credentials as RFC 1929 says
+func socksHandshake(conn io.ReadWriter, auth *proxy.Auth) error {
+	methods := []byte{0x00}
+	if auth != nil {
+		methods = append(methods, 0x02)
+	}
+	if _, err := conn.Write(append([]byte{0x05, byte(len(methods))}, methods...)); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != 0x05 {
+		return fmt.Errorf("unexpected protocol version %d", reply[0])
+	}
+	if reply[1] == 0x00 {
+		return nil
+	}
+	if reply[1] != 0x02 || auth == nil {
+		return fmt.Errorf("no acceptable authentication methods")
+	}
+	if len(auth.User) == 0 || len(auth.User) > 255 || len(auth.Password) == 0 || len(auth.Password) > 255 {
+		return fmt.Errorf("invalid username/password")
+	}
+	request := []byte{0x01, byte(len(auth.User))}
+	request = append(request, auth.User...)
+	request = append(request, byte(len(auth.Password)))
+	request = append(request, auth.Password...)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[1] != 0x00 {
+		return fmt.Errorf("username/password authentication failed")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package httpget_test
+
+import (
+	"context"
+	"gochopchop/core"
+	"gochopchop/internal"
+	"gochopchop/internal/httpget"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// fakeSOCKSProxy is a SOCKS5 proxy requiring the user scanner:secret, which connects any host to the target
+func fakeSOCKSProxy(t *testing.T, target string) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSOCKS(conn, target)
+		}
+	}()
+	return listener
+}
+
+func serveSOCKS(conn net.Conn, target string) {
+	defer conn.Close()
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return
+	}
+	if !strings.Contains(string(methods), "\x02") {
+		conn.Write([]byte{0x05, 0xff})
+		return
+	}
+	conn.Write([]byte{0x05, 0x02})
+	credentials := make([][]byte, 2)
+	version := make([]byte, 1)
+	if _, err := io.ReadFull(conn, version); err != nil {
+		return
+	}
+	for i := range credentials {
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return
+		}
+		credentials[i] = make([]byte, length[0])
+		if _, err := io.ReadFull(conn, credentials[i]); err != nil {
+			return
+		}
+	}
+	if string(credentials[0]) != "scanner" || string(credentials[1]) != "secret" {
+		conn.Write([]byte{0x01, 0x01})
+		return
+	}
+	conn.Write([]byte{0x01, 0x00})
+
+	// the CONNECT request, the host being resolved by the proxy
+	request := make([]byte, 5)
+	if _, err := io.ReadFull(conn, request); err != nil || request[3] != 0x03 {
+		return
+	}
+	address := make([]byte, int(request[4])+2)
+	if _, err := io.ReadFull(conn, address); err != nil {
+		return
+	}
+	upstream, err := net.Dial("tcp", target)
+	if err != nil {
+		conn.Write([]byte{0x05, 0x04, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer upstream.Close()
+	reply := []byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0, 0}
+	conn.Write(reply)
+	go io.Copy(upstream, conn)
+	io.Copy(conn, upstream)
+}
+
+func TestFetchSOCKSProxy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host))
+	}))
+	defer server.Close()
+	listener := fakeSOCKSProxy(t, strings.TrimPrefix(server.URL, "http://"))
+	defer listener.Close()
+
+	var tests = map[string]struct {
+		proxy     string
+		proxyUser string
+		proxyPass string
+		wantErr   string
+	}{
+		"credentials in the url": {proxy: "socks5://scanner:secret@" + listener.Addr().String()},
+		"proxy user flags":       {proxy: "socks5h://" + listener.Addr().String(), proxyUser: "scanner", proxyPass: "secret"},
+		"rejected credentials":   {proxy: "socks5://scanner:wrong@" + listener.Addr().String(), wantErr: "rejected the credentials"},
+		"missing credentials":    {proxy: "socks5://" + listener.Addr().String(), wantErr: "accepts none of the offered authentication methods"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			proxyURL, _ := url.Parse(tc.proxy)
+			config := core.HTTPConfig{Timeout: 5, Proxy: proxyURL, ProxyUser: tc.proxyUser, ProxyPass: tc.proxyPass}
+
+			err := httpget.VerifyProxy(context.Background(), config)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("expected a nil error, got : %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("expected: %v, got: %v", tc.wantErr, err)
+			}
+
+			resp, err := httpget.NewFetcher(config).Fetch(&internal.HTTPRequest{URL: "http://internal.bastion/"})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("expected: %v, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected a nil error, got : %v", err)
+			}
+			if resp.Body != "internal.bastion" {
+				t.Errorf("expected: %v, got: %v", "internal.bastion", resp.Body)
+			}
+		})
+	}
+}
@@ -1,7 +1,19 @@
 package main
 
-import "gochopchop/cmd"
+import (
+	"gochopchop/cmd"
+	"gochopchop/core"
+)
+
+// Version and BuildDate are set at build time with -ldflags "-X main.Version=..."
+var (
+	Version   string
+	BuildDate string
+)
 
 func main() {
+	if Version != "" {
+		core.Version = Version
+	}
 	cmd.Execute()
 }
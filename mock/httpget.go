@@ -10,8 +10,9 @@ import (
 
 type FakeNetClient map[string]*http.Response
 
-func (f FakeNetClient) Get(url string) (*http.Response, error) {
+func (f FakeNetClient) Do(req *http.Request) (*http.Response, error) {
 	// implements IHTTPClient interface
+	url := req.URL.String()
 	if res, ok := f[url]; ok {
 		return res, nil
 	}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"gochopchop/core"
+	"time"
 )
 
 var FakeOutputStatusCode = core.Output{
@@ -60,6 +61,18 @@ var FakeOutput = []core.Output{
 	FakeOutputNotMatch,
 }
 
-var FakeOutputAsCSV = "url,endpoint,severity,checkName,remediation\nhttp://problems,/,Medium,StatusCode200,uninstall\nhttp://problems,/,High,Headers,uninstall\nhttp://problems,/,Low,NoHeaders,uninstall\nhttp://problems,/,Informational,MustMatchAll,uninstall\nhttp://problems,/,Low,MustMatchOne,uninstall\nhttp://problems,/,High,MustNotMatch,uninstall\n"
-var FakeOutputAsTable = "+-----------------+----------+---------------+---------------+-------------+\n| URL             | ENDPOINT | SEVERITY      | PLUGIN        | REMEDIATION |\n+-----------------+----------+---------------+---------------+-------------+\n| http://problems | /        | \x1b[31mHigh\x1b[0m          | Headers       | uninstall   |\n| http://problems | /        | \x1b[31mHigh\x1b[0m          | MustNotMatch  | uninstall   |\n| http://problems | /        | \x1b[32mLow\x1b[0m           | NoHeaders     | uninstall   |\n| http://problems | /        | \x1b[32mLow\x1b[0m           | MustMatchOne  | uninstall   |\n| http://problems | /        | \x1b[33mMedium\x1b[0m        | StatusCode200 | uninstall   |\n| http://problems | /        | \x1b[36mInformational\x1b[0m | MustMatchAll  | uninstall   |\n+-----------------+----------+---------------+---------------+-------------+\n"
-var FakeOutputAsJSON = "[{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"StatusCode200\",\"severity\":\"Medium\",\"remediation\":\"uninstall\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"Headers\",\"severity\":\"High\",\"remediation\":\"uninstall\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"NoHeaders\",\"severity\":\"Low\",\"remediation\":\"uninstall\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"MustMatchAll\",\"severity\":\"Informational\",\"remediation\":\"uninstall\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"MustMatchOne\",\"severity\":\"Low\",\"remediation\":\"uninstall\"},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"MustNotMatch\",\"severity\":\"High\",\"remediation\":\"uninstall\"}]"
+var FakeOutputAsCSV = "url,endpoint,severity,checkName,remediation,category,duration\nhttp://problems,/,Medium,StatusCode200,uninstall,,0ms\nhttp://problems,/,High,Headers,uninstall,,0ms\nhttp://problems,/,Low,NoHeaders,uninstall,,0ms\nhttp://problems,/,Informational,MustMatchAll,uninstall,,0ms\nhttp://problems,/,Low,MustMatchOne,uninstall,,0ms\nhttp://problems,/,High,MustNotMatch,uninstall,,0ms\n"
+var FakeOutputAsTable = "+-----------------+----------+---------------+---------------+-------------+\n| URL             | ENDPOINT | SEVERITY      | PLUGIN        | REMEDIATION |\n+-----------------+----------+---------------+---------------+-------------+\n| http://problems | /        | \x1b[31mHigh\x1b[0m          | Headers       | uninstall   |\n| http://problems | /        | \x1b[31mHigh\x1b[0m          | MustNotMatch  | uninstall   |\n| http://problems | /        | \x1b[33mMedium\x1b[0m        | StatusCode200 | uninstall   |\n| http://problems | /        | \x1b[32mLow\x1b[0m           | NoHeaders     | uninstall   |\n| http://problems | /        | \x1b[32mLow\x1b[0m           | MustMatchOne  | uninstall   |\n| http://problems | /        | \x1b[36mInformational\x1b[0m | MustMatchAll  | uninstall   |\n+-----------------+----------+---------------+---------------+-------------+\n"
+var FakeOutputAsLines = "[High] http://problems - Headers\n[High] http://problems - MustNotMatch\n[Medium] http://problems - StatusCode200\n[Low] http://problems - NoHeaders\n[Low] http://problems - MustMatchOne\n[Informational] http://problems - MustMatchAll\n"
+var FakeOutputAsJSON = "[{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"StatusCode200\",\"severity\":\"Medium\",\"remediation\":\"uninstall\",\"durationMs\":0},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"Headers\",\"severity\":\"High\",\"remediation\":\"uninstall\",\"durationMs\":0},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"NoHeaders\",\"severity\":\"Low\",\"remediation\":\"uninstall\",\"durationMs\":0},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"MustMatchAll\",\"severity\":\"Informational\",\"remediation\":\"uninstall\",\"durationMs\":0},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"MustMatchOne\",\"severity\":\"Low\",\"remediation\":\"uninstall\",\"durationMs\":0},{\"url\":\"http://problems\",\"endpoint\":\"/\",\"checkName\":\"MustNotMatch\",\"severity\":\"High\",\"remediation\":\"uninstall\",\"durationMs\":0}]"
+
+var FakeMetadata = core.Metadata{
+	Version:        "1.0.0",
+	StartTime:      time.Date(2020, 12, 1, 10, 0, 0, 0, time.UTC),
+	EndTime:        time.Date(2020, 12, 1, 10, 5, 0, 0, time.UTC),
+	Targets:        2,
+	SignatureFiles: []string{"chopchop.yml"},
+	MaxSeverity:    "High",
+}
+var FakeMetadataAsJSON = `{"version":"1.0.0","startTime":"2020-12-01T10:00:00Z","endTime":"2020-12-01T10:05:00Z","targets":2,"signatureFiles":["chopchop.yml"],"maxSeverity":"High"}`
+var FakeReportAsJSON = `{"findings":` + FakeOutputAsJSON + `,"metadata":` + FakeMetadataAsJSON + `}`
@@ -11,11 +11,11 @@ var FakeScanner = core.NewScanner(MyFakeFetcher, MyFakeFetcher, FakeSignatures,
 
 type FakeFetcherWithoutNetclient map[string]*internal.HTTPResponse
 
-func (f FakeFetcherWithoutNetclient) Fetch(url string) (*internal.HTTPResponse, error) {
-	if res, ok := f[url]; ok {
+func (f FakeFetcherWithoutNetclient) Fetch(req *internal.HTTPRequest) (*internal.HTTPResponse, error) {
+	if res, ok := f[req.URL]; ok {
 		return res, nil
 	}
-	return nil, fmt.Errorf("could not fetch : %s", url)
+	return nil, fmt.Errorf("could not fetch : %s", req.URL)
 }
 
 var MyFakeFetcher = FakeFetcherWithoutNetclient{